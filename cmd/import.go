@@ -0,0 +1,68 @@
+package main
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/application/app_interfaces"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// runImport выполняет команду import: импортирует подписки из CSV-файла (- — из stdin)
+// и печатает отчёт в JSON. Код выхода: 0 — все строки импортированы,
+// 1 — импорт не выполнен, 2 — в отчёте есть ошибки строк.
+func runImport(ctx context.Context, service app_interfaces.IImportService, args []string, logger *zerolog.Logger) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: import [-dry-run] [-delimiter ;] [-map 'Header=field,...'] <file.csv|->")
+		flags.PrintDefaults()
+	}
+	var request dto.ImportRequest
+	flags.BoolVar(&request.DryRun, "dry-run", false, "only validate rows, do not create subscriptions")
+	flags.StringVar(&request.Delimiter, "delimiter", "", "field delimiter (default ,)")
+	flags.StringVar(&request.Mapping, "map", "", "header to field mapping, extends IMPORT_MAPPING")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 1
+	}
+
+	var src io.Reader = os.Stdin
+	var size int64
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			logger.Error().Err(err).Msg("Import: failed to open file")
+			return 1
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			logger.Error().Err(err).Msg("Import: failed to open file")
+			return 1
+		}
+		src, size = file, info.Size()
+	}
+
+	job, err := service.Import(ctx, src, size, request)
+	if job != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(job)
+	}
+	switch {
+	case err != nil:
+		logger.Error().Err(err).Msg("Import: not completed")
+		return 1
+	case job.Failed > 0:
+		return 2
+	}
+	return 0
+}
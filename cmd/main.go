@@ -3,8 +3,17 @@ package main
 import (
 	"SubscriptionService/configs"
 	"SubscriptionService/internal/api"
+	"SubscriptionService/internal/application/app_interfaces"
+	"SubscriptionService/internal/application/events"
 	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/application/workers"
+	"SubscriptionService/internal/broker"
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/notifier"
 	"SubscriptionService/internal/persistence"
+	"SubscriptionService/internal/persistence/memory"
+	"SubscriptionService/internal/persistence/sqlite"
 	"SubscriptionService/pkg/db"
 	"SubscriptionService/pkg/logger"
 	"SubscriptionService/pkg/migrate"
@@ -30,6 +39,16 @@ func main() {
 	dbConfig := configs.NewDataBaseConfig()
 	logConfig := configs.NewLogConfig()
 	serverConfig := configs.NewServerConfig()
+	ratesConfig := configs.NewExchangeRatesConfig()
+	trashConfig := configs.NewTrashConfig()
+	lifecycleConfig := configs.NewLifecycleConfig()
+	renewalConfig := configs.NewRenewalConfig()
+	notifierConfig := configs.NewNotifierConfig()
+	reminderConfig := configs.NewReminderConfig()
+	webhookConfig := configs.NewWebhookConfig()
+	eventsConfig := configs.NewEventsConfig()
+	brokerConfig := configs.NewBrokerConfig()
+	importConfig := configs.NewImportConfig()
 
 	// --- init logger ---
 	customLogger := logger.NewLogger(logConfig)
@@ -43,26 +62,156 @@ func main() {
 	}))
 	app.Use(gin.Recovery())
 
-	// --- run migrations ---
-	if err := migrate.Up(dbConfig.Url, customLogger); err != nil {
-		customLogger.Warn().Err(err).Msg("migrations failed (continuing anyway, tables may already exist)")
+	// --- init storage ---
+	var (
+		subRepo      core_interfaces.ISubRepository
+		txManager    core_interfaces.ITxManager
+		reminderRepo core_interfaces.IReminderRepository
+		webhookRepo  core_interfaces.IWebhookRepository
+		outboxRepo   core_interfaces.IOutboxRepository
+		rateProvider core_interfaces.IExchangeRateProvider
+	)
+	// Напоминания, вебхуки и публикация в брокер работают только с PostgreSQL
+	withPostgres := false
+	switch dbConfig.Storage {
+	case "database", "postgres":
+		// --- run migrations ---
+		if err := migrate.Up(dbConfig.Url, customLogger); err != nil {
+			customLogger.Warn().Err(err).Msg("migrations failed (continuing anyway, tables may already exist)")
+		}
+
+		if db.IsSQLite(dbConfig.Url) {
+			// --- open sqlite database ---
+			conn, err := db.NewSQLite(ctx, dbConfig.Url, customLogger)
+			if err != nil {
+				log.Fatalf("failed to open sqlite database: %v", err)
+			}
+			defer conn.Close()
+
+			subRepo = sqlite.NewSubRepository(conn)
+			txManager = sqlite.NewTxManager(conn)
+			reminderRepo = memory.ReminderRepository{}
+			webhookRepo = memory.WebhookRepository{}
+			rateProvider = memory.ExchangeRates{}
+			customLogger.Warn().Msg("Using SQLite storage: reminders, webhooks and broker publishing are disabled")
+			break
+		}
+
+		// --- init database pool ---
+		pool, err := db.NewPGXPool(ctx, dbConfig.Url, customLogger)
+		if err != nil {
+			log.Fatalf("failed to create db pool: %v", err)
+		}
+		defer pool.Close()
+
+		subRepo = persistence.NewSubRepository(pool)
+		txManager, err = persistence.NewTxManager(pool, dbConfig.TxIsolation, dbConfig.TxMaxAttempts)
+		if err != nil {
+			log.Fatalf("failed to create transaction manager: %v", err)
+		}
+		reminderRepo = persistence.NewReminderRepository(pool)
+		webhookRepo = persistence.NewWebhookRepository(pool)
+		outboxRepo = persistence.NewOutboxRepository(pool)
+		rateProvider = persistence.NewExchangeRateRepository(pool)
+		withPostgres = true
+	case "memory":
+		memoryRepo := memory.NewSubRepository()
+		subRepo = memoryRepo
+		txManager = memory.NewTxManager(memoryRepo)
+		reminderRepo = memory.ReminderRepository{}
+		webhookRepo = memory.WebhookRepository{}
+		rateProvider = memory.ExchangeRates{}
+		customLogger.Warn().Msg("Using in-memory storage: data is lost on restart, reminders, webhooks and broker publishing are disabled")
+	default:
+		log.Fatalf("unknown STORAGE %q", dbConfig.Storage)
 	}
 
-	// --- init database pool ---
-	pool, err := db.NewPGXPool(ctx, dbConfig.Url, customLogger)
-	if err != nil {
-		log.Fatalf("failed to create db pool: %v", err)
+	if ratesConfig.File != "" {
+		var err error
+		rateProvider, err = persistence.NewFileExchangeRateProvider(ratesConfig.File)
+		if err != nil {
+			log.Fatalf("failed to load exchange rates: %v", err)
+		}
+	}
+
+	// --- init message broker ---
+	var outboxRelay *services.OutboxRelay
+	if withPostgres {
+		eventBroker, err := broker.New(brokerConfig)
+		if err != nil {
+			log.Fatalf("failed to create broker publisher: %v", err)
+		}
+		defer eventBroker.Close()
+		outboxRelay = services.NewOutboxRelay(outboxRepo, eventBroker, brokerConfig.Source, customLogger)
 	}
-	defer pool.Close()
 
-	// --- init repository ---
-	subRepo := persistence.NewSubRepository(pool)
+	// --- init event dispatcher ---
+	var publisher app_interfaces.IEventPublisher
+	closeEvents := func(context.Context) error { return nil }
+	switch eventsConfig.Dispatch {
+	case "sync":
+		publisher = events.NewSyncDispatcher(customLogger)
+	case "async":
+		dispatcher := events.NewAsyncDispatcher(eventsConfig.Workers, eventsConfig.Buffer, customLogger)
+		publisher, closeEvents = dispatcher, dispatcher.Close
+	default:
+		log.Fatalf("unknown EVENTS_DISPATCH %q", eventsConfig.Dispatch)
+	}
+	publisher.Subscribe("log", events.LogHandler(customLogger))
+	// flushEvents перед выходом команды дожидается событий, ещё стоящих в очереди диспетчера
+	flushEvents := func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := closeEvents(flushCtx); err != nil {
+			customLogger.Error().Err(err).Msg("Pending events were not handled")
+		}
+	}
 
 	// --- init service ---
-	subService := services.NewSubService(subRepo, customLogger)
+	subService := services.NewSubService(subRepo, txManager, rateProvider, publisher, trashConfig.Retention, customLogger)
+
+	reminderNotifier, err := notifier.New(notifierConfig, customLogger)
+	if err != nil {
+		log.Fatalf("failed to create notifier: %v", err)
+	}
+	reminderService := services.NewReminderService(reminderRepo, reminderNotifier, reminderConfig.Lead, customLogger)
+	webhookService := services.NewWebhookService(webhookRepo, notifier.NewWebhookSender(webhookConfig.Timeout), customLogger)
+
+	importMapping, err := services.ParseMapping(importConfig.Mapping)
+	if err != nil {
+		log.Fatalf("invalid IMPORT_MAPPING: %v", err)
+	}
+	importService := services.NewImportService(subService, importMapping, importConfig.MaxBytes, importConfig.SyncLimit,
+		importConfig.MaxErrors, importConfig.JobTTL, customLogger)
+
+	// --- purge command: окончательно удалить подписки из корзины и выйти ---
+	if len(os.Args) > 1 && os.Args[1] == "purge" {
+		purgeCtx := models.WithAuditInfo(ctx, models.AuditInfo{Actor: "system:purge"})
+		result, err := subService.PurgeTrash(purgeCtx)
+		flushEvents()
+		if err != nil {
+			log.Fatalf("failed to purge trash: %v", err)
+		}
+		customLogger.Info().
+			Int64("purged", result.Purged).
+			Time("deletedBefore", result.DeletedBefore).
+			Msg("Trash purged")
+		return
+	}
+
+	// --- import command: импортировать подписки из CSV, напечатать отчёт и выйти ---
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		importCtx := models.WithAuditInfo(ctx, models.AuditInfo{Actor: "system:import"})
+		code := runImport(importCtx, importService, os.Args[2:], customLogger)
+		flushEvents()
+		if code != 0 {
+			os.Exit(code)
+		}
+		return
+	}
 
 	// --- init handlers ---
-	api.NewHandler(app, subService, customLogger)
+	api.NewHandler(app, subService, reminderService, webhookService, importService, customLogger)
 	api.RegisterSwagger(app)
 
 	// --- run server ---
@@ -79,6 +228,17 @@ func main() {
 		Handler: app,
 	}
 
+	// --- run workers ---
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+	go workers.NewWorker("lifecycle", workers.Transitions(subService), workers.SystemClock{}, lifecycleConfig.Interval, customLogger).Run(workersCtx)
+	go workers.NewWorker("renewal", workers.Renewal(subService), workers.SystemClock{}, renewalConfig.Interval, customLogger).Run(workersCtx)
+	if withPostgres {
+		go workers.NewWorker("reminder", reminderService, workers.SystemClock{}, reminderConfig.Interval, customLogger).Run(workersCtx)
+		go workers.NewWorker("webhook", webhookService, workers.SystemClock{}, webhookConfig.Interval, customLogger).Run(workersCtx)
+		go workers.NewWorker("outbox", outboxRelay, workers.SystemClock{}, brokerConfig.Interval, customLogger).Run(workersCtx)
+	}
+
 	customLogger.Info().Msgf("Starting server on %s", addr)
 
 	// Запускаем сервер в отдельной горутине
@@ -94,6 +254,7 @@ func main() {
 	<-quit
 
 	customLogger.Info().Msg("Shutting down server...")
+	stopWorkers()
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -103,4 +264,13 @@ func main() {
 	} else {
 		customLogger.Info().Msg("Server exited gracefully")
 	}
+
+	// Импорты публикуют события, поэтому останавливаются до закрытия диспетчера
+	if err := importService.Shutdown(shutdownCtx); err != nil {
+		customLogger.Error().Err(err).Msg("Background imports were not stopped")
+	}
+
+	if err := closeEvents(shutdownCtx); err != nil {
+		customLogger.Error().Err(err).Msg("Pending events were not handled")
+	}
 }
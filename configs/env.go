@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -24,6 +25,18 @@ func getString(key, defaultValue string) string {
 	return value
 }
 
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return d
+}
+
 func getInt(key string, defaultValue int) int {
 	value := os.Getenv(key)
 	if value == "" {
@@ -38,11 +51,22 @@ func getInt(key string, defaultValue int) int {
 
 type DatabaseConfig struct {
 	Url string
+	// Storage — хранилище подписок: database (PostgreSQL или SQLite по схеме DB_URL)
+	// или memory (в памяти процесса, без БД); postgres — прежнее название database
+	Storage string
+	// TxIsolation — уровень изоляции транзакций единицы работы в PostgreSQL:
+	// read_committed, repeatable_read или serializable
+	TxIsolation string
+	// TxMaxAttempts — сколько раз выполняется транзакция при конфликтах сериализации
+	TxMaxAttempts int
 }
 
 func NewDataBaseConfig() *DatabaseConfig {
 	return &DatabaseConfig{
-		Url: getString("DB_URL", ""),
+		Url:           getString("DB_URL", ""),
+		Storage:       getString("STORAGE", "database"),
+		TxIsolation:   getString("TX_ISOLATION", "read_committed"),
+		TxMaxAttempts: getInt("TX_MAX_ATTEMPTS", 3),
 	}
 }
 
@@ -67,3 +91,173 @@ func NewServerConfig() *ServerConfig {
 		Port: getString("HTTP_PORT", "8081"),
 	}
 }
+
+type ExchangeRatesConfig struct {
+	// File — путь к JSON-файлу с курсами; если пуст, курсы берутся из БД
+	File string
+}
+
+func NewExchangeRatesConfig() *ExchangeRatesConfig {
+	return &ExchangeRatesConfig{
+		File: getString("EXCHANGE_RATES_FILE", ""),
+	}
+}
+
+type TrashConfig struct {
+	// Retention — срок хранения удалённых подписок до окончательной очистки
+	Retention time.Duration
+}
+
+func NewTrashConfig() *TrashConfig {
+	return &TrashConfig{
+		Retention: getDuration("TRASH_RETENTION", 30*24*time.Hour),
+	}
+}
+
+type LifecycleConfig struct {
+	// Interval — как часто подписки с прошедшей датой окончания переводятся в expired
+	Interval time.Duration
+}
+
+func NewLifecycleConfig() *LifecycleConfig {
+	return &LifecycleConfig{
+		Interval: getDuration("LIFECYCLE_INTERVAL", time.Minute),
+	}
+}
+
+type RenewalConfig struct {
+	// Interval — как часто создаются списания за наступившие периоды оплаты
+	Interval time.Duration
+}
+
+func NewRenewalConfig() *RenewalConfig {
+	return &RenewalConfig{
+		Interval: getDuration("RENEWAL_INTERVAL", time.Minute),
+	}
+}
+
+type NotifierConfig struct {
+	// Kind — способ доставки напоминаний: log, smtp или webhook
+	Kind string
+	// SMTPAddr — адрес SMTP-сервера host:port
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// SMTPRecipient — шаблон адреса получателя, {user_id} заменяется идентификатором пользователя
+	SMTPRecipient string
+	WebhookURL    string
+}
+
+func NewNotifierConfig() *NotifierConfig {
+	return &NotifierConfig{
+		Kind:          getString("NOTIFIER", "log"),
+		SMTPAddr:      getString("SMTP_ADDR", "localhost:25"),
+		SMTPUsername:  getString("SMTP_USERNAME", ""),
+		SMTPPassword:  getString("SMTP_PASSWORD", ""),
+		SMTPFrom:      getString("SMTP_FROM", "noreply@localhost"),
+		SMTPRecipient: getString("SMTP_RECIPIENT", "{user_id}@localhost"),
+		WebhookURL:    getString("NOTIFY_WEBHOOK_URL", ""),
+	}
+}
+
+type ReminderConfig struct {
+	// Lead — за сколько до события отправляется напоминание
+	Lead time.Duration
+	// Interval — как часто проверяются предстоящие события
+	Interval time.Duration
+}
+
+func NewReminderConfig() *ReminderConfig {
+	return &ReminderConfig{
+		Lead:     getDuration("REMINDER_LEAD", 72*time.Hour),
+		Interval: getDuration("REMINDER_INTERVAL", time.Minute),
+	}
+}
+
+type WebhookConfig struct {
+	// Interval — как часто отправляются доставки вебхуков из очереди
+	Interval time.Duration
+	// Timeout — время ожидания ответа получателя
+	Timeout time.Duration
+}
+
+func NewWebhookConfig() *WebhookConfig {
+	return &WebhookConfig{
+		Interval: getDuration("WEBHOOK_INTERVAL", 5*time.Second),
+		Timeout:  getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+	}
+}
+
+type EventsConfig struct {
+	// Dispatch — доставка доменных событий обработчикам: sync (в горутине публикации) или async (пул обработчиков)
+	Dispatch string
+	// Workers и Buffer — число обработчиков и размер очереди каждого в режиме async
+	Workers int
+	Buffer  int
+}
+
+func NewEventsConfig() *EventsConfig {
+	return &EventsConfig{
+		Dispatch: getString("EVENTS_DISPATCH", "sync"),
+		Workers:  getInt("EVENTS_WORKERS", 4),
+		Buffer:   getInt("EVENTS_BUFFER", 1024),
+	}
+}
+
+type BrokerConfig struct {
+	// Kind — брокер для публикации изменений подписок: memory, file или nats
+	Kind string
+	// MemoryLimit — сколько последних событий хранит брокер memory
+	MemoryLimit int
+	// File — файл JSON Lines для брокера file
+	File string
+	// NATSURL и NATSSubjectPrefix — сервер NATS и префикс subject событий
+	NATSURL           string
+	NATSSubjectPrefix string
+	// NATSStream — поток JetStream, в который публикуются события
+	NATSStream string
+	// Timeout — время ожидания подтверждения публикации
+	Timeout time.Duration
+	// Source — атрибут source публикуемых CloudEvents
+	Source string
+	// Interval — как часто неопубликованные изменения передаются в брокер
+	Interval time.Duration
+}
+
+func NewBrokerConfig() *BrokerConfig {
+	return &BrokerConfig{
+		Kind:              getString("BROKER", "memory"),
+		MemoryLimit:       getInt("BROKER_MEMORY_LIMIT", 1000),
+		File:              getString("BROKER_FILE", ""),
+		NATSURL:           getString("NATS_URL", "nats://localhost:4222"),
+		NATSSubjectPrefix: getString("NATS_SUBJECT_PREFIX", "subscriptions"),
+		NATSStream:        getString("NATS_STREAM", "SUBSCRIPTIONS"),
+		Timeout:           getDuration("BROKER_TIMEOUT", 5*time.Second),
+		Source:            getString("EVENTS_SOURCE", "/subscription-service"),
+		Interval:          getDuration("OUTBOX_INTERVAL", time.Second),
+	}
+}
+
+type ImportConfig struct {
+	// Mapping — соответствие заголовков CSV полям подписки по умолчанию: "Заголовок=поле,..."
+	Mapping string
+	// MaxBytes — наибольший размер импортируемого файла
+	MaxBytes int64
+	// SyncLimit — файлы не больше этого размера импортируются в запросе, остальные — в фоне
+	SyncLimit int64
+	// MaxErrors — сколько ошибок строк попадает в отчёт об импорте
+	MaxErrors int
+	// JobTTL — сколько хранится отчёт о завершённом фоновом импорте
+	JobTTL time.Duration
+}
+
+func NewImportConfig() *ImportConfig {
+	return &ImportConfig{
+		Mapping:   getString("IMPORT_MAPPING", ""),
+		MaxBytes:  int64(getInt("IMPORT_MAX_BYTES", 100<<20)),
+		SyncLimit: int64(getInt("IMPORT_SYNC_LIMIT", 1<<20)),
+		MaxErrors: getInt("IMPORT_MAX_ERRORS", 1000),
+		JobTTL:    getDuration("IMPORT_JOB_TTL", 24*time.Hour),
+	}
+}
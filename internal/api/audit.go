@@ -0,0 +1,33 @@
+package api
+
+import (
+	"SubscriptionService/internal/core/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Заголовки, из которых берутся сведения для журнала аудита
+const (
+	headerRequestID = "X-Request-ID"
+	headerActor     = "X-Actor"
+)
+
+// AuditContext кладёт в контекст запроса инициатора изменения (X-Actor)
+// и идентификатор запроса (X-Request-ID, генерируется при отсутствии).
+// Идентификатор запроса возвращается в одноимённом заголовке ответа.
+func AuditContext() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(headerRequestID)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		ctx.Header(headerRequestID, requestID)
+
+		ctx.Request = ctx.Request.WithContext(models.WithAuditInfo(ctx.Request.Context(), models.AuditInfo{
+			Actor:     ctx.GetHeader(headerActor),
+			RequestId: requestID,
+		}))
+		ctx.Next()
+	}
+}
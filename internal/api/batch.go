@@ -0,0 +1,87 @@
+package api
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Статусы выполненных операций пакетного запроса — как у отдельных запросов
+var batchStatus = map[string]int{
+	dto.BatchCreate: http.StatusCreated,
+	dto.BatchUpdate: http.StatusOK,
+	dto.BatchDelete: http.StatusNoContent,
+}
+
+// collectionMethod направляет POST /subscriptions:<метод> обработчику метода.
+// gin не отделяет двоеточие внутри сегмента пути, поэтому метод приходит параметром.
+func (h *Handler) collectionMethod(ctx *gin.Context) {
+	switch ctx.Param("method") {
+	case ":batch":
+		h.Batch(ctx)
+	default:
+		_ = ctx.Error(fmt.Errorf("%w: unknown method %q", models.ErrNotFound, strings.TrimPrefix(ctx.Param("method"), ":")))
+	}
+}
+
+// Batch выполняет пакет операций создания, замены и удаления подписок.
+// С atomic=true операции выполняются в одной транзакции, и ошибка любой из них
+// отменяет пакет и возвращается как ошибка запроса. Без него операции независимы:
+// статус и ошибка каждой возвращаются в results, а ответ всегда 200.
+func (h *Handler) Batch(ctx *gin.Context) {
+	h.customLogger.Debug().Msg("Batch subscriptions: started")
+
+	var request dto.BatchRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Msg("Batch subscriptions: invalid request")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	atomic, err := queryBool(ctx, "atomic", false)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Msg("Batch subscriptions: invalid atomic")
+		_ = ctx.Error(err)
+		return
+	}
+	request.Atomic = atomic
+
+	results, err := h.service.Batch(ctx, request)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Int("operations", len(request.Operations)).
+			Msg("Batch subscriptions: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	response := dto.BatchResponse{Atomic: atomic, Results: results}
+	for _, result := range results {
+		if result.Err == nil {
+			result.Status = batchStatus[result.Op]
+			response.Succeeded++
+			continue
+		}
+		problem := newProblem(&gin.Error{Err: result.Err, Type: gin.ErrorTypePrivate})
+		result.Status = problem.Status
+		result.Error = &problem
+		response.Failed++
+	}
+
+	h.customLogger.
+		Info().
+		Int("succeeded", response.Succeeded).
+		Int("failed", response.Failed).
+		Bool("atomic", atomic).
+		Msg("Batch subscriptions: success")
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBatchReportsStatusPerOperation(t *testing.T) {
+	app := newTestServer(t)
+	updated := createSub(t, app, "Netflix", 400)
+	deleted := createSub(t, app, "Spotify", 300)
+	stale := createSub(t, app, "YouTube", 200)
+
+	rec := do(t, app, http.MethodPost, "/api/v1/subscriptions:batch", map[string]any{
+		"operations": []map[string]any{
+			{"op": "create", "subscription": subscriptionBody("Kinopoisk", 300)},
+			{"op": "update", "id": updated.Id, "subscription": subscriptionBody("Netflix", 500)},
+			{"op": "delete", "id": deleted.Id},
+			{"op": "update", "id": uuid.New(), "subscription": subscriptionBody("Missing", 100)},
+			{"op": "delete", "id": stale.Id, "version": stale.Version + 1},
+		},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+	}
+
+	response := decode[dto.BatchResponse](t, rec)
+	want := []int{http.StatusCreated, http.StatusOK, http.StatusNoContent, http.StatusNotFound, http.StatusPreconditionFailed}
+	if len(response.Results) != len(want) {
+		t.Fatalf("results = %d, want %d", len(response.Results), len(want))
+	}
+	for i, result := range response.Results {
+		if result.Status != want[i] {
+			t.Errorf("operation %d: status %d, want %d", i, result.Status, want[i])
+		}
+		if failed := result.Status >= http.StatusBadRequest; failed != (result.Error != nil) {
+			t.Errorf("operation %d: status %d with error %+v", i, result.Status, result.Error)
+		}
+	}
+	if response.Succeeded != 3 || response.Failed != 2 {
+		t.Errorf("succeeded %d, failed %d, want 3 and 2", response.Succeeded, response.Failed)
+	}
+}
+
+func TestBatchAtomicFailsRequest(t *testing.T) {
+	app := newTestServer(t)
+	sub := createSub(t, app, "Netflix", 400)
+
+	rec := do(t, app, http.MethodPost, "/api/v1/subscriptions:batch?atomic=true", map[string]any{
+		"operations": []map[string]any{
+			{"op": "create", "subscription": subscriptionBody("Kinopoisk", 300)},
+			{"op": "delete", "id": sub.Id, "version": sub.Version + 1},
+		},
+	})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status %d, want %d, body %s", rec.Code, http.StatusPreconditionFailed, rec.Body)
+	}
+
+	rec = do(t, app, http.MethodGet, "/api/v1/subscriptions", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list: status %d, body %s", rec.Code, rec.Body)
+	}
+	if list := decode[dto.GetAllResponse](t, rec); len(list.Data) != 1 {
+		t.Errorf("subscriptions = %d, want 1: batch was not rolled back", len(list.Data))
+	}
+}
+
+func TestCollectionMethodDispatch(t *testing.T) {
+	app := newTestServer(t)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/v1/subscriptions:batch", http.StatusOK},
+		{"/api/v1/subscriptions:unknown", http.StatusNotFound},
+	}
+	body := map[string]any{
+		"operations": []map[string]any{{"op": "create", "subscription": subscriptionBody("Netflix", 400)}},
+	}
+	for _, tt := range tests {
+		if rec := do(t, app, http.MethodPost, tt.path, body); rec.Code != tt.want {
+			t.Errorf("POST %s: status %d, want %d, body %s", tt.path, rec.Code, tt.want, rec.Body)
+		}
+	}
+}
@@ -1,29 +1,218 @@
 package dto
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type CreateSubscriptionRequest struct {
-	ServiceName string     `json:"service_name" binding:"required,min=2,max=100"`
-	Price       int64      `json:"price" binding:"required,min=1"`
-	UserID      uuid.UUID  `json:"user_id" binding:"required,uuid"`
-	StartDate   time.Time  `json:"start_date" binding:"required"`
-	EndDate     *time.Time `json:"end_date,omitempty"`
+	ServiceName     string    `json:"service_name" binding:"required,min=2,max=100"`
+	Price           int64     `json:"price" binding:"required,min=1"`
+	Currency        string    `json:"currency,omitempty" binding:"omitempty,iso4217"`
+	UserID          uuid.UUID `json:"user_id" binding:"required,uuid"`
+	BillingInterval string    `json:"billing_interval,omitempty" binding:"omitempty,oneof=weekly monthly quarterly yearly custom"`
+	IntervalDays    *int      `json:"interval_days,omitempty" binding:"omitempty,min=1"`
+	// State — начальное состояние: active (по умолчанию) или trial
+	State string `json:"state,omitempty" binding:"omitempty,oneof=trial active"`
+	// TrialDays и TrialEnd — пробный период в днях от start_date или дата его окончания
+	TrialDays *int       `json:"trial_days,omitempty" binding:"omitempty,min=1,excluded_with=TrialEnd"`
+	TrialEnd  *time.Time `json:"trial_end,omitempty"`
+	StartDate time.Time  `json:"start_date" binding:"required"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
 }
 
+// UpdateSubscriptionRequest — полная замена подписки (PUT): пропущенные
+// необязательные поля сбрасываются к значениям по умолчанию.
 type UpdateSubscriptionRequest struct {
-	ServiceName *string    `json:"service_name,omitempty"`
-	Price       *int64     `json:"price,omitempty"`
-	StartDate   *time.Time `json:"start_date,omitempty"`
-	EndDate     *time.Time `json:"end_date,omitempty"`
+	ServiceName     string     `json:"service_name" binding:"required,min=2,max=100"`
+	Price           int64      `json:"price" binding:"required,min=1"`
+	Currency        string     `json:"currency,omitempty" binding:"omitempty,iso4217"`
+	BillingInterval string     `json:"billing_interval,omitempty" binding:"omitempty,oneof=weekly monthly quarterly yearly custom"`
+	IntervalDays    *int       `json:"interval_days,omitempty" binding:"omitempty,min=1"`
+	StartDate       time.Time  `json:"start_date" binding:"required"`
+	EndDate         *time.Time `json:"end_date,omitempty"`
+}
+
+// Операции пакетного запроса
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchRequest — пакет операций над подписками (POST /subscriptions:batch).
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,max=1000,dive"`
+	// Atomic — все операции в одной транзакции; задаётся параметром запроса atomic
+	Atomic bool `json:"-"`
+}
+
+// BatchOperation — создание (create), полная замена (update) или удаление (delete) подписки.
+type BatchOperation struct {
+	Op string     `json:"op" binding:"required,oneof=create update delete"`
+	ID *uuid.UUID `json:"id,omitempty" binding:"required_unless=Op create,excluded_if=Op create"`
+	// Version — ожидаемая версия подписки, как в заголовке If-Match
+	Version      *int64            `json:"version,omitempty" binding:"excluded_if=Op create"`
+	Subscription BatchSubscription `json:"subscription"`
+}
+
+// BatchSubscription — тело операции: для create — CreateSubscriptionRequest,
+// для update — UpdateSubscriptionRequest. Тип выбирается по BatchOperation.Op.
+type BatchSubscription struct {
+	Create *CreateSubscriptionRequest
+	Update *UpdateSubscriptionRequest
+}
+
+func (o *BatchOperation) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Op           string          `json:"op"`
+		ID           *uuid.UUID      `json:"id"`
+		Version      *int64          `json:"version"`
+		Subscription json.RawMessage `json:"subscription"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*o = BatchOperation{Op: raw.Op, ID: raw.ID, Version: raw.Version}
+
+	var target any
+	switch raw.Op {
+	case BatchCreate:
+		o.Subscription.Create = &CreateSubscriptionRequest{}
+		target = o.Subscription.Create
+	case BatchUpdate:
+		o.Subscription.Update = &UpdateSubscriptionRequest{}
+		target = o.Subscription.Update
+	default:
+		// Тело удаления не нужно, неизвестную операцию отклонит валидация
+		return nil
+	}
+	if len(raw.Subscription) == 0 || string(raw.Subscription) == "null" {
+		return fmt.Errorf("subscription is required for %s operation", raw.Op)
+	}
+	return json.Unmarshal(raw.Subscription, target)
+}
+
+// SchedulePriceRequest — запланированное изменение цены подписки.
+type SchedulePriceRequest struct {
+	Price int64 `json:"price" binding:"required,min=1"`
+	// EffectiveFrom — дата вступления в силу (время не учитывается), должна быть в будущем
+	EffectiveFrom time.Time `json:"effective_from" binding:"required"`
+}
+
+// PatchFormat — формат тела PATCH-запроса.
+type PatchFormat string
+
+const (
+	// PatchMerge — JSON Merge Patch (RFC 7396), application/merge-patch+json
+	PatchMerge PatchFormat = "merge-patch"
+	// PatchJSON — JSON Patch (RFC 6902), application/json-patch+json
+	PatchJSON PatchFormat = "json-patch"
+)
+
+type PatchSubscriptionRequest struct {
+	Format PatchFormat
+	Patch  []byte
+}
+
+// SubscriptionPatchDocument — изменяемые поля подписки, к которым применяется PATCH.
+// Все поля присутствуют в документе (в том числе null), чтобы пути JSON Patch существовали.
+// Документ после патча проверяется теми же правилами, что и UpdateSubscriptionRequest.
+type SubscriptionPatchDocument struct {
+	ServiceName     string     `json:"service_name" binding:"required,min=2,max=100"`
+	Price           int64      `json:"price" binding:"required,min=1"`
+	Currency        string     `json:"currency" binding:"omitempty,iso4217"`
+	BillingInterval string     `json:"billing_interval" binding:"omitempty,oneof=weekly monthly quarterly yearly custom"`
+	IntervalDays    *int       `json:"interval_days" binding:"omitempty,min=1"`
+	StartDate       time.Time  `json:"start_date" binding:"required"`
+	EndDate         *time.Time `json:"end_date"`
+}
+
+// SubFilterQuery — фильтры подписок, общие для списка и расчёта стоимости.
+type SubFilterQuery struct {
+	UserID      string `json:"user_id,omitempty" form:"user_id" binding:"omitempty,uuid"`
+	ServiceName string `json:"service_name,omitempty" form:"service_name"`
+	// ServiceNamePrefix — префикс имени сервиса без учёта регистра
+	ServiceNamePrefix string    `json:"service_name_prefix,omitempty" form:"service_name_prefix"`
+	Status            string    `json:"status,omitempty" form:"status" binding:"omitempty,oneof=active expired"`
+	State             string    `json:"state,omitempty" form:"state" binding:"omitempty,oneof=trial active paused cancelled expired"`
+	MinPrice          *int64    `json:"min_price,omitempty" form:"min_price" binding:"omitempty,min=0"`
+	MaxPrice          *int64    `json:"max_price,omitempty" form:"max_price" binding:"omitempty,min=0"`
+	From              time.Time `json:"from" form:"from"`
+	To                time.Time `json:"to" form:"to"`
+	// IncludeDeleted добавляет подписки из корзины
+	IncludeDeleted bool `json:"include_deleted,omitempty" form:"include_deleted"`
+}
+
+type GetAllQueryRequest struct {
+	SubFilterQuery
+	// Sort — поля через запятую, минус означает убывание: "price,-start_date"
+	Sort string `json:"sort,omitempty" form:"sort"`
+	// OnlyDeleted — выборка только из корзины, задаётся маршрутом, а не параметром запроса
+	OnlyDeleted bool `json:"-" form:"-"`
+}
+
+// EndingTrialsQueryRequest — пробные подписки, пробный период которых скоро закончится.
+type EndingTrialsQueryRequest struct {
+	UserID string `json:"user_id,omitempty" form:"user_id" binding:"omitempty,uuid"`
+	// WithinDays — горизонт в днях от текущего момента (по умолчанию 3)
+	WithinDays int `json:"within_days,omitempty" form:"within_days" binding:"omitempty,min=1,max=365"`
+}
+
+// UpcomingQueryRequest — горизонт просмотра предстоящих событий подписок пользователя.
+type UpcomingQueryRequest struct {
+	// WithinDays — горизонт в днях от текущего момента (по умолчанию 30)
+	WithinDays int `json:"within_days,omitempty" form:"within_days" binding:"omitempty,min=1,max=365"`
+}
+
+// PageRequest — параметры пагинации списка: по номеру страницы или по курсору.
+type PageRequest struct {
+	Page     int64
+	PageSize int64
+	// Cursor не nil в режиме пагинации по курсору; пустая строка — первая страница
+	Cursor    *string
+	WithTotal bool
 }
 
 type CostCalculationQueryRequest struct {
-	UserID      uuid.UUID `json:"user_id" form:"user_id"`
-	ServiceName string    `json:"service_name" form:"service_name"`
-	From        time.Time `json:"from" form:"from"`
-	To          time.Time `json:"to" form:"to"`
+	SubFilterQuery
+	// Currency — валюта, в которую пересчитываются суммы (по умолчанию RUB)
+	Currency string `json:"currency,omitempty" form:"currency" binding:"omitempty,iso4217"`
+	// Period — период, к которому приводится стоимость (например, yearly — годовой эквивалент)
+	Period string `json:"period,omitempty" form:"period" binding:"omitempty,oneof=weekly monthly quarterly yearly"`
+}
+
+type CostBreakdownQueryRequest struct {
+	CostCalculationQueryRequest
+	GroupBy string `json:"group_by" form:"group_by" binding:"required,oneof=service_name user_id month year"`
+}
+
+// WebhookEndpointRequest — получатель вебхуков; при изменении (PUT) заменяются все поля,
+// кроме секрета: пустой секрет оставляет прежний, а при создании генерируется случайный.
+type WebhookEndpointRequest struct {
+	URL    string `json:"url" binding:"required,url,max=2048"`
+	Secret string `json:"secret,omitempty" binding:"omitempty,min=16,max=256"`
+	// Events — операции, о которых сообщать; пустой список — обо всех
+	Events []string `json:"events,omitempty" binding:"omitempty,dive,oneof=create update delete restore purge activate pause resume cancel expire schedule_price"`
+	// Active — включена ли доставка (по умолчанию true)
+	Active *bool `json:"active,omitempty"`
+}
+
+// DeliveriesQueryRequest — фильтр доставок вебхуков; status=dead — список недоставленных.
+type DeliveriesQueryRequest struct {
+	EndpointID string `json:"endpoint_id,omitempty" form:"endpoint_id" binding:"omitempty,uuid"`
+	Status     string `json:"status,omitempty" form:"status" binding:"omitempty,oneof=pending delivered dead"`
+}
+
+// ImportRequest — параметры импорта подписок из CSV.
+type ImportRequest struct {
+	// DryRun — только проверить строки, не создавая подписок
+	DryRun bool `json:"dry_run,omitempty" form:"dry_run"`
+	// Delimiter — разделитель полей (по умолчанию запятая)
+	Delimiter string `json:"delimiter,omitempty" form:"delimiter" binding:"omitempty,len=1"`
+	// Mapping — соответствие заголовков полям "Заголовок=поле,...", дополняет IMPORT_MAPPING
+	Mapping string `json:"mapping,omitempty" form:"mapping"`
 }
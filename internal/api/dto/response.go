@@ -2,6 +2,9 @@ package dto
 
 import (
 	"SubscriptionService/internal/core/models"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type GetAllResponse struct {
@@ -10,8 +13,142 @@ type GetAllResponse struct {
 }
 
 type PaginationInfo struct {
-	Page       int64 `json:"page"`
-	PageSize   int64 `json:"page_size"`
-	TotalCount int64 `json:"total_count"`
-	TotalPages int64 `json:"total_pages"`
+	Page     int64 `json:"page,omitempty"`
+	PageSize int64 `json:"page_size"`
+	// TotalCount и TotalPages заполняются, только если запрошено общее количество
+	TotalCount *int64 `json:"total_count,omitempty"`
+	TotalPages *int64 `json:"total_pages,omitempty"`
+	// NextCursor и PrevCursor заполняются в режиме пагинации по курсору
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+type CostBreakdownResponse struct {
+	GroupBy   string                    `json:"group_by"`
+	Currency  string                    `json:"currency"`
+	TotalCost int64                     `json:"total_cost"`
+	Groups    []*CostBreakdownGroup     `json:"groups"`
+	Rates     []models.ExchangeRate     `json:"rates,omitempty"`
+	Filters   CostBreakdownQueryRequest `json:"filters"`
+}
+
+type CostBreakdownGroup struct {
+	models.CostGroup
+	// Filters — фильтры, с которыми /cost возвращает итог этой группы
+	Filters CostCalculationQueryRequest `json:"filters"`
+}
+
+// PurgeResponse — результат очистки корзины.
+type PurgeResponse struct {
+	Purged        int64     `json:"purged"`
+	DeletedBefore time.Time `json:"deleted_before"`
+}
+
+// HistoryResponse — страница журнала изменений подписки.
+type HistoryResponse struct {
+	Data       []*models.SubscriptionEvent `json:"data"`
+	Pagination *PaginationInfo             `json:"pagination"`
+}
+
+// ChargesResponse — страница списаний подписки.
+type ChargesResponse struct {
+	Data       []*models.Charge `json:"data"`
+	Pagination *PaginationInfo  `json:"pagination"`
+}
+
+// UpcomingResponse — предстоящие события подписок пользователя и напоминания о них.
+type UpcomingResponse struct {
+	Data []*models.Reminder `json:"data"`
+	// Lead — за сколько до события отправляется напоминание
+	Lead string `json:"lead"`
+}
+
+// PricesResponse — история и план цен подписки по возрастанию даты.
+type PricesResponse struct {
+	Data []*models.PriceChange `json:"data"`
+}
+
+// WebhookEndpointsResponse — зарегистрированные получатели вебхуков.
+type WebhookEndpointsResponse struct {
+	Data []*models.WebhookEndpoint `json:"data"`
+}
+
+// DeliveriesResponse — страница доставок вебхуков.
+type DeliveriesResponse struct {
+	Data       []*models.WebhookDelivery `json:"data"`
+	Pagination *PaginationInfo           `json:"pagination"`
+}
+
+// BatchResponse — результаты пакетного запроса в порядке операций.
+type BatchResponse struct {
+	Atomic    bool           `json:"atomic"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Results   []*BatchResult `json:"results"`
+}
+
+// BatchResult — результат одной операции пакетного запроса.
+type BatchResult struct {
+	Index int    `json:"index"`
+	Op    string `json:"op"`
+	// Status — HTTP-статус, который вернул бы отдельный запрос с этой операцией
+	Status int        `json:"status"`
+	ID     *uuid.UUID `json:"id,omitempty"`
+	// Subscription — созданная или изменённая подписка
+	Subscription *models.Subscription `json:"subscription,omitempty"`
+	Error        *Problem             `json:"error,omitempty"`
+	// Err — ошибка операции; обработчик переводит её в Status и Error
+	Err error `json:"-"`
+}
+
+// Состояния импорта подписок
+const (
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// Категории ошибок строк импорта — как типы проблем в ответах API
+const (
+	ImportErrorValidation      = "validation"
+	ImportErrorInvalidArgument = "invalid_argument"
+	ImportErrorNotFound        = "not_found"
+	ImportErrorConflict        = "conflict"
+	ImportErrorPrecondition    = "precondition_failed"
+	ImportErrorUnavailable     = "unavailable"
+	ImportErrorInternal        = "internal"
+)
+
+// ImportJob — ход и отчёт импорта подписок из CSV.
+type ImportJob struct {
+	Id     uuid.UUID `json:"id"`
+	Status string    `json:"status"`
+	DryRun bool      `json:"dry_run"`
+	// Rows — обработанные строки данных; Valid — строки, прошедшие проверку (только при dry_run);
+	// Imported — созданные подписки; Failed — строки с ошибками
+	Rows     int64 `json:"rows"`
+	Valid    int64 `json:"valid"`
+	Imported int64 `json:"imported"`
+	Failed   int64 `json:"failed"`
+	// Progress — доля прочитанного файла, от 0 до 1
+	Progress float64          `json:"progress"`
+	Errors   []ImportRowError `json:"errors"`
+	// ErrorsTruncated — в отчёт вошли не все ошибки строк (см. IMPORT_MAX_ERRORS)
+	ErrorsTruncated bool `json:"errors_truncated,omitempty"`
+	// Error и ErrorCategory — причина, по которой импорт прерван (status=failed), и её категория
+	// (ImportError*); текст ошибок хранилища в отчёт не попадает
+	Error         string     `json:"error,omitempty"`
+	ErrorCategory string     `json:"error_category,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+// ImportRowError — ошибка строки CSV.
+type ImportRowError struct {
+	// Line — номер строки файла; заголовок — строка 1
+	Line int `json:"line"`
+	// Category — категория ошибки (ImportError*); текст ошибок хранилища в отчёт не попадает
+	Category string `json:"category"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
 }
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const problemContentType = "application/problem+json"
+
+// Типы тела PATCH-запроса
+const (
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+var errUnsupportedMediaType = fmt.Errorf("unsupported content type, expected %s or %s", mimeMergePatch, mimeJSONPatch)
+
+// Типы проблем RFC 7807 по категориям ошибок
+const (
+	problemInvalidArgument = "/problems/invalid-argument"
+	problemValidation      = "/problems/validation-failed"
+	problemNotFound        = "/problems/not-found"
+	problemConflict        = "/problems/conflict"
+	problemMediaType       = "/problems/unsupported-media-type"
+	problemPrecondition    = "/problems/precondition-failed"
+	problemUnavailable     = "/problems/unavailable"
+	problemInternal        = "/problems/internal"
+)
+
+// ErrorHandler отображает ошибку, добавленную обработчиком через ctx.Error,
+// в ответ application/problem+json. Статус определяется категорией ошибки (models.Err*).
+func ErrorHandler(logger *zerolog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		ginErr := ctx.Errors.Last()
+		problem := newProblem(ginErr)
+		problem.Instance = ctx.Request.URL.Path
+
+		logger.Debug().
+			Err(ginErr.Err).
+			Int("status", problem.Status).
+			Str("path", ctx.FullPath()).
+			Msg("Request failed")
+
+		ctx.Header("Content-Type", problemContentType)
+		ctx.JSON(problem.Status, problem)
+	}
+}
+
+func newProblem(ginErr *gin.Error) dto.Problem {
+	err := ginErr.Err
+
+	var validationErrs validator.ValidationErrors
+	var fieldErr *models.ValidationError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		problem := problemFor(http.StatusUnprocessableEntity, problemValidation, "request validation failed")
+		for _, fe := range validationErrs {
+			problem.Errors = append(problem.Errors, dto.ProblemField{
+				Field:   fieldPath(fe),
+				Code:    fe.Tag(),
+				Message: validationMessage(fe),
+			})
+		}
+		return problem
+	case errors.As(err, &typeErr):
+		problem := problemFor(http.StatusBadRequest, problemInvalidArgument, "malformed request body")
+		problem.Errors = []dto.ProblemField{{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		}}
+		return problem
+	case errors.Is(err, errUnsupportedMediaType):
+		return problemFor(http.StatusUnsupportedMediaType, problemMediaType, err.Error())
+	case ginErr.IsType(gin.ErrorTypeBind), errors.Is(err, models.ErrInvalidArgument):
+		return problemFor(http.StatusBadRequest, problemInvalidArgument, err.Error())
+	case errors.As(err, &fieldErr):
+		problem := problemFor(http.StatusUnprocessableEntity, problemValidation, fieldErr.Message)
+		problem.Errors = []dto.ProblemField{{
+			Field:   fieldErr.Field,
+			Code:    fieldErr.Code,
+			Message: fieldErr.Message,
+		}}
+		return problem
+	case errors.Is(err, models.ErrValidation):
+		return problemFor(http.StatusUnprocessableEntity, problemValidation, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return problemFor(http.StatusNotFound, problemNotFound, err.Error())
+	case errors.Is(err, models.ErrConflict):
+		return problemFor(http.StatusConflict, problemConflict, err.Error())
+	case errors.Is(err, models.ErrPreconditionFailed):
+		return problemFor(http.StatusPreconditionFailed, problemPrecondition, err.Error())
+	case errors.Is(err, models.ErrUnavailable):
+		// Подробности недоступности хранилища клиенту не раскрываются
+		return problemFor(http.StatusServiceUnavailable, problemUnavailable, "service temporarily unavailable")
+	default:
+		return problemFor(http.StatusInternalServerError, problemInternal, "internal server error")
+	}
+}
+
+func problemFor(status int, problemType, detail string) dto.Problem {
+	return dto.Problem{
+		Type:   problemType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// fieldPath возвращает путь к полю в именах JSON без имени корневой структуры
+// и встроенных структур (SubFilterQuery и т.п.).
+func fieldPath(fe validator.FieldError) string {
+	parts := strings.Split(fe.Namespace(), ".")
+	if len(parts) <= 1 {
+		return fe.Field()
+	}
+
+	path := parts[1:]
+	kept := path[:0]
+	for _, part := range path {
+		if part != "" && part[0] >= 'A' && part[0] <= 'Z' {
+			continue
+		}
+		kept = append(kept, part)
+	}
+	if len(kept) == 0 {
+		return fe.Field()
+	}
+	return strings.Join(kept, ".")
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_unless":
+		return "is required"
+	case "excluded_if":
+		return "is not allowed"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters long", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "uuid":
+		return "must be a valid UUID"
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+	}
+}
+
+// registerValidatorTagNames заставляет валидатор gin называть поля по тегам json/form,
+// чтобы в ошибках были те же имена, что и в запросе.
+func registerValidatorTagNames() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
+
+// parseID разбирает параметр пути :id.
+func parseID(ctx *gin.Context) (uuid.UUID, error) {
+	return parseUUIDParam(ctx, "id")
+}
+
+// parseUUIDParam разбирает параметр маршрута name как UUID.
+func parseUUIDParam(ctx *gin.Context, name string) (uuid.UUID, error) {
+	value := ctx.Param(name)
+	if value == "" {
+		return uuid.Nil, fmt.Errorf("%w: %s is required", models.ErrInvalidArgument, name)
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid %s format", models.ErrInvalidArgument, name)
+	}
+	return id, nil
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"SubscriptionService/internal/core/models"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// setETag выставляет заголовок ETag по версии подписки.
+func setETag(ctx *gin.Context, sub *models.Subscription) {
+	ctx.Header("ETag", sub.ETag())
+}
+
+// parseIfMatch возвращает версии, перечисленные в заголовке If-Match.
+// Без заголовка или для "*" возвращает nil — изменение безусловное.
+// If-Match требует строгого сравнения, поэтому слабые и чужие ETag
+// не совпадают ни с одной версией.
+func parseIfMatch(ctx *gin.Context) ([]int64, error) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		unquoted, err := strconv.Unquote(strings.TrimSpace(tag))
+		if err != nil {
+			continue
+		}
+		if version, err := strconv.ParseInt(unquoted, 10, 64); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: If-Match does not match any version", models.ErrPreconditionFailed)
+	}
+	return versions, nil
+}
+
+// ifMatch возвращает версию, которую должна иметь подписка id, чтобы изменение выполнилось.
+// Если If-Match перечисляет несколько версий, выбирается совпадающая с текущей (RFC 9110):
+// хранилище всё равно сверяет её при изменении, поэтому гонка с другим запросом даёт 412.
+func (h *Handler) ifMatch(ctx *gin.Context, id uuid.UUID) (*int64, error) {
+	versions, err := parseIfMatch(ctx)
+	if err != nil || len(versions) == 0 {
+		return nil, err
+	}
+	if len(versions) == 1 {
+		return &versions[0], nil
+	}
+
+	current, err := h.service.GetById(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(versions, current.Version) {
+		return nil, fmt.Errorf("%w: If-Match does not match version %d", models.ErrPreconditionFailed, current.Version)
+	}
+	return &current.Version, nil
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"SubscriptionService/internal/core/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header   string
+		versions []int64
+		err      error
+	}{
+		{"", nil, nil},
+		{"*", nil, nil},
+		{`"3"`, []int64{3}, nil},
+		{`"3", "4"`, []int64{3, 4}, nil},
+		{`W/"3", "4"`, []int64{4}, nil},
+		{`"abc", "5"`, []int64{5}, nil},
+		{`W/"3"`, nil, models.ErrPreconditionFailed},
+		{`"abc"`, nil, models.ErrPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+			ctx.Request.Header.Set("If-Match", tt.header)
+
+			versions, err := parseIfMatch(ctx)
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if !slices.Equal(versions, tt.versions) {
+				t.Fatalf("expected versions %v, got %v", tt.versions, versions)
+			}
+		})
+	}
+}
+
+// Любая из перечисленных в If-Match версий может совпасть с текущей.
+func TestIfMatchAnyListedVersion(t *testing.T) {
+	app := newTestServer(t)
+	sub := createSub(t, app, "Netflix", 100)
+	path := fmt.Sprintf("/api/v1/subscriptions/%s", sub.Id)
+
+	rec := do(t, app, http.MethodPut, path, subscriptionBody("Netflix", 200), "If-Match", `"7", "1"`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("put with matching version in list: status %d, body %s", rec.Code, rec.Body)
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"2"` {
+		t.Fatalf("expected ETag \"2\", got %s", etag)
+	}
+
+	rec = do(t, app, http.MethodDelete, path, nil, "If-Match", `"1", "3"`)
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("delete without current version in list: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = do(t, app, http.MethodDelete, path, nil, "If-Match", `"1", "2"`)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete with current version in list: status %d, body %s", rec.Code, rec.Body)
+	}
+}
@@ -3,24 +3,36 @@ package api
 import (
 	"SubscriptionService/internal/api/dto"
 	"SubscriptionService/internal/application/app_interfaces"
+	"SubscriptionService/internal/core/models"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
 type Handler struct {
 	route        *gin.Engine
 	service      app_interfaces.ISubService
+	reminders    app_interfaces.IReminderService
+	webhooks     app_interfaces.IWebhookService
+	imports      app_interfaces.IImportService
 	customLogger *zerolog.Logger
 }
 
-func NewHandler(r *gin.Engine, s app_interfaces.ISubService, l *zerolog.Logger) *Handler {
+func NewHandler(r *gin.Engine, s app_interfaces.ISubService, rs app_interfaces.IReminderService, ws app_interfaces.IWebhookService, is app_interfaces.IImportService, l *zerolog.Logger) *Handler {
+	registerValidatorTagNames()
+	// Сервисы получают *gin.Context как context.Context; значения контекста запроса
+	// (например, models.AuditInfo) должны быть доступны через него
+	r.ContextWithFallback = true
+
 	handler := &Handler{
 		route:        r,
 		service:      s,
+		reminders:    rs,
+		webhooks:     ws,
+		imports:      is,
 		customLogger: l,
 	}
 	handler.registerRoutes()
@@ -31,6 +43,7 @@ func (h *Handler) registerRoutes() {
 	h.route.GET("/health", h.Health)
 
 	api := h.route.Group("/api/v1")
+	api.Use(AuditContext(), ErrorHandler(h.customLogger))
 	{
 		subs := api.Group("/subscriptions")
 		{
@@ -38,8 +51,42 @@ func (h *Handler) registerRoutes() {
 			subs.GET("/:id", h.GetById)
 			subs.GET("", h.GetAll)
 			subs.PUT("/:id", h.Update)
+			subs.PATCH("/:id", h.Patch)
 			subs.DELETE("/:id", h.Delete)
+			subs.POST("/:id/restore", h.Restore)
+			subs.POST("/:id/activate", h.Activate)
+			subs.POST("/:id/pause", h.Pause)
+			subs.POST("/:id/resume", h.Resume)
+			subs.POST("/:id/cancel", h.Cancel)
+			subs.GET("/:id/history", h.History)
+			subs.GET("/:id/charges", h.Charges)
+			subs.GET("/:id/prices", h.GetPrices)
+			subs.POST("/:id/prices", h.SchedulePrice)
+			subs.GET("/trash", h.Trash)
+			subs.DELETE("/trash", h.PurgeTrash)
+			subs.GET("/trials/ending", h.EndingTrials)
 			subs.GET("/cost", h.CalculateCost)
+			subs.GET("/cost/breakdown", h.CalculateCostBreakdown)
+			subs.POST("/import", h.Import)
+			subs.GET("/import/:job_id", h.ImportJob)
+		}
+		// Методы коллекции в стиле AIP-136: POST /subscriptions:batch
+		api.POST("/subscriptions:method", h.collectionMethod)
+
+		users := api.Group("/users")
+		{
+			users.GET("/:user_id/upcoming", h.Upcoming)
+		}
+
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.POST("", h.CreateWebhook)
+			webhooks.GET("", h.GetWebhooks)
+			webhooks.GET("/:id", h.GetWebhook)
+			webhooks.PUT("/:id", h.UpdateWebhook)
+			webhooks.DELETE("/:id", h.DeleteWebhook)
+			webhooks.GET("/deliveries", h.GetDeliveries)
+			webhooks.POST("/deliveries/:delivery_id/redeliver", h.Redeliver)
 		}
 	}
 }
@@ -56,17 +103,17 @@ func (h *Handler) Create(ctx *gin.Context) {
 	var request dto.CreateSubscriptionRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		h.customLogger.
-			Error().
+			Warn().
 			Err(err).
 			Msg("Create subscription: invalid request")
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	created, err := h.service.Create(ctx, request)
 	if err != nil {
 		h.customLogger.Error().Err(err).Msg("Create subscription: service error")
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create subscription"})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -81,33 +128,34 @@ func (h *Handler) Create(ctx *gin.Context) {
 func (h *Handler) GetById(ctx *gin.Context) {
 	h.customLogger.Debug().Msg("Get subscription by id: started")
 
-	idStr := ctx.Param("id")
-	if idStr == "" {
+	id, err := parseID(ctx)
+	if err != nil {
 		h.customLogger.
-			Warn().
-			Msg("Get subscription by id: empty id parameter")
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Get subscription by id: invalid id")
+		_ = ctx.Error(err)
 		return
 	}
 
-	id, err := uuid.Parse(idStr)
+	includeDeleted, err := queryBool(ctx, "include_deleted", false)
 	if err != nil {
 		h.customLogger.
 			Warn().Err(err).
-			Str("id", idStr).
-			Msg("Get subscription by id: invalid id format")
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id format"})
+			Str("id", id.String()).
+			Msg("Get subscription by id: invalid include_deleted")
+		_ = ctx.Error(err)
 		return
 	}
 
-	sub, err := h.service.GetById(ctx, id)
+	sub, err := h.service.GetById(ctx, id, includeDeleted)
 	if err != nil {
 		h.customLogger.
 			Error().
 			Err(err).
 			Str("id", id.String()).
 			Msg("Get subscription by id: service error")
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -116,49 +164,104 @@ func (h *Handler) GetById(ctx *gin.Context) {
 		Str("id", id.String()).
 		Msg("Get subscription by id: success")
 
+	setETag(ctx, sub)
 	ctx.JSON(http.StatusOK, sub)
 }
 
 func (h *Handler) GetAll(ctx *gin.Context) {
+	h.list(ctx, "Get all subscriptions", false)
+}
+
+// Trash — список удалённых подписок (корзина) с теми же фильтрами и пагинацией.
+func (h *Handler) Trash(ctx *gin.Context) {
+	h.list(ctx, "Get trash", true)
+}
+
+func (h *Handler) list(ctx *gin.Context, op string, onlyDeleted bool) {
 	h.customLogger.
 		Debug().
-		Msg("Get all subscriptions: started")
+		Msg(op + ": started")
 
-	page, err := strconv.ParseInt(ctx.DefaultQuery("page", "1"), 10, 64)
-	if err != nil || page < 1 {
+	page, pageSize := h.pageParams(ctx, op)
+
+	var request dto.GetAllQueryRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
 		h.customLogger.
-			Warn().
-			Int64("providedPage", page).
-			Msg("Get all subscriptions: invalid page, using default")
+			Warn().Err(err).
+			Msg(op + ": invalid query parameters")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	request.OnlyDeleted = onlyDeleted
 
-		page = 1
+	// Наличие параметра cursor (даже пустого) включает пагинацию по курсору
+	pageRequest := dto.PageRequest{Page: page, PageSize: pageSize}
+	if cursor, ok := ctx.GetQuery("cursor"); ok {
+		pageRequest.Cursor = &cursor
 	}
 
-	pageSize, err := strconv.ParseInt(ctx.DefaultQuery("page_size", "20"), 10, 64)
-	if err != nil || pageSize < 1 || pageSize > 100 {
+	// Общее количество по умолчанию считается только в режиме страниц
+	withTotal, err := queryBool(ctx, "with_total", pageRequest.Cursor == nil)
+	if err != nil {
 		h.customLogger.
-			Warn().
-			Int64("providedPageSize", pageSize).
-			Msg("Get all subscriptions: invalid page size, using default")
-
-		pageSize = 20
+			Warn().Err(err).
+			Msg(op + ": invalid with_total")
+		_ = ctx.Error(err)
+		return
 	}
+	pageRequest.WithTotal = withTotal
 
 	h.customLogger.
 		Debug().Int64("page", page).
 		Int64("pageSize", pageSize).
-		Msg("Get all subscriptions: fetching")
+		Interface("filters", request).
+		Msg(op + ": fetching")
 
-	res, err := h.service.GetAll(ctx, page, pageSize)
+	res, err := h.service.GetAll(ctx, request, pageRequest)
 	if err != nil {
 		h.customLogger.
 			Error().
 			Err(err).
 			Int64("page", page).
 			Int64("pageSize", pageSize).
-			Msg("Get all subscriptions: service error")
+			Msg(op + ": service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Int64("page", page).
+		Int64("pageSize", pageSize).
+		Msg(op + ": success")
+	ctx.JSON(http.StatusOK, res)
+}
 
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get all subscriptions"})
+// EndingTrials — пробные подписки, которые скоро станут платными, для предупреждения пользователей.
+func (h *Handler) EndingTrials(ctx *gin.Context) {
+	op := "Get ending trials"
+	h.customLogger.
+		Debug().
+		Msg(op + ": started")
+
+	page, pageSize := h.pageParams(ctx, op)
+
+	var request dto.EndingTrialsQueryRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Msg(op + ": invalid query parameters")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	res, err := h.service.EndingTrials(ctx, request, dto.PageRequest{Page: page, PageSize: pageSize})
+	if err != nil {
+		h.customLogger.
+			Error().
+			Err(err).
+			Msg(op + ": service error")
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -166,33 +269,101 @@ func (h *Handler) GetAll(ctx *gin.Context) {
 		Info().
 		Int64("page", page).
 		Int64("pageSize", pageSize).
-		Msg("Get all subscriptions: success")
+		Msg(op + ": success")
 	ctx.JSON(http.StatusOK, res)
 }
 
-func (h *Handler) Update(ctx *gin.Context) {
+// Upcoming — предстоящие продления, окончания пробных периодов и подписок пользователя
+// с временем отправки напоминаний.
+func (h *Handler) Upcoming(ctx *gin.Context) {
 	h.customLogger.
 		Debug().
-		Msg("Update subscription: started")
+		Msg("Get upcoming events: started")
 
-	idS := ctx.Param("id")
-	if idS == "" {
+	userID, err := parseUUIDParam(ctx, "user_id")
+	if err != nil {
 		h.customLogger.
-			Warn().
-			Msg("Update subscription: empty id parameter")
+			Warn().Err(err).
+			Str("userId", ctx.Param("user_id")).
+			Msg("Get upcoming events: invalid user id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	var request dto.UpcomingQueryRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Msg("Get upcoming events: invalid query parameters")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
 
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+	res, err := h.reminders.Upcoming(ctx, userID, request)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("userId", userID.String()).
+			Msg("Get upcoming events: service error")
+		_ = ctx.Error(err)
 		return
 	}
 
-	id, err := uuid.Parse(idS)
+	h.customLogger.
+		Info().
+		Str("userId", userID.String()).
+		Int("events", len(res.Data)).
+		Msg("Get upcoming events: success")
+	ctx.JSON(http.StatusOK, res)
+}
+
+// pageParams разбирает page и page_size; некорректные значения заменяются значениями по умолчанию.
+func (h *Handler) pageParams(ctx *gin.Context, op string) (int64, int64) {
+	page, err := strconv.ParseInt(ctx.DefaultQuery("page", "1"), 10, 64)
+	if err != nil || page < 1 {
+		h.customLogger.
+			Warn().
+			Int64("providedPage", page).
+			Msg(op + ": invalid page, using default")
+
+		page = 1
+	}
+
+	pageSize, err := strconv.ParseInt(ctx.DefaultQuery("page_size", "20"), 10, 64)
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		h.customLogger.
+			Warn().
+			Int64("providedPageSize", pageSize).
+			Msg(op + ": invalid page size, using default")
+
+		pageSize = 20
+	}
+
+	return page, pageSize
+}
+
+func (h *Handler) Update(ctx *gin.Context) {
+	h.customLogger.
+		Debug().
+		Msg("Update subscription: started")
+
+	id, err := parseID(ctx)
 	if err != nil {
 		h.customLogger.
 			Warn().Err(err).
-			Str("id", idS).
-			Msg("Update subscription: invalid id format")
+			Str("id", ctx.Param("id")).
+			Msg("Update subscription: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
 
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id format"})
+	ifMatch, err := h.ifMatch(ctx, id)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", id.String()).
+			Msg("Update subscription: invalid If-Match")
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -202,21 +373,16 @@ func (h *Handler) Update(ctx *gin.Context) {
 			Warn().
 			Err(err).
 			Str("id", id.String()).Msg("Update subscription: invalid JSON")
-
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid request body",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
-	updated, err := h.service.Update(ctx, id, request)
+	updated, err := h.service.Update(ctx, id, request, ifMatch)
 	if err != nil {
 		h.customLogger.Error().
 			Err(err).Str("id", id.String()).
 			Msg("Update subscription: service error")
-
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update subscription"})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -224,44 +390,112 @@ func (h *Handler) Update(ctx *gin.Context) {
 		Info().
 		Str("id", id.String()).
 		Msg("Update subscription: success")
+	setETag(ctx, updated)
 	ctx.JSON(http.StatusOK, updated)
 }
 
-func (h *Handler) Delete(ctx *gin.Context) {
+func (h *Handler) Patch(ctx *gin.Context) {
 	h.customLogger.
 		Debug().
-		Msg("Delete subscription: started")
+		Msg("Patch subscription: started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Patch subscription: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
 
-	idStr := ctx.Param("id")
-	if idStr == "" {
+	var request dto.PatchSubscriptionRequest
+	switch ctx.ContentType() {
+	case mimeMergePatch:
+		request.Format = dto.PatchMerge
+	case mimeJSONPatch:
+		request.Format = dto.PatchJSON
+	default:
 		h.customLogger.
 			Warn().
-			Msg("Delete subscription: empty id parameter")
+			Str("id", id.String()).
+			Str("contentType", ctx.ContentType()).
+			Msg("Patch subscription: unsupported content type")
+		_ = ctx.Error(errUnsupportedMediaType)
+		return
+	}
+
+	ifMatch, err := h.ifMatch(ctx, id)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", id.String()).
+			Msg("Patch subscription: invalid If-Match")
+		_ = ctx.Error(err)
+		return
+	}
+
+	request.Patch, err = ctx.GetRawData()
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", id.String()).
+			Msg("Patch subscription: failed to read body")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
 
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+	patched, err := h.service.Patch(ctx, id, request, ifMatch)
+	if err != nil {
+		h.customLogger.Error().
+			Err(err).Str("id", id.String()).
+			Msg("Patch subscription: service error")
+		_ = ctx.Error(err)
 		return
 	}
 
-	id, err := uuid.Parse(idStr)
+	h.customLogger.
+		Info().
+		Str("id", id.String()).
+		Str("format", string(request.Format)).
+		Msg("Patch subscription: success")
+	setETag(ctx, patched)
+	ctx.JSON(http.StatusOK, patched)
+}
+
+func (h *Handler) Delete(ctx *gin.Context) {
+	h.customLogger.
+		Debug().
+		Msg("Delete subscription: started")
+
+	id, err := parseID(ctx)
 	if err != nil {
 		h.customLogger.
 			Warn().
 			Err(err).
-			Str("id", idStr).
-			Msg("Delete subscription: invalid id format")
+			Str("id", ctx.Param("id")).
+			Msg("Delete subscription: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
 
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id format"})
+	ifMatch, err := h.ifMatch(ctx, id)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", id.String()).
+			Msg("Delete subscription: invalid If-Match")
+		_ = ctx.Error(err)
 		return
 	}
 
-	err = h.service.Delete(ctx, id)
+	err = h.service.Delete(ctx, id, ifMatch)
 	if err != nil {
 		h.customLogger.
 			Error().Err(err).
 			Str("id", id.String()).
 			Msg("Delete subscription: service error")
-
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -273,6 +507,270 @@ func (h *Handler) Delete(ctx *gin.Context) {
 	ctx.JSON(http.StatusNoContent, nil)
 }
 
+func (h *Handler) Restore(ctx *gin.Context) {
+	h.customLogger.
+		Debug().
+		Msg("Restore subscription: started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Restore subscription: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	restored, err := h.service.Restore(ctx, id)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("id", id.String()).
+			Msg("Restore subscription: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Str("id", id.String()).
+		Msg("Restore subscription: success")
+	setETag(ctx, restored)
+	ctx.JSON(http.StatusOK, restored)
+}
+
+// Activate переводит пробную подписку в active.
+func (h *Handler) Activate(ctx *gin.Context) {
+	h.changeState(ctx, "Activate subscription", models.OperationActivate)
+}
+
+func (h *Handler) Pause(ctx *gin.Context) {
+	h.changeState(ctx, "Pause subscription", models.OperationPause)
+}
+
+func (h *Handler) Resume(ctx *gin.Context) {
+	h.changeState(ctx, "Resume subscription", models.OperationResume)
+}
+
+func (h *Handler) Cancel(ctx *gin.Context) {
+	h.changeState(ctx, "Cancel subscription", models.OperationCancel)
+}
+
+// changeState — общий обработчик действий над подпиской.
+// Недопустимый из текущего состояния переход возвращает 409.
+func (h *Handler) changeState(ctx *gin.Context, op string, action models.Operation) {
+	h.customLogger.
+		Debug().
+		Msg(op + ": started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg(op + ": invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	ifMatch, err := h.ifMatch(ctx, id)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", id.String()).
+			Msg(op + ": invalid If-Match")
+		_ = ctx.Error(err)
+		return
+	}
+
+	result, err := h.service.ChangeState(ctx, id, action, ifMatch)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("id", id.String()).
+			Msg(op + ": service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Str("id", id.String()).
+		Str("state", string(result.State)).
+		Msg(op + ": success")
+	setETag(ctx, result)
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) PurgeTrash(ctx *gin.Context) {
+	h.customLogger.
+		Debug().
+		Msg("Purge trash: started")
+
+	result, err := h.service.PurgeTrash(ctx)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Msg("Purge trash: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Int64("purged", result.Purged).
+		Msg("Purge trash: success")
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) History(ctx *gin.Context) {
+	h.customLogger.
+		Debug().
+		Msg("Get subscription history: started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Get subscription history: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	page, pageSize := h.pageParams(ctx, "Get subscription history")
+
+	history, err := h.service.GetHistory(ctx, id, dto.PageRequest{Page: page, PageSize: pageSize})
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("id", id.String()).
+			Msg("Get subscription history: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Str("id", id.String()).
+		Int("events", len(history.Data)).
+		Msg("Get subscription history: success")
+	ctx.JSON(http.StatusOK, history)
+}
+
+// Charges — списания подписки по периодам оплаты, от новых к старым.
+func (h *Handler) Charges(ctx *gin.Context) {
+	h.customLogger.
+		Debug().
+		Msg("Get subscription charges: started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Get subscription charges: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	page, pageSize := h.pageParams(ctx, "Get subscription charges")
+
+	charges, err := h.service.GetCharges(ctx, id, dto.PageRequest{Page: page, PageSize: pageSize})
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("id", id.String()).
+			Msg("Get subscription charges: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Str("id", id.String()).
+		Int("charges", len(charges.Data)).
+		Msg("Get subscription charges: success")
+	ctx.JSON(http.StatusOK, charges)
+}
+
+func (h *Handler) SchedulePrice(ctx *gin.Context) {
+	h.customLogger.
+		Debug().
+		Msg("Schedule price: started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Schedule price: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	var request dto.SchedulePriceRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", id.String()).
+			Msg("Schedule price: invalid request")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	change, err := h.service.SchedulePrice(ctx, id, request)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("id", id.String()).
+			Msg("Schedule price: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Str("id", id.String()).
+		Int64("price", change.Price).
+		Msg("Schedule price: success")
+	ctx.JSON(http.StatusCreated, change)
+}
+
+func (h *Handler) GetPrices(ctx *gin.Context) {
+	h.customLogger.
+		Debug().
+		Msg("Get prices: started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Get prices: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	prices, err := h.service.GetPrices(ctx, id)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("id", id.String()).
+			Msg("Get prices: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Str("id", id.String()).
+		Int("prices", len(prices)).
+		Msg("Get prices: success")
+	ctx.JSON(http.StatusOK, dto.PricesResponse{Data: prices})
+}
+
 func (h *Handler) CalculateCost(ctx *gin.Context) {
 	h.customLogger.
 		Debug().
@@ -280,27 +778,19 @@ func (h *Handler) CalculateCost(ctx *gin.Context) {
 
 	var request dto.CostCalculationQueryRequest
 	if err := ctx.ShouldBindQuery(&request); err != nil {
-
 		h.customLogger.
 			Warn().Err(err).
 			Msg("Calculate cost: invalid query parameters")
-
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid query parameters",
-			"details": err.Error(),
-		})
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
-	if !request.From.IsZero() && !request.To.IsZero() && request.From.After(request.To) {
+	if err := validateDateRange(request.SubFilterQuery); err != nil {
 		h.customLogger.
 			Warn().Time("from", request.From).
 			Time("to", request.To).
 			Msg("Calculate cost: invalid date range")
-
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid date range: 'from' cannot be after 'to'",
-		})
+		_ = ctx.Error(err)
 		return
 	}
 
@@ -309,27 +799,97 @@ func (h *Handler) CalculateCost(ctx *gin.Context) {
 		Interface("filters", request).
 		Msg("Calculate cost: processing")
 
-	totalCost, err := h.service.CalculateTotalCost(ctx, request)
+	summary, err := h.service.CalculateTotalCost(ctx, request)
 	if err != nil {
 		h.customLogger.
 			Error().
 			Err(err).
 			Msg("Calculate cost: service error")
-
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to calculate total cost",
-		})
+		_ = ctx.Error(err)
 		return
 	}
 
 	h.customLogger.
 		Info().
-		Int64("totalCost", totalCost).
+		Int64("totalCost", summary.TotalCost).
+		Int64("billableMonths", summary.BillableMonths).
 		Msg("Calculate cost: success")
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"total_cost": totalCost,
-		"currency":   "RUB",
-		"filters":    request,
-	})
+	response := gin.H{
+		"total_cost":      summary.TotalCost,
+		"billable_months": summary.BillableMonths,
+		"currency":        summary.Currency,
+		"filters":         request,
+	}
+	if len(summary.Rates) > 0 {
+		response["rates"] = summary.Rates
+	}
+	if summary.NormalizedCost != nil {
+		response["period"] = summary.Period
+		response["normalized_cost"] = *summary.NormalizedCost
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) CalculateCostBreakdown(ctx *gin.Context) {
+	h.customLogger.
+		Debug().
+		Msg("Calculate cost breakdown: started")
+
+	var request dto.CostBreakdownQueryRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Msg("Calculate cost breakdown: invalid query parameters")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := validateDateRange(request.SubFilterQuery); err != nil {
+		h.customLogger.
+			Warn().Time("from", request.From).
+			Time("to", request.To).
+			Msg("Calculate cost breakdown: invalid date range")
+		_ = ctx.Error(err)
+		return
+	}
+
+	breakdown, err := h.service.CalculateCostBreakdown(ctx, request)
+	if err != nil {
+		h.customLogger.
+			Error().
+			Err(err).
+			Msg("Calculate cost breakdown: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Str("groupBy", request.GroupBy).
+		Int("groups", len(breakdown.Groups)).
+		Msg("Calculate cost breakdown: success")
+
+	ctx.JSON(http.StatusOK, breakdown)
+}
+
+// queryBool разбирает необязательный булев параметр запроса.
+func queryBool(ctx *gin.Context, name string, defaultValue bool) (bool, error) {
+	value, ok := ctx.GetQuery(name)
+	if !ok {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid %s value", models.ErrInvalidArgument, name)
+	}
+	return parsed, nil
+}
+
+func validateDateRange(filter dto.SubFilterQuery) error {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return fmt.Errorf("%w: invalid date range: 'from' cannot be after 'to'", models.ErrInvalidArgument)
+	}
+	return nil
 }
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"SubscriptionService/internal/application/events"
+	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/notifier"
+	"SubscriptionService/internal/persistence/memory"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// newTestServer возвращает маршрутизатор API над хранилищем в памяти.
+func newTestServer(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := zerolog.Nop()
+	repo := memory.NewSubRepository()
+	subs := services.NewSubService(repo, memory.NewTxManager(repo), memory.ExchangeRates{}, events.NewSyncDispatcher(&logger), 0, &logger)
+	reminders := services.NewReminderService(memory.ReminderRepository{}, notifier.NewLogNotifier(&logger), time.Hour, &logger)
+	webhooks := services.NewWebhookService(memory.WebhookRepository{}, notifier.NewWebhookSender(time.Second), &logger)
+	imports := services.NewImportService(subs, nil, 1<<20, 1<<20, 100, time.Hour, &logger)
+
+	app := gin.New()
+	NewHandler(app, subs, reminders, webhooks, imports, &logger)
+	return app
+}
+
+// do выполняет запрос с телом body в JSON; header — пары имя, значение.
+func do(t *testing.T, app *gin.Engine, method, path string, body any, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatalf("marshal request: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode разбирает тело ответа в JSON.
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var value T
+	if err := json.Unmarshal(rec.Body.Bytes(), &value); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return value
+}
+
+// subscriptionBody — тело запроса на создание или замену месячной подписки.
+func subscriptionBody(name string, price int64) map[string]any {
+	return map[string]any{
+		"service_name": name,
+		"price":        price,
+		"user_id":      uuid.New(),
+		"start_date":   "2024-01-01T00:00:00Z",
+	}
+}
+
+// createSub создаёт подписку через API.
+func createSub(t *testing.T, app *gin.Engine, name string, price int64) *models.Subscription {
+	t.Helper()
+	rec := do(t, app, http.MethodPost, "/api/v1/subscriptions", subscriptionBody(name, price))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create subscription: status %d, body %s", rec.Code, rec.Body)
+	}
+	return decode[*models.Subscription](t, rec)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Import импортирует подписки из CSV — поля file формы multipart/form-data.
+// Параметры импорта передаются в строке запроса. Небольшие файлы импортируются
+// в запросе (200 с отчётом), остальные — в фоне: 202 с отчётом в состоянии running,
+// а Location указывает, где следить за ходом импорта.
+func (h *Handler) Import(ctx *gin.Context) {
+	h.customLogger.Debug().Msg("Import subscriptions: started")
+
+	var request dto.ImportRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Msg("Import subscriptions: invalid query parameters")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	file, err := filePart(ctx, "file")
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Msg("Import subscriptions: invalid form")
+		_ = ctx.Error(err)
+		return
+	}
+	defer file.Close()
+
+	job, err := h.imports.Start(ctx, file, request)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Msg("Import subscriptions: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Str("jobId", job.Id.String()).
+		Str("status", job.Status).
+		Msg("Import subscriptions: success")
+
+	if job.Status == dto.ImportRunning {
+		ctx.Header("Location", "/api/v1/subscriptions/import/"+job.Id.String())
+		ctx.JSON(http.StatusAccepted, job)
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+// filePart возвращает поле name формы multipart/form-data, не загружая форму в память.
+func filePart(ctx *gin.Context, name string) (*multipart.Part, error) {
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: expected multipart/form-data: %v", models.ErrInvalidArgument, err)
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: form field %s is required", models.ErrInvalidArgument, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid multipart form: %v", models.ErrInvalidArgument, err)
+		}
+		if part.FormName() == name {
+			return part, nil
+		}
+		_ = part.Close()
+	}
+}
+
+// ImportJob возвращает ход и отчёт импорта.
+func (h *Handler) ImportJob(ctx *gin.Context) {
+	h.customLogger.Debug().Msg("Get import job: started")
+
+	id, err := parseUUIDParam(ctx, "job_id")
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("jobId", ctx.Param("job_id")).
+			Msg("Get import job: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	job, err := h.imports.GetJob(ctx, id)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("jobId", id.String()).
+			Msg("Get import job: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.
+		Info().
+		Str("jobId", id.String()).
+		Str("status", job.Status).
+		Msg("Get import job: success")
+	ctx.JSON(http.StatusOK, job)
+}
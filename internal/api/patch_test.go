@@ -0,0 +1,52 @@
+package api
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPatchValidatesFields(t *testing.T) {
+	app := newTestServer(t)
+	sub := createSub(t, app, "Netflix", 400)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		field       string
+		code        string
+	}{
+		{"merge patch too short", mimeMergePatch, `{"service_name":"x"}`, "service_name", "min"},
+		{"merge patch zero price", mimeMergePatch, `{"price":0}`, "price", "required"},
+		{"json patch too long", mimeJSONPatch,
+			`[{"op":"replace","path":"/service_name","value":"` + strings.Repeat("x", 101) + `"}]`, "service_name", "max"},
+		{"json patch bad currency", mimeJSONPatch, `[{"op":"replace","path":"/currency","value":"XXXX"}]`, "currency", "iso4217"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/subscriptions/"+sub.Id.String(), bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status %d, want 422, body %s", rec.Code, rec.Body)
+			}
+			problem := decode[dto.Problem](t, rec)
+			if len(problem.Errors) != 1 || problem.Errors[0].Field != tt.field || problem.Errors[0].Code != tt.code {
+				t.Errorf("errors = %+v, want %s %s", problem.Errors, tt.field, tt.code)
+			}
+		})
+	}
+
+	// Подписка не изменилась
+	rec := do(t, app, http.MethodGet, "/api/v1/subscriptions/"+sub.Id.String(), nil)
+	if got := decode[*models.Subscription](t, rec); got.ServiceName != sub.ServiceName || got.Version != sub.Version {
+		t.Errorf("subscription changed: %+v", got)
+	}
+}
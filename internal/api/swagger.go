@@ -23,7 +23,7 @@ const openAPISpecJSON = `{
   "info": {
     "title": "Subscription Service API",
     "version": "1.0.0",
-    "description": "API для управления подписками и расчета их стоимости."
+    "description": "API для управления подписками и расчета их стоимости. Ошибки возвращаются единообразно: 400 — некорректный запрос, 404 — запись не найдена, 409 — конфликт, 422 — ошибка валидации, 503 — хранилище недоступно."
   },
   "paths": {
     "/health": {
@@ -52,6 +52,14 @@ const openAPISpecJSON = `{
     "/api/v1/subscriptions": {
       "post": {
         "summary": "Create subscription",
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
         "requestBody": {
           "required": true,
           "content": {
@@ -74,10 +82,19 @@ const openAPISpecJSON = `{
             }
           },
           "400": {
-            "description": "Invalid request"
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
           },
           "500": {
-            "description": "Internal error"
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
           }
         }
       },
@@ -102,6 +119,60 @@ const openAPISpecJSON = `{
               "default": 20,
               "maximum": 100
             }
+          },
+          {
+            "$ref": "#/components/parameters/UserID"
+          },
+          {
+            "$ref": "#/components/parameters/ServiceName"
+          },
+          {
+            "$ref": "#/components/parameters/ServiceNamePrefix"
+          },
+          {
+            "$ref": "#/components/parameters/Status"
+          },
+          {
+            "$ref": "#/components/parameters/State"
+          },
+          {
+            "$ref": "#/components/parameters/MinPrice"
+          },
+          {
+            "$ref": "#/components/parameters/MaxPrice"
+          },
+          {
+            "$ref": "#/components/parameters/From"
+          },
+          {
+            "$ref": "#/components/parameters/To"
+          },
+          {
+            "$ref": "#/components/parameters/IncludeDeleted"
+          },
+          {
+            "name": "cursor",
+            "in": "query",
+            "description": "Включает пагинацию по курсору (created_at, id); пустое значение — первая страница. Значения берутся из next_cursor/prev_cursor. Не сочетается с sort",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "with_total",
+            "in": "query",
+            "description": "Считать общее количество записей. По умолчанию true в режиме страниц и false в режиме курсора",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "sort",
+            "in": "query",
+            "description": "Поля сортировки через запятую, минус — по убыванию: price,-start_date. Допустимы service_name, price, start_date, end_date, trial_end, created_at, updated_at, deleted_at",
+            "schema": {
+              "type": "string"
+            }
           }
         ],
         "responses": {
@@ -115,92 +186,191 @@ const openAPISpecJSON = `{
               }
             }
           },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
           "500": {
-            "description": "Internal error"
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
           }
         }
       }
     },
-    "/api/v1/subscriptions/{id}": {
-      "get": {
-        "summary": "Get subscription by ID",
+    "/api/v1/subscriptions:batch": {
+      "post": {
+        "summary": "Batch create, update and delete subscriptions",
+        "description": "Операции выполняются по порядку, подряд идущие создания сохраняются одной вставкой. С atomic=true все операции выполняются в одной транзакции: ошибка любой из них отменяет пакет и возвращается как ошибка запроса (detail начинается с номера операции). Без него операции независимы, статус и ошибка каждой возвращаются в results",
         "parameters": [
           {
-            "name": "id",
-            "in": "path",
-            "required": true,
+            "name": "atomic",
+            "in": "query",
+            "description": "Выполнить все операции в одной транзакции",
             "schema": {
-              "type": "string",
-              "format": "uuid"
+              "type": "boolean",
+              "default": false
             }
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
           }
         ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/BatchRequest"
+              }
+            }
+          }
+        },
         "responses": {
           "200": {
-            "description": "OK",
+            "description": "Результаты операций в порядке запроса",
             "content": {
               "application/json": {
                 "schema": {
-                  "$ref": "#/components/schemas/Subscription"
+                  "$ref": "#/components/schemas/BatchResponse"
                 }
               }
             }
           },
           "400": {
-            "description": "Bad request"
+            "$ref": "#/components/responses/BadRequest"
           },
           "404": {
-            "description": "Not found"
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "412": {
+            "$ref": "#/components/responses/PreconditionFailed"
+          },
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
           }
         }
-      },
-      "put": {
-        "summary": "Update subscription",
+      }
+    },
+    "/api/v1/subscriptions/import": {
+      "post": {
+        "summary": "Import subscriptions from CSV",
+        "description": "Первая строка файла — заголовок: столбцы сопоставляются полям подписки по именам полей или по соответствию mapping (и IMPORT_MAPPING). Каждая строка проверяется как при создании подписки; ошибки строк не прерывают импорт и возвращаются в отчёте. Файлы до IMPORT_SYNC_LIMIT импортируются в запросе (200), остальные — в фоне (202, ход импорта — по адресу из Location)",
         "parameters": [
           {
-            "name": "id",
-            "in": "path",
-            "required": true,
+            "name": "dry_run",
+            "in": "query",
+            "description": "Только проверить строки, не создавая подписок",
+            "schema": {
+              "type": "boolean",
+              "default": false
+            }
+          },
+          {
+            "name": "delimiter",
+            "in": "query",
+            "description": "Разделитель полей",
             "schema": {
               "type": "string",
-              "format": "uuid"
+              "default": ",",
+              "example": ";"
+            }
+          },
+          {
+            "name": "mapping",
+            "in": "query",
+            "description": "Соответствие заголовков полям; дополняет IMPORT_MAPPING",
+            "schema": {
+              "type": "string",
+              "example": "Сервис=service_name,Стоимость=price"
             }
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
           }
         ],
         "requestBody": {
           "required": true,
           "content": {
-            "application/json": {
+            "multipart/form-data": {
               "schema": {
-                "$ref": "#/components/schemas/UpdateSubscriptionRequest"
+                "type": "object",
+                "required": [
+                  "file"
+                ],
+                "properties": {
+                  "file": {
+                    "type": "string",
+                    "format": "binary",
+                    "description": "CSV в UTF-8; обязательные столбцы — service_name, price, user_id, start_date"
+                  }
+                }
               }
             }
           }
         },
         "responses": {
           "200": {
-            "description": "OK",
+            "description": "Импорт выполнен",
             "content": {
               "application/json": {
                 "schema": {
-                  "$ref": "#/components/schemas/Subscription"
+                  "$ref": "#/components/schemas/ImportJob"
+                }
+              }
+            }
+          },
+          "202": {
+            "description": "Импорт выполняется в фоне",
+            "headers": {
+              "Location": {
+                "description": "Адрес хода и отчёта импорта",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ImportJob"
                 }
               }
             }
           },
           "400": {
-            "description": "Bad request"
+            "$ref": "#/components/responses/BadRequest"
           },
-          "404": {
-            "description": "Not found"
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
           }
         }
-      },
-      "delete": {
-        "summary": "Delete subscription",
+      }
+    },
+    "/api/v1/subscriptions/import/{job_id}": {
+      "get": {
+        "summary": "Get import progress and report",
         "parameters": [
           {
-            "name": "id",
+            "name": "job_id",
             "in": "path",
             "required": true,
             "schema": {
@@ -210,217 +380,2647 @@ const openAPISpecJSON = `{
           }
         ],
         "responses": {
-          "204": {
-            "description": "No Content"
+          "200": {
+            "description": "Ход и отчёт импорта",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ImportJob"
+                }
+              }
+            }
           },
           "400": {
-            "description": "Bad request"
+            "$ref": "#/components/responses/BadRequest"
           },
           "404": {
-            "description": "Not found"
+            "$ref": "#/components/responses/NotFound"
           }
         }
       }
     },
-    "/api/v1/subscriptions/cost": {
+    "/api/v1/subscriptions/{id}": {
       "get": {
-        "summary": "Calculate total cost of subscriptions",
+        "summary": "Get subscription by ID",
         "parameters": [
           {
-            "name": "user_id",
-            "in": "query",
+            "name": "id",
+            "in": "path",
+            "required": true,
             "schema": {
               "type": "string",
               "format": "uuid"
             }
           },
           {
-            "name": "service_name",
-            "in": "query",
-            "schema": {
-              "type": "string"
+            "$ref": "#/components/parameters/IncludeDeleted"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {
+              "ETag": {
+                "$ref": "#/components/headers/ETag"
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Subscription"
+                }
+              }
             }
           },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          }
+        }
+      },
+      "put": {
+        "summary": "Replace subscription",
+        "parameters": [
           {
-            "name": "from",
-            "in": "query",
+            "name": "id",
+            "in": "path",
+            "required": true,
             "schema": {
               "type": "string",
-              "format": "date-time"
+              "format": "uuid"
             }
           },
           {
-            "name": "to",
-            "in": "query",
-            "schema": {
-              "type": "string",
-              "format": "date-time"
-            }
+            "$ref": "#/components/parameters/IfMatch"
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
           }
         ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/UpdateSubscriptionRequest"
+              }
+            }
+          }
+        },
         "responses": {
           "200": {
             "description": "OK",
+            "headers": {
+              "ETag": {
+                "$ref": "#/components/headers/ETag"
+              }
+            },
             "content": {
               "application/json": {
                 "schema": {
-                  "type": "object",
-                  "properties": {
-                    "total_cost": {
-                      "type": "integer",
-                      "format": "int64"
-                    },
-                    "currency": {
-                      "type": "string",
-                      "example": "RUB"
-                    }
-                  }
+                  "$ref": "#/components/schemas/Subscription"
                 }
               }
             }
           },
           "400": {
-            "description": "Bad request"
+            "$ref": "#/components/responses/BadRequest"
           },
-          "500": {
-            "description": "Internal error"
-          }
-        }
-      }
-    }
-  },
-  "components": {
-    "schemas": {
-      "Subscription": {
-        "type": "object",
-        "properties": {
-          "id": {
-            "type": "string",
-            "format": "uuid"
+          "404": {
+            "$ref": "#/components/responses/NotFound"
           },
-          "service_name": {
-            "type": "string"
+          "412": {
+            "$ref": "#/components/responses/PreconditionFailed"
           },
-          "price": {
-            "type": "integer",
-            "format": "int64"
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
+          }
+        }
+      },
+      "patch": {
+        "summary": "Patch subscription",
+        "description": "Частичное изменение: application/merge-patch+json (RFC 7396) или application/json-patch+json (RFC 6902)",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
           },
-          "user_id": {
-            "type": "string",
-            "format": "uuid"
+          {
+            "$ref": "#/components/parameters/IfMatch"
           },
-          "start_date": {
-            "type": "string",
-            "format": "date-time"
+          {
+            "$ref": "#/components/parameters/XActor"
           },
-          "end_date": {
-            "type": "string",
-            "format": "date-time",
-            "nullable": true
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/merge-patch+json": {
+              "schema": {
+                "$ref": "#/components/schemas/SubscriptionMergePatch"
+              }
+            },
+            "application/json-patch+json": {
+              "schema": {
+                "type": "array",
+                "items": {
+                  "$ref": "#/components/schemas/JsonPatchOperation"
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {
+              "ETag": {
+                "$ref": "#/components/headers/ETag"
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Subscription"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "412": {
+            "$ref": "#/components/responses/PreconditionFailed"
+          },
+          "415": {
+            "$ref": "#/components/responses/UnsupportedMediaType"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
+          }
+        }
+      },
+      "delete": {
+        "summary": "Delete subscription (move to trash)",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/IfMatch"
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
+        "responses": {
+          "204": {
+            "description": "No Content"
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "412": {
+            "$ref": "#/components/responses/PreconditionFailed"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/{id}/restore": {
+      "post": {
+        "summary": "Restore subscription from trash",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {
+              "ETag": {
+                "$ref": "#/components/headers/ETag"
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Subscription"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/{id}/activate": {
+      "post": {
+        "summary": "Activate trial subscription",
+        "description": "trial → active; пробный период заканчивается в момент активации. По окончании пробного периода подписка активируется автоматически. Недопустимый из текущего состояния переход — 409.",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/IfMatch"
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {
+              "ETag": {
+                "$ref": "#/components/headers/ETag"
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Subscription"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "412": {
+            "$ref": "#/components/responses/PreconditionFailed"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/{id}/pause": {
+      "post": {
+        "summary": "Pause subscription",
+        "description": "active → paused; время на паузе не оплачивается: из стоимости месяца вычитается его доля, пришедшаяся на паузу. Недопустимый из текущего состояния переход — 409.",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/IfMatch"
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {
+              "ETag": {
+                "$ref": "#/components/headers/ETag"
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Subscription"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "412": {
+            "$ref": "#/components/responses/PreconditionFailed"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/{id}/resume": {
+      "post": {
+        "summary": "Resume paused subscription",
+        "description": "paused → active. Недопустимый из текущего состояния переход — 409.",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/IfMatch"
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {
+              "ETag": {
+                "$ref": "#/components/headers/ETag"
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Subscription"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "412": {
+            "$ref": "#/components/responses/PreconditionFailed"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/{id}/cancel": {
+      "post": {
+        "summary": "Cancel subscription",
+        "description": "active → cancelled; дата окончания — момент отмены. Недопустимый из текущего состояния переход — 409.",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/IfMatch"
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "headers": {
+              "ETag": {
+                "$ref": "#/components/headers/ETag"
+              }
+            },
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Subscription"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          },
+          "412": {
+            "$ref": "#/components/responses/PreconditionFailed"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/{id}/history": {
+      "get": {
+        "summary": "Get subscription change history",
+        "description": "Журнал изменений подписки от новых событий к старым, включая удалённые подписки",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "name": "page",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 1
+            }
+          },
+          {
+            "name": "page_size",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 20,
+              "maximum": 100
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/HistoryResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/{id}/charges": {
+      "get": {
+        "summary": "List subscription charges",
+        "description": "Списания по периодам оплаты от новых к старым. Списания создаёт планировщик продлений для активных подписок: одно за каждый период оплаты по цене на его начало",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "name": "page",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 1
+            }
+          },
+          {
+            "name": "page_size",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 20,
+              "maximum": 100
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ChargesResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/{id}/prices": {
+      "get": {
+        "summary": "Get subscription price history",
+        "description": "История и план цен по возрастанию даты вступления в силу",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/PricesResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          }
+        }
+      },
+      "post": {
+        "summary": "Schedule price change",
+        "description": "Новая цена действует с начала первого оплачиваемого месяца, начинающегося не раньше effective_from; прошлые месяцы считаются по прежней цене. Цена на ту же дату заменяется",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/SchedulePriceRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "Created",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/PriceChange"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/trash": {
+      "get": {
+        "summary": "List deleted subscriptions (trash)",
+        "parameters": [
+          {
+            "name": "page",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 1
+            }
+          },
+          {
+            "name": "page_size",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 20,
+              "maximum": 100
+            }
+          },
+          {
+            "$ref": "#/components/parameters/UserID"
+          },
+          {
+            "$ref": "#/components/parameters/ServiceName"
+          },
+          {
+            "$ref": "#/components/parameters/ServiceNamePrefix"
+          },
+          {
+            "$ref": "#/components/parameters/Status"
+          },
+          {
+            "$ref": "#/components/parameters/State"
+          },
+          {
+            "$ref": "#/components/parameters/MinPrice"
+          },
+          {
+            "$ref": "#/components/parameters/MaxPrice"
+          },
+          {
+            "$ref": "#/components/parameters/From"
+          },
+          {
+            "$ref": "#/components/parameters/To"
+          },
+          {
+            "name": "cursor",
+            "in": "query",
+            "description": "Включает пагинацию по курсору (created_at, id); пустое значение — первая страница. Значения берутся из next_cursor/prev_cursor. Не сочетается с sort",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "with_total",
+            "in": "query",
+            "description": "Считать общее количество записей. По умолчанию true в режиме страниц и false в режиме курсора",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "sort",
+            "in": "query",
+            "description": "Поля сортировки через запятую, минус — по убыванию: price,-start_date. Допустимы service_name, price, start_date, end_date, trial_end, created_at, updated_at, deleted_at",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/GetAllResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
+          }
+        }
+      },
+      "delete": {
+        "summary": "Purge trash",
+        "description": "Окончательно удаляет подписки, находящиеся в корзине дольше TRASH_RETENTION",
+        "parameters": [
+          {
+            "$ref": "#/components/parameters/XActor"
+          },
+          {
+            "$ref": "#/components/parameters/XRequestID"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/PurgeResponse"
+                }
+              }
+            }
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/trials/ending": {
+      "get": {
+        "summary": "List trials ending soon",
+        "description": "Пробные подписки, пробный период которых закончится в ближайшие within_days дней, по возрастанию trial_end — для предупреждения пользователей до начала оплаты",
+        "parameters": [
+          {
+            "name": "page",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 1
+            }
+          },
+          {
+            "name": "page_size",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 20,
+              "maximum": 100
+            }
+          },
+          {
+            "$ref": "#/components/parameters/UserID"
+          },
+          {
+            "name": "within_days",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "default": 3,
+              "minimum": 1,
+              "maximum": 365
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/GetAllResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/cost": {
+      "get": {
+        "summary": "Calculate total cost of subscriptions",
+        "description": "Каждая подписка тарифицируется один раз за каждый месяц, пересекающийся с периодом [from, to]. Бессрочные подписки ограничиваются to.",
+        "parameters": [
+          {
+            "name": "user_id",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "name": "service_name",
+            "in": "query",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/ServiceNamePrefix"
+          },
+          {
+            "$ref": "#/components/parameters/Status"
+          },
+          {
+            "$ref": "#/components/parameters/State"
+          },
+          {
+            "$ref": "#/components/parameters/MinPrice"
+          },
+          {
+            "$ref": "#/components/parameters/MaxPrice"
+          },
+          {
+            "name": "from",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "format": "date-time"
+            }
+          },
+          {
+            "name": "to",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "format": "date-time"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/IncludeDeleted"
+          },
+          {
+            "name": "currency",
+            "in": "query",
+            "description": "Валюта результата (ISO 4217), по умолчанию RUB. Суммы в других валютах пересчитываются по курсу на дату to",
+            "schema": {
+              "type": "string",
+              "example": "USD"
+            }
+          },
+          {
+            "name": "period",
+            "in": "query",
+            "description": "Привести суммарную стоимость подписок к периоду (например, yearly — годовой эквивалент)",
+            "schema": {
+              "type": "string",
+              "enum": ["weekly", "monthly", "quarterly", "yearly"]
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "total_cost": {
+                      "type": "integer",
+                      "format": "int64"
+                    },
+                    "billable_months": {
+                      "type": "integer",
+                      "format": "int64",
+                      "description": "Количество оплачиваемых месяцев подписок в периоде"
+                    },
+                    "currency": {
+                      "type": "string",
+                      "example": "RUB"
+                    },
+                    "rates": {
+                      "type": "array",
+                      "description": "Курсы, использованные для пересчёта",
+                      "items": {
+                        "$ref": "#/components/schemas/ExchangeRate"
+                      }
+                    },
+                    "period": {
+                      "type": "string"
+                    },
+                    "normalized_cost": {
+                      "type": "integer",
+                      "format": "int64",
+                      "description": "Стоимость подписок, приведённая к периоду period"
+                    }
+                  }
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
+          }
+        }
+      }
+    },
+    "/api/v1/subscriptions/cost/breakdown": {
+      "get": {
+        "summary": "Cost breakdown grouped by service, user, month or year",
+        "description": "Принимает те же фильтры, что и /api/v1/subscriptions/cost; суммы групп складываются в итог /cost.",
+        "parameters": [
+          {
+            "name": "group_by",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "enum": ["service_name", "user_id", "month", "year"]
+            }
+          },
+          {
+            "name": "user_id",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "name": "service_name",
+            "in": "query",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/ServiceNamePrefix"
+          },
+          {
+            "$ref": "#/components/parameters/Status"
+          },
+          {
+            "$ref": "#/components/parameters/State"
+          },
+          {
+            "$ref": "#/components/parameters/MinPrice"
+          },
+          {
+            "$ref": "#/components/parameters/MaxPrice"
+          },
+          {
+            "name": "from",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "format": "date-time"
+            }
+          },
+          {
+            "name": "to",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "format": "date-time"
+            }
+          },
+          {
+            "$ref": "#/components/parameters/IncludeDeleted"
+          },
+          {
+            "name": "currency",
+            "in": "query",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "period",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "enum": ["weekly", "monthly", "quarterly", "yearly"]
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/CostBreakdownResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
+          }
+        }
+      }
+    },
+    "/api/v1/users/{user_id}/upcoming": {
+      "get": {
+        "summary": "Preview upcoming events and reminders",
+        "description": "Предстоящие продления, окончания пробных периодов и подписок пользователя в ближайшие within_days дней. Напоминание о каждом событии отправляется один раз за REMINDER_LEAD до него",
+        "parameters": [
+          {
+            "name": "user_id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "name": "within_days",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "default": 30,
+              "minimum": 1,
+              "maximum": 365
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/UpcomingResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          },
+          "503": {
+            "$ref": "#/components/responses/Unavailable"
+          }
+        }
+      }
+    },
+    "/api/v1/webhooks": {
+      "post": {
+        "summary": "Register webhook endpoint",
+        "description": "Получатель получает POST с событием журнала аудита (SubscriptionEvent) в теле. Заголовки: X-Webhook-Id, X-Webhook-Event, X-Webhook-Timestamp и X-Webhook-Signature = \"sha256=\" + hex(HMAC-SHA256(secret, timestamp + \".\" + тело)). Секрет возвращается только в этом ответе",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/WebhookEndpointRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "Created",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/WebhookEndpoint"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      },
+      "get": {
+        "summary": "List webhook endpoints",
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/WebhookEndpointsResponse"
+                }
+              }
+            }
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/api/v1/webhooks/{id}": {
+      "get": {
+        "summary": "Get webhook endpoint",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/WebhookEndpoint"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          }
+        }
+      },
+      "put": {
+        "summary": "Replace webhook endpoint",
+        "description": "Пустой secret оставляет прежний секрет",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/WebhookEndpointRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/WebhookEndpoint"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "422": {
+            "$ref": "#/components/responses/ValidationFailed"
+          }
+        }
+      },
+      "delete": {
+        "summary": "Delete webhook endpoint",
+        "description": "Доставки получателя остаются в списке доставок; ожидающие доставки становятся недоставленными (dead)",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          }
+        ],
+        "responses": {
+          "204": {
+            "description": "No Content"
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          }
+        }
+      }
+    },
+    "/api/v1/webhooks/deliveries": {
+      "get": {
+        "summary": "List webhook deliveries",
+        "description": "Доставки от новых к старым. Неудачная доставка повторяется с экспоненциальной задержкой (30s, 1m, 2m, … до 6h); после 10 попыток она получает статус dead — status=dead возвращает список недоставленных",
+        "parameters": [
+          {
+            "name": "endpoint_id",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "format": "uuid"
+            }
+          },
+          {
+            "name": "status",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "enum": ["pending", "delivered", "dead"]
+            }
+          },
+          {
+            "name": "page",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 1
+            }
+          },
+          {
+            "name": "page_size",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 20,
+              "maximum": 100
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/DeliveriesResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "500": {
+            "$ref": "#/components/responses/InternalError"
+          }
+        }
+      }
+    },
+    "/api/v1/webhooks/deliveries/{delivery_id}/redeliver": {
+      "post": {
+        "summary": "Redeliver dead webhook delivery",
+        "description": "Возвращает недоставленную (dead) доставку в очередь с обнулённым счётчиком попыток. Доставку удалённому получателю повторить нельзя (409)",
+        "parameters": [
+          {
+            "name": "delivery_id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          }
+        ],
+        "responses": {
+          "202": {
+            "description": "Accepted",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/WebhookDelivery"
+                }
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/components/responses/BadRequest"
+          },
+          "404": {
+            "$ref": "#/components/responses/NotFound"
+          },
+          "409": {
+            "$ref": "#/components/responses/Conflict"
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "UserID": {
+        "name": "user_id",
+        "in": "query",
+        "schema": {
+          "type": "string",
+          "format": "uuid"
+        }
+      },
+      "ServiceName": {
+        "name": "service_name",
+        "in": "query",
+        "description": "Точное совпадение имени сервиса",
+        "schema": {
+          "type": "string"
+        }
+      },
+      "ServiceNamePrefix": {
+        "name": "service_name_prefix",
+        "in": "query",
+        "description": "Префикс имени сервиса без учёта регистра",
+        "schema": {
+          "type": "string"
+        }
+      },
+      "Status": {
+        "name": "status",
+        "in": "query",
+        "schema": {
+          "type": "string",
+          "enum": ["active", "expired"]
+        }
+      },
+      "State": {
+        "name": "state",
+        "in": "query",
+        "description": "Состояние жизненного цикла",
+        "schema": {
+          "type": "string",
+          "enum": ["trial", "active", "paused", "cancelled", "expired"]
+        }
+      },
+      "MinPrice": {
+        "name": "min_price",
+        "in": "query",
+        "schema": {
+          "type": "integer",
+          "format": "int64"
+        }
+      },
+      "MaxPrice": {
+        "name": "max_price",
+        "in": "query",
+        "schema": {
+          "type": "integer",
+          "format": "int64"
+        }
+      },
+      "From": {
+        "name": "from",
+        "in": "query",
+        "description": "Подписка пересекается с периодом [from, to]",
+        "schema": {
+          "type": "string",
+          "format": "date-time"
+        }
+      },
+      "To": {
+        "name": "to",
+        "in": "query",
+        "schema": {
+          "type": "string",
+          "format": "date-time"
+        }
+      },
+      "IfMatch": {
+        "name": "If-Match",
+        "in": "header",
+        "description": "ETag подписки из GET; при несовпадении версии возвращается 412",
+        "schema": {
+          "type": "string",
+          "example": "\"3\""
+        }
+      },
+      "IncludeDeleted": {
+        "name": "include_deleted",
+        "in": "query",
+        "description": "Учитывать удалённые подписки (корзину)",
+        "schema": {
+          "type": "boolean",
+          "default": false
+        }
+      },
+      "XActor": {
+        "name": "X-Actor",
+        "in": "header",
+        "description": "Инициатор изменения для журнала аудита (по умолчанию anonymous)",
+        "schema": {
+          "type": "string"
+        }
+      },
+      "XRequestID": {
+        "name": "X-Request-ID",
+        "in": "header",
+        "description": "Идентификатор запроса для журнала аудита; генерируется, если не передан, и возвращается в ответе",
+        "schema": {
+          "type": "string"
+        }
+      }
+    },
+    "headers": {
+      "ETag": {
+        "description": "Версия подписки для заголовка If-Match",
+        "schema": {
+          "type": "string",
+          "example": "\"3\""
+        }
+      }
+    },
+    "responses": {
+      "BadRequest": {
+        "description": "Invalid request",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "NotFound": {
+        "description": "Subscription not found",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "Conflict": {
+        "description": "Conflict",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "PreconditionFailed": {
+        "description": "Subscription version does not match If-Match",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "UnsupportedMediaType": {
+        "description": "Unsupported patch content type",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "ValidationFailed": {
+        "description": "Validation failed",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "InternalError": {
+        "description": "Internal error",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      },
+      "Unavailable": {
+        "description": "Service unavailable",
+        "content": {
+          "application/problem+json": {
+            "schema": {
+              "$ref": "#/components/schemas/Problem"
+            }
+          }
+        }
+      }
+    },
+    "schemas": {
+      "Subscription": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "service_name": {
+            "type": "string"
+          },
+          "price": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Цена, действующая на сегодня (см. /prices)"
+          },
+          "currency": {
+            "type": "string",
+            "description": "Код валюты ISO 4217",
+            "example": "RUB"
+          },
+          "user_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "billing_interval": {
+            "type": "string",
+            "enum": ["weekly", "monthly", "quarterly", "yearly", "custom"],
+            "default": "monthly"
+          },
+          "state": {
+            "type": "string",
+            "enum": ["trial", "active", "paused", "cancelled", "expired"],
+            "description": "Состояние; меняется действиями activate, pause, resume, cancel и автоматически (expired)"
+          },
+          "trial_end": {
+            "type": "string",
+            "format": "date-time",
+            "nullable": true,
+            "description": "Окончание пробного периода; оплата начинается с этого момента"
+          },
+          "interval_days": {
+            "type": "integer",
+            "description": "Длина периода в днях, только для billing_interval=custom",
+            "nullable": true
+          },
+          "start_date": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "end_date": {
+            "type": "string",
+            "format": "date-time",
+            "nullable": true
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "updated_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "version": {
+            "type": "integer",
+            "format": "int64",
+            "example": 1
+          },
+          "deleted_at": {
+            "type": "string",
+            "format": "date-time",
+            "nullable": true,
+            "description": "Момент удаления в корзину"
+          }
+        },
+        "required": [
+          "id",
+          "service_name",
+          "price",
+          "currency",
+          "user_id",
+          "start_date",
+          "created_at",
+          "updated_at",
+          "version"
+        ]
+      },
+      "CreateSubscriptionRequest": {
+        "type": "object",
+        "properties": {
+          "service_name": {
+            "type": "string"
+          },
+          "price": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "currency": {
+            "type": "string",
+            "description": "Код валюты ISO 4217",
+            "example": "RUB"
+          },
+          "user_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "billing_interval": {
+            "type": "string",
+            "enum": ["weekly", "monthly", "quarterly", "yearly", "custom"],
+            "default": "monthly"
+          },
+          "interval_days": {
+            "type": "integer",
+            "description": "Длина периода в днях, только для billing_interval=custom",
+            "nullable": true
+          },
+          "state": {
+            "type": "string",
+            "enum": ["trial", "active"],
+            "default": "active"
+          },
+          "trial_days": {
+            "type": "integer",
+            "minimum": 1,
+            "description": "Длина пробного периода в днях от start_date; не сочетается с trial_end"
+          },
+          "trial_end": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Окончание пробного периода. При заданном пробном периоде состояние — trial"
+          },
+          "start_date": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "end_date": {
+            "type": "string",
+            "format": "date-time",
+            "nullable": true
+          }
+        },
+        "required": [
+          "service_name",
+          "price",
+          "user_id",
+          "start_date"
+        ]
+      },
+      "UpdateSubscriptionRequest": {
+        "type": "object",
+        "description": "Полная замена подписки: пропущенные необязательные поля сбрасываются (currency — RUB, billing_interval — monthly, end_date — null)",
+        "required": [
+          "service_name",
+          "price",
+          "start_date"
+        ],
+        "properties": {
+          "service_name": {
+            "type": "string"
+          },
+          "price": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "currency": {
+            "type": "string",
+            "description": "Код валюты ISO 4217",
+            "example": "RUB"
+          },
+          "billing_interval": {
+            "type": "string",
+            "enum": ["weekly", "monthly", "quarterly", "yearly", "custom"],
+            "default": "monthly"
+          },
+          "interval_days": {
+            "type": "integer",
+            "description": "Длина периода в днях, только для billing_interval=custom",
+            "nullable": true
+          },
+          "start_date": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "end_date": {
+            "type": "string",
+            "format": "date-time",
+            "nullable": true
+          }
+        }
+      },
+      "BatchRequest": {
+        "type": "object",
+        "required": [
+          "operations"
+        ],
+        "properties": {
+          "operations": {
+            "type": "array",
+            "minItems": 1,
+            "maxItems": 1000,
+            "items": {
+              "$ref": "#/components/schemas/BatchOperation"
+            }
+          }
+        }
+      },
+      "BatchOperation": {
+        "type": "object",
+        "required": [
+          "op"
+        ],
+        "properties": {
+          "op": {
+            "type": "string",
+            "enum": ["create", "update", "delete"]
+          },
+          "id": {
+            "type": "string",
+            "format": "uuid",
+            "description": "Подписка для update и delete"
+          },
+          "version": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Ожидаемая версия подписки для update и delete, как в If-Match"
+          },
+          "subscription": {
+            "description": "Для create — CreateSubscriptionRequest, для update — UpdateSubscriptionRequest",
+            "oneOf": [
+              {
+                "$ref": "#/components/schemas/CreateSubscriptionRequest"
+              },
+              {
+                "$ref": "#/components/schemas/UpdateSubscriptionRequest"
+              }
+            ]
+          }
+        }
+      },
+      "BatchResponse": {
+        "type": "object",
+        "properties": {
+          "atomic": {
+            "type": "boolean"
+          },
+          "succeeded": {
+            "type": "integer"
+          },
+          "failed": {
+            "type": "integer"
+          },
+          "results": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/BatchResult"
+            }
+          }
+        }
+      },
+      "BatchResult": {
+        "type": "object",
+        "required": [
+          "index",
+          "op",
+          "status"
+        ],
+        "properties": {
+          "index": {
+            "type": "integer",
+            "description": "Номер операции в запросе, с нуля"
+          },
+          "op": {
+            "type": "string",
+            "enum": ["create", "update", "delete"]
+          },
+          "status": {
+            "type": "integer",
+            "description": "HTTP-статус, который вернул бы отдельный запрос с этой операцией",
+            "example": 201
+          },
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "subscription": {
+            "$ref": "#/components/schemas/Subscription"
+          },
+          "error": {
+            "$ref": "#/components/schemas/Problem"
+          }
+        }
+      },
+      "ImportJob": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "status": {
+            "type": "string",
+            "enum": ["running", "completed", "failed"],
+            "description": "failed — импорт прерван (например, нет обязательного столбца), причина — в error"
+          },
+          "dry_run": {
+            "type": "boolean"
+          },
+          "rows": {
+            "type": "integer",
+            "description": "Обработанные строки данных"
+          },
+          "valid": {
+            "type": "integer",
+            "description": "Строки, прошедшие проверку; заполняется только при dry_run"
+          },
+          "imported": {
+            "type": "integer",
+            "description": "Созданные подписки; при dry_run — 0"
+          },
+          "failed": {
+            "type": "integer",
+            "description": "Строки с ошибками"
+          },
+          "progress": {
+            "type": "number",
+            "description": "Доля прочитанного файла, от 0 до 1",
+            "example": 0.42
+          },
+          "errors": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/ImportRowError"
+            }
+          },
+          "errors_truncated": {
+            "type": "boolean",
+            "description": "В отчёт вошли не все ошибки строк (IMPORT_MAX_ERRORS)"
+          },
+          "error": {
+            "type": "string",
+            "description": "Причина, по которой импорт прерван; подробности ошибок хранилища не раскрываются"
+          },
+          "error_category": {
+            "type": "string",
+            "enum": ["validation", "invalid_argument", "not_found", "conflict", "precondition_failed", "unavailable", "internal"]
+          },
+          "started_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "finished_at": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "ImportRowError": {
+        "type": "object",
+        "properties": {
+          "line": {
+            "type": "integer",
+            "description": "Номер строки файла; заголовок — строка 1"
+          },
+          "category": {
+            "type": "string",
+            "enum": ["validation", "invalid_argument", "not_found", "conflict", "precondition_failed", "unavailable", "internal"],
+            "description": "Категория ошибки; подробности ошибок хранилища в отчёт не попадают"
+          },
+          "field": {
+            "type": "string",
+            "example": "price"
+          },
+          "message": {
+            "type": "string",
+            "example": "price must be positive"
+          }
+        }
+      },
+      "SubscriptionMergePatch": {
+        "type": "object",
+        "description": "JSON Merge Patch (RFC 7396): переданные поля заменяются, null сбрасывает interval_days и end_date",
+        "properties": {
+          "service_name": {
+            "type": "string"
+          },
+          "price": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "currency": {
+            "type": "string",
+            "description": "Код валюты ISO 4217"
+          },
+          "billing_interval": {
+            "type": "string",
+            "enum": ["weekly", "monthly", "quarterly", "yearly", "custom"]
+          },
+          "interval_days": {
+            "type": "integer",
+            "description": "Длина периода в днях, только для billing_interval=custom",
+            "nullable": true
+          },
+          "start_date": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "end_date": {
+            "type": "string",
+            "format": "date-time",
+            "nullable": true
+          }
+        },
+        "additionalProperties": false
+      },
+      "JsonPatchOperation": {
+        "type": "object",
+        "description": "Операция JSON Patch (RFC 6902) над полями service_name, price, currency, billing_interval, interval_days, start_date, end_date",
+        "required": [
+          "op",
+          "path"
+        ],
+        "properties": {
+          "op": {
+            "type": "string",
+            "enum": ["add", "remove", "replace", "move", "copy", "test"]
+          },
+          "path": {
+            "type": "string",
+            "example": "/end_date"
+          },
+          "from": {
+            "type": "string"
+          },
+          "value": {
+            "nullable": true
+          }
+        }
+      },
+      "ExchangeRate": {
+        "type": "object",
+        "properties": {
+          "base": {
+            "type": "string"
+          },
+          "quote": {
+            "type": "string"
+          },
+          "rate": {
+            "type": "number"
+          },
+          "date": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "CostBreakdownResponse": {
+        "type": "object",
+        "properties": {
+          "group_by": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "total_cost": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "groups": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/CostBreakdownGroup"
+            }
+          },
+          "rates": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/ExchangeRate"
+            }
+          },
+          "filters": {
+            "type": "object"
+          }
+        }
+      },
+      "CostBreakdownGroup": {
+        "type": "object",
+        "properties": {
+          "key": {
+            "type": "string",
+            "description": "Значение группы: имя сервиса, ID пользователя, YYYY-MM или YYYY"
+          },
+          "subscription_count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "total_cost": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "billable_months": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "normalized_cost": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "filters": {
+            "type": "object",
+            "description": "Фильтры, с которыми /cost вернёт итог группы"
+          }
+        }
+      },
+      "GetAllResponse": {
+        "type": "object",
+        "properties": {
+          "data": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Subscription"
+            }
+          },
+          "pagination": {
+            "$ref": "#/components/schemas/PaginationInfo"
+          }
+        }
+      },
+      "PaginationInfo": {
+        "type": "object",
+        "properties": {
+          "page": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "page_size": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "total_count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "total_pages": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "next_cursor": {
+            "type": "string"
+          },
+          "prev_cursor": {
+            "type": "string"
+          }
+        }
+      },
+      "PurgeResponse": {
+        "type": "object",
+        "properties": {
+          "purged": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Количество окончательно удалённых подписок"
+          },
+          "deleted_before": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Удалены подписки, перемещённые в корзину раньше этого момента"
+          }
+        }
+      },
+      "SubscriptionEvent": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "subscription_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "operation": {
+            "type": "string",
+            "enum": ["create", "update", "delete", "restore", "purge", "activate", "pause", "resume", "cancel", "expire", "schedule_price"]
           },
-          "created_at": {
+          "actor": {
             "type": "string",
-            "format": "date-time"
+            "example": "anonymous"
           },
-          "updated_at": {
+          "request_id": {
+            "type": "string"
+          },
+          "before": {
+            "allOf": [
+              {
+                "$ref": "#/components/schemas/Subscription"
+              }
+            ],
+            "nullable": true,
+            "description": "Состояние до изменения (для schedule_price — заменённая цена PriceChange)"
+          },
+          "after": {
+            "allOf": [
+              {
+                "$ref": "#/components/schemas/Subscription"
+              }
+            ],
+            "nullable": true,
+            "description": "Состояние после изменения (для schedule_price — новая цена PriceChange)"
+          },
+          "occurred_at": {
             "type": "string",
             "format": "date-time"
           }
-        },
-        "required": [
-          "id",
-          "service_name",
-          "price",
-          "user_id",
-          "start_date",
-          "created_at",
-          "updated_at"
-        ]
+        }
       },
-      "CreateSubscriptionRequest": {
+      "HistoryResponse": {
         "type": "object",
         "properties": {
-          "service_name": {
-            "type": "string"
+          "data": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/SubscriptionEvent"
+            }
           },
-          "price": {
+          "pagination": {
+            "$ref": "#/components/schemas/PaginationInfo"
+          }
+        }
+      },
+      "Charge": {
+        "type": "object",
+        "properties": {
+          "id": {
             "type": "integer",
             "format": "int64"
           },
-          "user_id": {
+          "subscription_id": {
             "type": "string",
             "format": "uuid"
           },
-          "start_date": {
+          "period_start": {
             "type": "string",
             "format": "date-time"
           },
-          "end_date": {
+          "period_end": {
             "type": "string",
             "format": "date-time",
-            "nullable": true
+            "description": "Конец периода (не включительно)"
+          },
+          "amount": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Цена подписки на начало периода"
+          },
+          "currency": {
+            "type": "string",
+            "example": "RUB"
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
           }
-        },
-        "required": [
-          "service_name",
-          "price",
-          "user_id",
-          "start_date"
-        ]
+        }
       },
-      "UpdateSubscriptionRequest": {
+      "ChargesResponse": {
+        "type": "object",
+        "properties": {
+          "data": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Charge"
+            }
+          },
+          "pagination": {
+            "$ref": "#/components/schemas/PaginationInfo"
+          }
+        }
+      },
+      "Reminder": {
         "type": "object",
         "properties": {
+          "subscription_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "user_id": {
+            "type": "string",
+            "format": "uuid"
+          },
           "service_name": {
             "type": "string"
           },
-          "price": {
-            "type": "integer",
-            "format": "int64"
+          "kind": {
+            "type": "string",
+            "enum": ["renewal", "trial_end", "expiry"],
+            "description": "renewal — следующее списание, trial_end — окончание пробного периода, expiry — окончание подписки"
           },
-          "start_date": {
+          "due_at": {
             "type": "string",
             "format": "date-time"
           },
-          "end_date": {
+          "remind_at": {
             "type": "string",
             "format": "date-time",
-            "nullable": true
+            "description": "Когда будет отправлено напоминание"
+          },
+          "amount": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Цена подписки на due_at"
+          },
+          "currency": {
+            "type": "string",
+            "example": "RUB"
+          },
+          "sent_at": {
+            "type": "string",
+            "format": "date-time",
+            "nullable": true,
+            "description": "Когда напоминание было отправлено"
           }
         }
       },
-      "GetAllResponse": {
+      "UpcomingResponse": {
         "type": "object",
         "properties": {
           "data": {
             "type": "array",
             "items": {
-              "$ref": "#/components/schemas/Subscription"
+              "$ref": "#/components/schemas/Reminder"
             }
           },
-          "pagination": {
-            "$ref": "#/components/schemas/PaginationInfo"
+          "lead": {
+            "type": "string",
+            "example": "72h0m0s"
           }
         }
       },
-      "PaginationInfo": {
+      "PriceChange": {
         "type": "object",
         "properties": {
-          "page": {
+          "subscription_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "price": {
             "type": "integer",
             "format": "int64"
           },
-          "page_size": {
+          "effective_from": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Дата вступления в силу"
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "SchedulePriceRequest": {
+        "type": "object",
+        "required": [
+          "price",
+          "effective_from"
+        ],
+        "properties": {
+          "price": {
             "type": "integer",
-            "format": "int64"
+            "format": "int64",
+            "minimum": 1
           },
-          "total_count": {
+          "effective_from": {
+            "type": "string",
+            "format": "date-time",
+            "description": "Дата вступления в силу (время не учитывается), должна быть в будущем и в пределах периода подписки"
+          }
+        }
+      },
+      "PricesResponse": {
+        "type": "object",
+        "properties": {
+          "data": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/PriceChange"
+            }
+          }
+        }
+      },
+      "Problem": {
+        "type": "object",
+        "description": "Ошибка в формате RFC 7807",
+        "required": [
+          "type",
+          "title",
+          "status"
+        ],
+        "properties": {
+          "type": {
+            "type": "string",
+            "example": "/problems/validation-failed"
+          },
+          "title": {
+            "type": "string",
+            "example": "Unprocessable Entity"
+          },
+          "status": {
+            "type": "integer",
+            "example": 422
+          },
+          "detail": {
+            "type": "string",
+            "example": "request validation failed"
+          },
+          "instance": {
+            "type": "string",
+            "example": "/api/v1/subscriptions"
+          },
+          "errors": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/ProblemField"
+            }
+          }
+        }
+      },
+      "ProblemField": {
+        "type": "object",
+        "required": [
+          "field",
+          "code",
+          "message"
+        ],
+        "properties": {
+          "field": {
+            "type": "string",
+            "example": "currency"
+          },
+          "code": {
+            "type": "string",
+            "example": "iso4217"
+          },
+          "message": {
+            "type": "string",
+            "example": "must be an ISO 4217 currency code"
+          }
+        }
+      },
+      "WebhookEndpointRequest": {
+        "type": "object",
+        "required": [
+          "url"
+        ],
+        "properties": {
+          "url": {
+            "type": "string",
+            "format": "uri",
+            "example": "https://billing.example.com/hooks/subscriptions"
+          },
+          "secret": {
+            "type": "string",
+            "minLength": 16,
+            "maxLength": 256,
+            "description": "Ключ подписи; если не задан, при создании генерируется случайный"
+          },
+          "events": {
+            "type": "array",
+            "items": {
+              "type": "string",
+              "enum": ["create", "update", "delete", "restore", "purge", "activate", "pause", "resume", "cancel", "expire", "schedule_price"]
+            },
+            "description": "Операции, о которых сообщать; пустой список — обо всех"
+          },
+          "active": {
+            "type": "boolean",
+            "default": true
+          }
+        }
+      },
+      "WebhookEndpoint": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "url": {
+            "type": "string",
+            "format": "uri"
+          },
+          "secret": {
+            "type": "string",
+            "description": "Только в ответе на создание"
+          },
+          "events": {
+            "type": "array",
+            "items": {
+              "type": "string",
+              "enum": ["create", "update", "delete", "restore", "purge", "activate", "pause", "resume", "cancel", "expire", "schedule_price"]
+            }
+          },
+          "active": {
+            "type": "boolean"
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "updated_at": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "WebhookEndpointsResponse": {
+        "type": "object",
+        "properties": {
+          "data": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/WebhookEndpoint"
+            }
+          }
+        }
+      },
+      "WebhookDelivery": {
+        "type": "object",
+        "properties": {
+          "id": {
             "type": "integer",
             "format": "int64"
           },
-          "total_pages": {
+          "endpoint_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "event_id": {
             "type": "integer",
             "format": "int64"
+          },
+          "event_type": {
+            "type": "string",
+            "enum": ["create", "update", "delete", "restore", "purge", "activate", "pause", "resume", "cancel", "expire", "schedule_price"]
+          },
+          "subscription_id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "payload": {
+            "$ref": "#/components/schemas/SubscriptionEvent"
+          },
+          "status": {
+            "type": "string",
+            "enum": ["pending", "delivered", "dead"]
+          },
+          "attempts": {
+            "type": "integer"
+          },
+          "next_attempt_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "last_status_code": {
+            "type": "integer"
+          },
+          "last_error": {
+            "type": "string"
+          },
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "delivered_at": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "DeliveriesResponse": {
+        "type": "object",
+        "properties": {
+          "data": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/WebhookDelivery"
+            }
+          },
+          "pagination": {
+            "$ref": "#/components/schemas/PaginationInfo"
           }
         }
       }
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhook регистрирует получателя вебхуков; секрет возвращается только в ответе на создание.
+func (h *Handler) CreateWebhook(ctx *gin.Context) {
+	h.customLogger.Debug().Msg("Create webhook: started")
+
+	var request dto.WebhookEndpointRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Msg("Create webhook: invalid request")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	created, err := h.webhooks.CreateEndpoint(ctx, request)
+	if err != nil {
+		h.customLogger.Error().Err(err).Msg("Create webhook: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.Info().
+		Str("createdId", created.Id.String()).
+		Msg("Create webhook: created")
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (h *Handler) GetWebhooks(ctx *gin.Context) {
+	h.customLogger.Debug().Msg("Get webhooks: started")
+
+	res, err := h.webhooks.GetEndpoints(ctx)
+	if err != nil {
+		h.customLogger.Error().Err(err).Msg("Get webhooks: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.Info().
+		Int("endpoints", len(res.Data)).
+		Msg("Get webhooks: success")
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetWebhook(ctx *gin.Context) {
+	h.customLogger.Debug().Msg("Get webhook: started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Get webhook: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	endpoint, err := h.webhooks.GetEndpoint(ctx, id)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("id", id.String()).
+			Msg("Get webhook: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.Info().
+		Str("id", id.String()).
+		Msg("Get webhook: success")
+	ctx.JSON(http.StatusOK, endpoint)
+}
+
+func (h *Handler) UpdateWebhook(ctx *gin.Context) {
+	h.customLogger.Debug().Msg("Update webhook: started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Update webhook: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	var request dto.WebhookEndpointRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", id.String()).
+			Msg("Update webhook: invalid request")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	updated, err := h.webhooks.UpdateEndpoint(ctx, id, request)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("id", id.String()).
+			Msg("Update webhook: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.Info().
+		Str("id", id.String()).
+		Msg("Update webhook: success")
+	ctx.JSON(http.StatusOK, updated)
+}
+
+func (h *Handler) DeleteWebhook(ctx *gin.Context) {
+	h.customLogger.Debug().Msg("Delete webhook: started")
+
+	id, err := parseID(ctx)
+	if err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Str("id", ctx.Param("id")).
+			Msg("Delete webhook: invalid id")
+		_ = ctx.Error(err)
+		return
+	}
+
+	if err := h.webhooks.DeleteEndpoint(ctx, id); err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Str("id", id.String()).
+			Msg("Delete webhook: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.Info().
+		Str("id", id.String()).
+		Msg("Delete webhook: success")
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// GetDeliveries — доставки вебхуков от новых к старым; status=dead — список недоставленных.
+func (h *Handler) GetDeliveries(ctx *gin.Context) {
+	const op = "Get webhook deliveries"
+	h.customLogger.Debug().Msg(op + ": started")
+
+	var request dto.DeliveriesQueryRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		h.customLogger.
+			Warn().Err(err).
+			Msg(op + ": invalid query parameters")
+		_ = ctx.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	page, pageSize := h.pageParams(ctx, op)
+
+	res, err := h.webhooks.GetDeliveries(ctx, request, dto.PageRequest{Page: page, PageSize: pageSize})
+	if err != nil {
+		h.customLogger.Error().Err(err).Msg(op + ": service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.Info().
+		Int("deliveries", len(res.Data)).
+		Msg(op + ": success")
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Redeliver возвращает недоставленную доставку в очередь отправки.
+func (h *Handler) Redeliver(ctx *gin.Context) {
+	h.customLogger.Debug().Msg("Redeliver webhook: started")
+
+	id, err := strconv.ParseInt(ctx.Param("delivery_id"), 10, 64)
+	if err != nil || id < 1 {
+		h.customLogger.
+			Warn().
+			Str("deliveryId", ctx.Param("delivery_id")).
+			Msg("Redeliver webhook: invalid delivery id")
+		_ = ctx.Error(fmt.Errorf("%w: invalid delivery_id format", models.ErrInvalidArgument))
+		return
+	}
+
+	delivery, err := h.webhooks.Redeliver(ctx, id)
+	if err != nil {
+		h.customLogger.
+			Error().Err(err).
+			Int64("deliveryId", id).
+			Msg("Redeliver webhook: service error")
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.customLogger.Info().
+		Int64("deliveryId", id).
+		Msg("Redeliver webhook: queued")
+	ctx.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,13 @@
+package app_interfaces
+
+import (
+	"SubscriptionService/internal/application/events"
+	"context"
+)
+
+// IEventPublisher доставляет доменные события подписчикам (events.SyncDispatcher, events.AsyncDispatcher).
+type IEventPublisher interface {
+	// Publish не возвращает ошибок: сбой обработчика не отменяет опубликовавшую событие операцию
+	Publish(ctx context.Context, event events.Event)
+	Subscribe(name string, handler events.Handler, events ...string)
+}
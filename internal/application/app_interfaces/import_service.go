@@ -0,0 +1,19 @@
+package app_interfaces
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+type IImportService interface {
+	// Start принимает CSV-файл из src и импортирует его: небольшие файлы — сразу,
+	// остальные — в фоне (ход импорта возвращает GetJob)
+	Start(ctx context.Context, src io.Reader, req dto.ImportRequest) (*dto.ImportJob, error)
+	// Import импортирует CSV из src размером size байт, дожидаясь завершения
+	Import(ctx context.Context, src io.Reader, size int64, req dto.ImportRequest) (*dto.ImportJob, error)
+	// GetJob возвращает ход и отчёт импорта
+	GetJob(ctx context.Context, id uuid.UUID) (*dto.ImportJob, error)
+}
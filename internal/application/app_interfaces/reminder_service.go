@@ -0,0 +1,16 @@
+package app_interfaces
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IReminderService interface {
+	// Upcoming возвращает предстоящие события подписок пользователя с временем напоминания
+	Upcoming(ctx context.Context, userID uuid.UUID, req dto.UpcomingQueryRequest) (dto.UpcomingResponse, error)
+	// DispatchDue отправляет напоминания о событиях, до которых осталось не больше срока напоминания
+	DispatchDue(ctx context.Context, now time.Time) (int, error)
+}
@@ -4,15 +4,36 @@ import (
 	"SubscriptionService/internal/api/dto"
 	"SubscriptionService/internal/core/models"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type ISubService interface {
 	Create(ctx context.Context, req dto.CreateSubscriptionRequest) (*models.Subscription, error)
-	Update(ctx context.Context, id uuid.UUID, req dto.UpdateSubscriptionRequest) (*models.Subscription, error)
-	Delete(ctx context.Context, id uuid.UUID) error
-	GetById(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
-	GetAll(ctx context.Context, page, pageSize int64) (dto.GetAllResponse, error)
-	CalculateTotalCost(ctx context.Context, req dto.CostCalculationQueryRequest) (int64, error)
+	// Batch выполняет пакет операций; ошибки отдельных операций неатомарного пакета — в BatchResult.Err
+	Batch(ctx context.Context, req dto.BatchRequest) ([]*dto.BatchResult, error)
+	// Update и Delete с ifMatch != nil выполняются, только если версия подписки совпадает
+	Update(ctx context.Context, id uuid.UUID, req dto.UpdateSubscriptionRequest, ifMatch *int64) (*models.Subscription, error)
+	Patch(ctx context.Context, id uuid.UUID, req dto.PatchSubscriptionRequest, ifMatch *int64) (*models.Subscription, error)
+	// Delete переносит подписку в корзину
+	Delete(ctx context.Context, id uuid.UUID, ifMatch *int64) error
+	Restore(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
+	PurgeTrash(ctx context.Context) (dto.PurgeResponse, error)
+	// ChangeState выполняет действие над подпиской: activate, pause, resume или cancel
+	ChangeState(ctx context.Context, id uuid.UUID, op models.Operation, ifMatch *int64) (*models.Subscription, error)
+	ExpireDue(ctx context.Context, now time.Time) (int64, error)
+	ConvertDueTrials(ctx context.Context, now time.Time) (int64, error)
+	// EndingTrials возвращает пробные подписки, которые станут платными в ближайшие дни
+	EndingTrials(ctx context.Context, req dto.EndingTrialsQueryRequest, page dto.PageRequest) (dto.GetAllResponse, error)
+	GetById(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Subscription, error)
+	SchedulePrice(ctx context.Context, id uuid.UUID, req dto.SchedulePriceRequest) (*models.PriceChange, error)
+	GetPrices(ctx context.Context, id uuid.UUID) ([]*models.PriceChange, error)
+	GetHistory(ctx context.Context, id uuid.UUID, page dto.PageRequest) (dto.HistoryResponse, error)
+	// RenewDue создаёт списания за все наступившие к now периоды оплаты
+	RenewDue(ctx context.Context, now time.Time) (int64, error)
+	GetCharges(ctx context.Context, id uuid.UUID, page dto.PageRequest) (dto.ChargesResponse, error)
+	GetAll(ctx context.Context, req dto.GetAllQueryRequest, page dto.PageRequest) (dto.GetAllResponse, error)
+	CalculateTotalCost(ctx context.Context, req dto.CostCalculationQueryRequest) (*models.CostSummary, error)
+	CalculateCostBreakdown(ctx context.Context, req dto.CostBreakdownQueryRequest) (*dto.CostBreakdownResponse, error)
 }
@@ -0,0 +1,23 @@
+package app_interfaces
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IWebhookService interface {
+	CreateEndpoint(ctx context.Context, req dto.WebhookEndpointRequest) (*models.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, id uuid.UUID, req dto.WebhookEndpointRequest) (*models.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*models.WebhookEndpoint, error)
+	GetEndpoints(ctx context.Context) (dto.WebhookEndpointsResponse, error)
+	GetDeliveries(ctx context.Context, req dto.DeliveriesQueryRequest, page dto.PageRequest) (dto.DeliveriesResponse, error)
+	// Redeliver возвращает недоставленную доставку в очередь
+	Redeliver(ctx context.Context, id int64) (*models.WebhookDelivery, error)
+	// DispatchDue отправляет доставки, время попытки которых наступило
+	DispatchDue(ctx context.Context, now time.Time) (int, error)
+}
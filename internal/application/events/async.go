@@ -0,0 +1,103 @@
+package events
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+type queued struct {
+	ctx   context.Context
+	event Event
+}
+
+// AsyncDispatcher передаёт события пулу обработчиков через буферизованные очереди.
+// События одной подписки попадают в одну очередь и обрабатываются в порядке публикации.
+// Если очередь заполнена, Publish ждёт места, пока не отменён контекст публикации.
+type AsyncDispatcher struct {
+	registry
+	queues []chan queued
+	wg     sync.WaitGroup
+	// closeMu не даёт закрыть очереди во время отправки в них
+	closeMu sync.RWMutex
+	closed  bool
+}
+
+// NewAsyncDispatcher запускает workers обработчиков с очередью на buffer событий у каждого.
+func NewAsyncDispatcher(workers, buffer int, logger *zerolog.Logger) *AsyncDispatcher {
+	d := &AsyncDispatcher{
+		registry: registry{logger: logger},
+		queues:   make([]chan queued, max(workers, 1)),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan queued, buffer)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+func (d *AsyncDispatcher) work(queue <-chan queued) {
+	defer d.wg.Done()
+	for item := range queue {
+		d.dispatch(item.ctx, item.event)
+	}
+}
+
+// Publish ставит событие в очередь. Обработчики получают контекст публикации
+// без отмены: значения (например, models.AuditInfo) доступны и после завершения запроса.
+func (d *AsyncDispatcher) Publish(ctx context.Context, event Event) {
+	d.closeMu.RLock()
+	defer d.closeMu.RUnlock()
+	if d.closed {
+		d.logger.Warn().
+			Str("event", event.Name()).
+			Str("subscriptionId", event.SubscriptionID().String()).
+			Msg("Event dropped: dispatcher is closed")
+		return
+	}
+
+	select {
+	case d.queue(event) <- queued{ctx: context.WithoutCancel(ctx), event: event}:
+	case <-ctx.Done():
+		d.logger.Warn().
+			Err(ctx.Err()).
+			Str("event", event.Name()).
+			Str("subscriptionId", event.SubscriptionID().String()).
+			Msg("Event dropped: queue is full")
+	}
+}
+
+func (d *AsyncDispatcher) queue(event Event) chan queued {
+	id := event.SubscriptionID()
+	h := fnv.New32a()
+	h.Write(id[:])
+	return d.queues[h.Sum32()%uint32(len(d.queues))]
+}
+
+// Close перестаёт принимать события и ждёт обработки уже поставленных в очередь,
+// пока не отменён ctx.
+func (d *AsyncDispatcher) Close(ctx context.Context) error {
+	d.closeMu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+	d.closeMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,234 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// priceEvent — событие подписки id с порядковым номером seq в NewPrice.
+func priceEvent(id uuid.UUID, seq int) Event {
+	return PriceChanged{Meta: Meta{SubscriptionId: id}, NewPrice: int64(seq)}
+}
+
+// recorder запоминает номера обработанных событий по подпискам.
+type recorder struct {
+	mu   sync.Mutex
+	seqs map[uuid.UUID][]int64
+}
+
+func newRecorder() *recorder {
+	return &recorder{seqs: make(map[uuid.UUID][]int64)}
+}
+
+func (r *recorder) handle(_ context.Context, event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seqs[event.SubscriptionID()] = append(r.seqs[event.SubscriptionID()], event.(PriceChanged).NewPrice)
+	return nil
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, seqs := range r.seqs {
+		n += len(seqs)
+	}
+	return n
+}
+
+func closeDispatcher(t *testing.T, d *AsyncDispatcher) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestAsyncDispatcherKeepsOrderPerSubscription(t *testing.T) {
+	logger := zerolog.Nop()
+	d := NewAsyncDispatcher(4, 8, &logger)
+	rec := newRecorder()
+	d.Subscribe("recorder", rec.handle)
+
+	const perSub = 200
+	ids := make([]uuid.UUID, 16)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	// Подписки публикуются параллельно, события каждой — по порядку
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seq := range perSub {
+				d.Publish(context.Background(), priceEvent(id, seq))
+			}
+		}()
+	}
+	wg.Wait()
+	// Close дожидается событий, уже поставленных в очередь
+	closeDispatcher(t, d)
+
+	for _, id := range ids {
+		seqs := rec.seqs[id]
+		if len(seqs) != perSub {
+			t.Fatalf("subscription %s: handled %d events, want %d", id, len(seqs), perSub)
+		}
+		for i, seq := range seqs {
+			if seq != int64(i) {
+				t.Fatalf("subscription %s: event %d handled at position %d", id, seq, i)
+			}
+		}
+	}
+}
+
+func TestAsyncDispatcherQueueIsStable(t *testing.T) {
+	logger := zerolog.Nop()
+	d := NewAsyncDispatcher(8, 1, &logger)
+	defer closeDispatcher(t, d)
+
+	id := uuid.New()
+	queue := d.queue(priceEvent(id, 0))
+	for seq := 1; seq < 10; seq++ {
+		if d.queue(priceEvent(id, seq)) != queue {
+			t.Fatal("events of one subscription go to different queues")
+		}
+	}
+}
+
+func TestAsyncDispatcherDropsAfterClose(t *testing.T) {
+	logger := zerolog.Nop()
+	d := NewAsyncDispatcher(2, 4, &logger)
+	rec := newRecorder()
+	d.Subscribe("recorder", rec.handle)
+
+	d.Publish(context.Background(), priceEvent(uuid.New(), 1))
+	closeDispatcher(t, d)
+	d.Publish(context.Background(), priceEvent(uuid.New(), 2))
+	// Повторное закрытие безопасно
+	closeDispatcher(t, d)
+
+	if got := rec.count(); got != 1 {
+		t.Errorf("handled %d events, want 1", got)
+	}
+}
+
+func TestAsyncDispatcherDropsWhenQueueFullAndContextDone(t *testing.T) {
+	logger := zerolog.Nop()
+	d := NewAsyncDispatcher(1, 1, &logger)
+	started, release := make(chan struct{}), make(chan struct{})
+	rec := newRecorder()
+	d.Subscribe("blocking", func(ctx context.Context, event Event) error {
+		if event.(PriceChanged).NewPrice == 1 {
+			close(started)
+			<-release
+		}
+		return rec.handle(ctx, event)
+	})
+
+	id := uuid.New()
+	d.Publish(context.Background(), priceEvent(id, 1))
+	<-started
+	// Обработчик занят первым событием, второе заполняет очередь
+	d.Publish(context.Background(), priceEvent(id, 2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	published := make(chan struct{})
+	go func() {
+		d.Publish(ctx, priceEvent(id, 3))
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a full queue after its context was cancelled")
+	}
+
+	close(release)
+	closeDispatcher(t, d)
+	if seqs := rec.seqs[id]; len(seqs) != 2 || seqs[0] != 1 || seqs[1] != 2 {
+		t.Errorf("handled %v, want [1 2]", seqs)
+	}
+}
+
+func TestAsyncDispatcherHandlerContextOutlivesPublisher(t *testing.T) {
+	logger := zerolog.Nop()
+	d := NewAsyncDispatcher(1, 1, &logger)
+	type key struct{}
+	got := make(chan error, 1)
+	d.Subscribe("context", func(ctx context.Context, _ Event) error {
+		if ctx.Value(key{}) != "value" {
+			got <- errors.New("context value is lost")
+			return nil
+		}
+		got <- ctx.Err()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	d.Publish(ctx, priceEvent(uuid.New(), 1))
+	cancel()
+	closeDispatcher(t, d)
+	if err := <-got; err != nil {
+		t.Errorf("handler context: %v", err)
+	}
+}
+
+func TestAsyncDispatcherPublishDuringClose(t *testing.T) {
+	logger := zerolog.Nop()
+	d := NewAsyncDispatcher(4, 4, &logger)
+	rec := newRecorder()
+	d.Subscribe("recorder", rec.handle)
+
+	// Публикации, идущие одновременно с Close, либо обрабатываются, либо отбрасываются,
+	// но не отправляют в закрытую очередь
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := uuid.New()
+			for seq := range 500 {
+				d.Publish(context.Background(), priceEvent(id, seq))
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	closeDispatcher(t, d)
+	wg.Wait()
+
+	if got := rec.count(); got > 8*500 {
+		t.Errorf("handled %d events, published at most %d", got, 8*500)
+	}
+}
+
+func TestAsyncDispatcherCloseTimesOut(t *testing.T) {
+	logger := zerolog.Nop()
+	d := NewAsyncDispatcher(1, 1, &logger)
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	d.Subscribe("blocking", func(context.Context, Event) error {
+		close(started)
+		<-release
+		return nil
+	})
+	d.Publish(context.Background(), priceEvent(uuid.New(), 1))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := d.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close = %v, want DeadlineExceeded", err)
+	}
+}
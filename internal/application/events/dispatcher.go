@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Handler обрабатывает событие. Ошибка обработчика записывается в лог
+// и не влияет ни на операцию, опубликовавшую событие, ни на другие обработчики.
+type Handler func(ctx context.Context, event Event) error
+
+type subscriber struct {
+	name   string
+	events map[string]bool
+	handle Handler
+}
+
+// registry — подписчики на события, общие для синхронного и асинхронного диспетчеров.
+type registry struct {
+	mu          sync.RWMutex
+	subscribers []subscriber
+	logger      *zerolog.Logger
+}
+
+// Subscribe регистрирует обработчик name на события с именами events; без имён — на все события.
+func (r *registry) Subscribe(name string, handler Handler, events ...string) {
+	filter := make(map[string]bool, len(events))
+	for _, event := range events {
+		filter[event] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, subscriber{name: name, events: filter, handle: handler})
+}
+
+// dispatch вызывает обработчики события в порядке подписки.
+func (r *registry) dispatch(ctx context.Context, event Event) {
+	r.mu.RLock()
+	subscribers := r.subscribers
+	r.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		if len(sub.events) > 0 && !sub.events[event.Name()] {
+			continue
+		}
+		if err := safeHandle(ctx, sub.handle, event); err != nil {
+			r.logger.Error().
+				Err(err).
+				Str("subscriber", sub.name).
+				Str("event", event.Name()).
+				Str("subscriptionId", event.SubscriptionID().String()).
+				Msg("Event handler failed")
+		}
+	}
+}
+
+// safeHandle превращает панику обработчика в ошибку.
+func safeHandle(ctx context.Context, handle Handler, event Event) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("event handler panic: %v", p)
+		}
+	}()
+	return handle(ctx, event)
+}
+
+// SyncDispatcher вызывает обработчики в горутине публикации, до возврата из Publish.
+type SyncDispatcher struct {
+	registry
+}
+
+func NewSyncDispatcher(logger *zerolog.Logger) *SyncDispatcher {
+	return &SyncDispatcher{registry: registry{logger: logger}}
+}
+
+func (d *SyncDispatcher) Publish(ctx context.Context, event Event) {
+	d.dispatch(ctx, event)
+}
@@ -0,0 +1,146 @@
+// Package events — доменные события подписок и их доставка подписчикам внутри процесса.
+// SubService публикует события, не зная, кто их обрабатывает (аудит, вебхуки, метрики, кеш).
+package events
+
+import (
+	"SubscriptionService/internal/core/models"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Имена событий
+const (
+	NameSubscriptionCreated = "subscription.created"
+	NameSubscriptionUpdated = "subscription.updated"
+	NameSubscriptionDeleted = "subscription.deleted"
+	NamePriceChanged        = "subscription.price_changed"
+	NameSubscriptionExpired = "subscription.expired"
+)
+
+// Event — доменное событие подписки.
+type Event interface {
+	Name() string
+	SubscriptionID() uuid.UUID
+	OccurredAt() time.Time
+}
+
+// Meta — общие поля событий.
+type Meta struct {
+	SubscriptionId uuid.UUID `json:"subscription_id"`
+	At             time.Time `json:"occurred_at"`
+}
+
+func (m Meta) SubscriptionID() uuid.UUID { return m.SubscriptionId }
+func (m Meta) OccurredAt() time.Time     { return m.At }
+
+type SubscriptionCreated struct {
+	Meta
+	Subscription *models.Subscription `json:"subscription"`
+}
+
+func (SubscriptionCreated) Name() string { return NameSubscriptionCreated }
+
+// SubscriptionUpdated — изменение подписки (замена, частичное изменение, смена состояния).
+type SubscriptionUpdated struct {
+	Meta
+	Before *models.Subscription `json:"before"`
+	After  *models.Subscription `json:"after"`
+	Diff   []FieldChange        `json:"diff"`
+}
+
+func (SubscriptionUpdated) Name() string { return NameSubscriptionUpdated }
+
+type SubscriptionDeleted struct {
+	Meta
+}
+
+func (SubscriptionDeleted) Name() string { return NameSubscriptionDeleted }
+
+// PriceChanged — новая цена подписки с даты EffectiveFrom: запланированное изменение
+// или замена цены при изменении подписки.
+type PriceChanged struct {
+	Meta
+	OldPrice      int64     `json:"old_price"`
+	NewPrice      int64     `json:"new_price"`
+	Currency      string    `json:"currency"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+func (PriceChanged) Name() string { return NamePriceChanged }
+
+type SubscriptionExpired struct {
+	Meta
+	Subscription *models.Subscription `json:"subscription"`
+}
+
+func (SubscriptionExpired) Name() string { return NameSubscriptionExpired }
+
+// FieldChange — изменение одного поля подписки (имена и значения — как в JSON).
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// Служебные поля, меняющиеся при любом изменении, в разницу не входят
+var ignoredDiffFields = map[string]bool{"updated_at": true, "version": true}
+
+// Diff возвращает изменившиеся поля подписки в порядке имён.
+func Diff(before, after *models.Subscription) ([]FieldChange, error) {
+	oldFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(newFields))
+	for name := range oldFields {
+		names = append(names, name)
+	}
+	for name := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []FieldChange{}
+	for _, name := range names {
+		if ignoredDiffFields[name] {
+			continue
+		}
+		oldValue, newValue := orNull(oldFields[name]), orNull(newFields[name])
+		if !bytes.Equal(oldValue, newValue) {
+			changes = append(changes, FieldChange{Field: name, Old: oldValue, New: newValue})
+		}
+	}
+	return changes, nil
+}
+
+func fields(sub *models.Subscription) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return nil, fmt.Errorf("marshal subscription: %w", err)
+	}
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal subscription: %w", err)
+	}
+	return result, nil
+}
+
+// orNull — отсутствующее (omitempty) поле равно null
+func orNull(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	return value
+}
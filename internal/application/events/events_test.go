@@ -0,0 +1,110 @@
+package events
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestDiff(t *testing.T) {
+	end := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
+	before := &models.Subscription{
+		Id:          uuid.New(),
+		ServiceName: "Netflix",
+		Price:       400,
+		Currency:    "RUB",
+		StartDate:   time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Version:     1,
+	}
+	after := *before
+	after.Price = 500
+	after.EndDate = &end
+	after.UpdatedAt = time.Now()
+	after.Version = 2
+
+	changes, err := Diff(before, &after)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	// updated_at и version не входят; end_date отсутствовал (omitempty) — старое значение null
+	want := []FieldChange{
+		{Field: "end_date", Old: []byte("null"), New: []byte(`"2024-12-31T00:00:00Z"`)},
+		{Field: "price", Old: []byte("400"), New: []byte("500")},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %s, want %d", changes, len(want))
+	}
+	for i, change := range changes {
+		if change.Field != want[i].Field || string(change.Old) != string(want[i].Old) || string(change.New) != string(want[i].New) {
+			t.Errorf("change %d = %s %s -> %s, want %s %s -> %s", i,
+				change.Field, change.Old, change.New, want[i].Field, want[i].Old, want[i].New)
+		}
+	}
+
+	// Сброс необязательного поля — новое значение null
+	changes, err = Diff(&after, before)
+	if err != nil {
+		t.Fatalf("Diff reverse: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Field != "end_date" || string(changes[0].New) != "null" {
+		t.Errorf("reverse changes = %s", changes)
+	}
+
+	// Изменились только служебные поля — разница пустая, но не nil (в JSON — [])
+	touched := *before
+	touched.Version = 5
+	touched.UpdatedAt = time.Now()
+	changes, err = Diff(before, &touched)
+	if err != nil || changes == nil || len(changes) != 0 {
+		t.Errorf("service fields only: changes %v, err %v", changes, err)
+	}
+}
+
+func TestDispatchIsolatesHandlers(t *testing.T) {
+	logger := zerolog.Nop()
+	dispatcher := NewSyncDispatcher(&logger)
+	var calls []string
+	dispatcher.Subscribe("panics", func(context.Context, Event) error {
+		calls = append(calls, "panics")
+		panic("boom")
+	})
+	dispatcher.Subscribe("fails", func(context.Context, Event) error {
+		calls = append(calls, "fails")
+		return errors.New("handler failed")
+	})
+	dispatcher.Subscribe("deleted", func(context.Context, Event) error {
+		calls = append(calls, "deleted")
+		return nil
+	}, NameSubscriptionDeleted)
+	dispatcher.Subscribe("created", func(context.Context, Event) error {
+		calls = append(calls, "created")
+		return nil
+	}, NameSubscriptionCreated)
+
+	dispatcher.Publish(context.Background(), SubscriptionCreated{Meta: Meta{SubscriptionId: uuid.New()}})
+
+	// Паника и ошибка обработчика не мешают следующим; фильтр по имени пропускает чужие события
+	want := []string{"panics", "fails", "created"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("calls = %v, want %v", calls, want)
+		}
+	}
+}
+
+func TestSafeHandleRecoversPanic(t *testing.T) {
+	err := safeHandle(context.Background(), func(context.Context, Event) error {
+		panic("boom")
+	}, SubscriptionDeleted{})
+	if err == nil || err.Error() != "event handler panic: boom" {
+		t.Errorf("safeHandle = %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package events
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// LogHandler записывает каждое событие в лог.
+func LogHandler(logger *zerolog.Logger) Handler {
+	return func(ctx context.Context, event Event) error {
+		logger.Info().
+			Str("event", event.Name()).
+			Str("subscriptionId", event.SubscriptionID().String()).
+			Time("occurredAt", event.OccurredAt()).
+			Msg("Subscription event")
+		return nil
+	}
+}
@@ -0,0 +1,709 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	appInterfaces "SubscriptionService/internal/application/app_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Сколько проверенных строк CSV создаётся одним пакетом
+const importChunkSize = 500
+
+// Поля CreateSubscriptionRequest, которые заполняются из столбцов CSV, и обязательные из них
+var (
+	importFields = []string{"service_name", "price", "currency", "user_id", "billing_interval", "interval_days",
+		"state", "trial_days", "trial_end", "start_date", "end_date"}
+	requiredImportFields = []string{"service_name", "price", "user_id", "start_date"}
+)
+
+// errImportStopped — причина, по которой прерываются фоновые импорты при остановке сервиса
+var errImportStopped = fmt.Errorf("%w: import service is shutting down", models.ErrUnavailable)
+
+// Форматы дат в CSV: RFC 3339, дата ISO 8601, дата через точки и месяц MM-YYYY
+var importDateLayouts = []string{time.RFC3339, "2006-01-02", "02.01.2006", "01-2006"}
+
+// ImportService импортирует подписки из CSV. Строки проверяются так же, как тело
+// POST /subscriptions (правила привязки и Subscription.Validate), и создаются пакетами (SubService.Batch) — ошибка строки не прерывает импорт,
+// а попадает в отчёт. Отчёты хранятся в памяти процесса. Фоновые импорты прерываются
+// и дожидаются в Shutdown.
+type ImportService struct {
+	subs appInterfaces.ISubService
+	// mapping — соответствие заголовков полям по умолчанию (IMPORT_MAPPING)
+	mapping   map[string]string
+	maxBytes  int64
+	syncLimit int64
+	maxErrors int
+	jobTTL    time.Duration
+	logger    *zerolog.Logger
+
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*importJob
+
+	// ctx отменяется в Shutdown; от него наследуются контексты фоновых импортов
+	ctx  context.Context
+	stop context.CancelCauseFunc
+	wg   sync.WaitGroup
+}
+
+var _ appInterfaces.IImportService = (*ImportService)(nil)
+
+func NewImportService(
+	subs appInterfaces.ISubService,
+	mapping map[string]string,
+	maxBytes int64,
+	syncLimit int64,
+	maxErrors int,
+	jobTTL time.Duration,
+	logger *zerolog.Logger) *ImportService {
+	ctx, stop := context.WithCancelCause(context.Background())
+	return &ImportService{
+		subs:      subs,
+		mapping:   mapping,
+		maxBytes:  maxBytes,
+		syncLimit: syncLimit,
+		maxErrors: maxErrors,
+		jobTTL:    jobTTL,
+		logger:    logger,
+		jobs:      make(map[uuid.UUID]*importJob),
+		ctx:       ctx,
+		stop:      stop,
+	}
+}
+
+// ParseMapping разбирает соответствие заголовков CSV полям подписки: "Заголовок=поле,...".
+// Заголовки сравниваются без учёта регистра; столбцы, названные как поля, сопоставляются и без соответствия.
+func ParseMapping(s string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		header, field, ok := strings.Cut(pair, "=")
+		header, field = normalizeHeader(header), strings.TrimSpace(field)
+		if !ok || header == "" || !slices.Contains(importFields, field) {
+			return nil, fmt.Errorf("%w: invalid mapping %q: expected header=field, fields: %s",
+				models.ErrInvalidArgument, pair, strings.Join(importFields, ", "))
+		}
+		mapping[header] = field
+	}
+	return mapping, nil
+}
+
+func normalizeHeader(header string) string {
+	// Excel начинает CSV в UTF-8 с BOM
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+}
+
+// importOptions — проверенные параметры импорта.
+type importOptions struct {
+	mapping   map[string]string
+	delimiter rune
+	dryRun    bool
+}
+
+func (s *ImportService) options(req dto.ImportRequest) (importOptions, error) {
+	opts := importOptions{mapping: s.mapping, delimiter: ',', dryRun: req.DryRun}
+	if req.Delimiter != "" {
+		r, size := utf8.DecodeRuneInString(req.Delimiter)
+		if size != len(req.Delimiter) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+			return opts, fmt.Errorf("%w: invalid delimiter %q", models.ErrInvalidArgument, req.Delimiter)
+		}
+		opts.delimiter = r
+	}
+	if req.Mapping != "" {
+		mapping, err := ParseMapping(req.Mapping)
+		if err != nil {
+			return opts, err
+		}
+		// Соответствие из запроса дополняет и переопределяет соответствие по умолчанию
+		opts.mapping = make(map[string]string, len(s.mapping)+len(mapping))
+		for header, field := range s.mapping {
+			opts.mapping[header] = field
+		}
+		for header, field := range mapping {
+			opts.mapping[header] = field
+		}
+	}
+	return opts, nil
+}
+
+// Start --- IMPORT ---
+// Сохраняет CSV из src во временный файл и импортирует его. Файлы не больше
+// IMPORT_SYNC_LIMIT импортируются до возврата, остальные — в фоне: возвращённый
+// отчёт имеет статус running, а ход импорта доступен через GetJob.
+func (s *ImportService) Start(ctx context.Context, src io.Reader, req dto.ImportRequest) (*dto.ImportJob, error) {
+	opts, err := s.options(req)
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Msg("Import: invalid options")
+		return nil, err
+	}
+
+	file, size, err := s.receive(src)
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Msg("Import: failed to receive file")
+		return nil, err
+	}
+
+	job := newImportJob(req, s.maxErrors)
+	if size <= s.syncLimit {
+		defer removeFile(file)
+		_ = s.register(job, false)
+		_ = s.run(ctx, job, file, size, opts)
+		return job.snapshot(), nil
+	}
+
+	if err := s.register(job, true); err != nil {
+		removeFile(file)
+		s.logger.Warn().
+			Err(err).
+			Msg("Import: rejected")
+		return nil, err
+	}
+	// Фоновый импорт переживает запрос, но сохраняет его инициатора для журнала аудита
+	bgCtx := models.WithAuditInfo(s.ctx, models.AuditInfoFrom(ctx))
+	go func() {
+		defer s.wg.Done()
+		defer removeFile(file)
+		_ = s.run(bgCtx, job, file, size, opts)
+	}()
+	return job.snapshot(), nil
+}
+
+// Shutdown прерывает фоновые импорты и ждёт их завершения, но не дольше, чем живёт ctx.
+// Подписки, созданные до остановки, остаются; отчёты прерванных импортов получают статус failed.
+func (s *ImportService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.stop(errImportStopped)
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background imports did not stop: %w", ctx.Err())
+	}
+}
+
+// receive сохраняет src во временный файл не больше IMPORT_MAX_BYTES и возвращает его размер.
+func (s *ImportService) receive(src io.Reader) (*os.File, int64, error) {
+	file, err := os.CreateTemp("", "subscriptions-import-*.csv")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create import file: %w", err)
+	}
+
+	size, err := io.Copy(file, io.LimitReader(src, s.maxBytes+1))
+	if err != nil {
+		err = fmt.Errorf("failed to receive import file: %w", err)
+	} else if size > s.maxBytes {
+		err = fmt.Errorf("%w: file is larger than %d bytes", models.ErrInvalidArgument, s.maxBytes)
+	} else if _, err = file.Seek(0, io.SeekStart); err != nil {
+		err = fmt.Errorf("failed to rewind import file: %w", err)
+	}
+	if err != nil {
+		removeFile(file)
+		return nil, 0, err
+	}
+	return file, size, nil
+}
+
+func removeFile(file *os.File) {
+	_ = file.Close()
+	_ = os.Remove(file.Name())
+}
+
+// Import импортирует CSV из src размером size байт (0 — размер неизвестен) и дожидается
+// завершения. Если импорт прерван, вместе с отчётом возвращается его причина.
+func (s *ImportService) Import(ctx context.Context, src io.Reader, size int64, req dto.ImportRequest) (*dto.ImportJob, error) {
+	opts, err := s.options(req)
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Msg("Import: invalid options")
+		return nil, err
+	}
+
+	job := newImportJob(req, s.maxErrors)
+	err = s.run(ctx, job, src, size, opts)
+	return job.snapshot(), err
+}
+
+// GetJob --- IMPORT JOB ---
+func (s *ImportService) GetJob(_ context.Context, id uuid.UUID) (*dto.ImportJob, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: import job %s", models.ErrNotFound, id)
+	}
+	return job.snapshot(), nil
+}
+
+// register сохраняет отчёт импорта, удаляя отчёты, завершённые раньше IMPORT_JOB_TTL.
+// Фоновый импорт (background) учитывается в s.wg; после Shutdown он не принимается.
+func (s *ImportService) register(job *importJob, background bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if background {
+		if s.ctx.Err() != nil {
+			return errImportStopped
+		}
+		s.wg.Add(1)
+	}
+
+	expired := time.Now().Add(-s.jobTTL)
+	for id, j := range s.jobs {
+		if j.finishedBefore(expired) {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[job.id] = job
+	return nil
+}
+
+func (s *ImportService) run(ctx context.Context, job *importJob, src io.Reader, size int64, opts importOptions) error {
+	s.logger.Info().
+		Str("jobId", job.id.String()).
+		Int64("bytes", size).
+		Bool("dryRun", opts.dryRun).
+		Msg("Import: started")
+
+	err := s.process(ctx, job, &countingReader{r: src}, size, opts)
+	job.finish(err)
+	result := job.snapshot()
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("jobId", job.id.String()).
+			Int64("rows", result.Rows).
+			Msg("Import: failed")
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	s.logger.Info().
+		Str("jobId", job.id.String()).
+		Int64("rows", result.Rows).
+		Int64("valid", result.Valid).
+		Int64("imported", result.Imported).
+		Int64("failed", result.Failed).
+		Bool("dryRun", opts.dryRun).
+		Msg("Import: completed")
+	return nil
+}
+
+func (s *ImportService) process(ctx context.Context, job *importJob, src *countingReader, size int64, opts importOptions) error {
+	reader := csv.NewReader(src)
+	reader.Comma = opts.delimiter
+	// Число столбцов не проверяется: недостающие значения считаются пустыми
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: file is empty", models.ErrInvalidArgument)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: invalid header: %v", models.ErrInvalidArgument, err)
+	}
+	columns, err := mapColumns(header, opts.mapping)
+	if err != nil {
+		return err
+	}
+
+	var chunk []importRow
+	for {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			job.row(src.n, size)
+			job.fail(parseErr.StartLine, models.NewValidationError("", "csv", parseErr.Err.Error()))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read csv: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		job.row(src.n, size)
+		req, err := parseRow(columns, record)
+		if err == nil {
+			err = validateRow(&req)
+		}
+		if err == nil {
+			_, err = newSubscription(req)
+		}
+		if err != nil {
+			job.fail(line, err)
+			continue
+		}
+		if opts.dryRun {
+			job.valid()
+			continue
+		}
+
+		chunk = append(chunk, importRow{line: line, req: req})
+		if len(chunk) == importChunkSize {
+			if err := s.flush(ctx, job, chunk); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+		}
+	}
+	return s.flush(ctx, job, chunk)
+}
+
+// importRow — проверенная строка CSV, ожидающая создания.
+type importRow struct {
+	line int
+	req  dto.CreateSubscriptionRequest
+}
+
+// flush создаёт подписки строк rows одним пакетом; ошибки отдельных строк попадают в отчёт.
+func (s *ImportService) flush(ctx context.Context, job *importJob, rows []importRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	ops := make([]dto.BatchOperation, len(rows))
+	for i := range rows {
+		ops[i] = dto.BatchOperation{Op: dto.BatchCreate, Subscription: dto.BatchSubscription{Create: &rows[i].req}}
+	}
+	results, err := s.subs.Batch(ctx, dto.BatchRequest{Operations: ops})
+	if err != nil {
+		return err
+	}
+
+	created := 0
+	for i, result := range results {
+		if result.Err != nil && ctx.Err() != nil {
+			// Строка не создана из-за прерывания импорта, а не из-за ошибки в ней
+			continue
+		}
+		if result.Err != nil {
+			// В отчёт попадает только категория ошибки, подробности — в журнал
+			s.logger.Debug().
+				Err(result.Err).
+				Str("jobId", job.id.String()).
+				Int("line", rows[i].line).
+				Msg("Import: row not created")
+			job.fail(rows[i].line, result.Err)
+			continue
+		}
+		created++
+	}
+	job.imported(created)
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return nil
+}
+
+// mapColumns сопоставляет столбцы заголовка полям подписки; "" — столбец не импортируется.
+func mapColumns(header []string, mapping map[string]string) ([]string, error) {
+	columns := make([]string, len(header))
+	seen := make(map[string]string, len(header))
+	for i, cell := range header {
+		name := normalizeHeader(cell)
+		field, ok := mapping[name]
+		if !ok && slices.Contains(importFields, name) {
+			field = name
+		}
+		if field == "" {
+			continue
+		}
+		if previous, ok := seen[field]; ok {
+			return nil, fmt.Errorf("%w: columns %q and %q both map to %s", models.ErrInvalidArgument, previous, cell, field)
+		}
+		seen[field] = cell
+		columns[i] = field
+	}
+
+	for _, field := range requiredImportFields {
+		if _, ok := seen[field]; !ok {
+			return nil, fmt.Errorf("%w: no column for required field %s", models.ErrInvalidArgument, field)
+		}
+	}
+	return columns, nil
+}
+
+// parseRow заполняет запрос на создание подписки значениями строки; пустые значения пропускаются.
+func parseRow(columns, record []string) (dto.CreateSubscriptionRequest, error) {
+	var req dto.CreateSubscriptionRequest
+	for i, field := range columns {
+		if field == "" || i >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+		if err := setField(&req, field, value); err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+// validateRow проверяет запрос правилами привязки CreateSubscriptionRequest, как POST /subscriptions,
+// чтобы проверка, dry_run и создание подписки отклоняли одни и те же строки.
+// Первое нарушение возвращается как models.ValidationError с именем поля.
+func validateRow(req *dto.CreateSubscriptionRequest) error {
+	err := binding.Validator.ValidateStruct(req)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) || len(fieldErrs) == 0 {
+		return err
+	}
+
+	fe := fieldErrs[0]
+	field := fe.StructField()
+	if sf, ok := reflect.TypeFor[dto.CreateSubscriptionRequest]().FieldByName(field); ok {
+		field, _, _ = strings.Cut(sf.Tag.Get("json"), ",")
+	}
+	rule := fe.Tag()
+	if fe.Param() != "" {
+		rule += "=" + fe.Param()
+	}
+	return models.NewValidationError(field, fe.Tag(), fmt.Sprintf("%s failed on the '%s' rule", field, rule))
+}
+
+func setField(req *dto.CreateSubscriptionRequest, field, value string) error {
+	var err error
+	switch field {
+	case "service_name":
+		req.ServiceName = value
+	case "price":
+		req.Price, err = strconv.ParseInt(value, 10, 64)
+	case "currency":
+		req.Currency = strings.ToUpper(value)
+	case "user_id":
+		req.UserID, err = uuid.Parse(value)
+	case "billing_interval":
+		req.BillingInterval = strings.ToLower(value)
+	case "interval_days":
+		req.IntervalDays, err = parseIntPtr(value)
+	case "state":
+		req.State = strings.ToLower(value)
+	case "trial_days":
+		req.TrialDays, err = parseIntPtr(value)
+	case "trial_end":
+		req.TrialEnd, err = parseDatePtr(value)
+	case "start_date":
+		req.StartDate, err = parseDate(value)
+	case "end_date":
+		req.EndDate, err = parseDatePtr(value)
+	}
+	if err != nil {
+		return models.NewValidationError(field, "format", fmt.Sprintf("invalid %s value %q", field, value))
+	}
+	return nil
+}
+
+func parseIntPtr(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format %q", value)
+}
+
+func parseDatePtr(value string) (*time.Time, error) {
+	t, err := parseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// newRowError переводит ошибку строки в запись отчёта так же, как обработчики API
+// переводят ошибки в ответ: сообщение models.ValidationError составлено сервисом и
+// попадает в отчёт, а для остальных ошибок — только категория. Их текст приходит
+// из хранилища и может содержать SQLSTATE и имена ограничений.
+func newRowError(line int, err error) dto.ImportRowError {
+	var fieldErr *models.ValidationError
+	if errors.As(err, &fieldErr) {
+		return dto.ImportRowError{Line: line, Category: dto.ImportErrorValidation, Field: fieldErr.Field, Message: fieldErr.Message}
+	}
+
+	rowErr := dto.ImportRowError{Line: line}
+	rowErr.Category, rowErr.Message = errorCategory(err)
+	return rowErr
+}
+
+// errorCategory возвращает категорию ошибки и общее сообщение о ней без текста самой ошибки.
+func errorCategory(err error) (string, string) {
+	switch {
+	case errors.Is(err, models.ErrValidation):
+		return dto.ImportErrorValidation, "subscription failed validation"
+	case errors.Is(err, models.ErrInvalidArgument):
+		return dto.ImportErrorInvalidArgument, "invalid value"
+	case errors.Is(err, models.ErrNotFound):
+		return dto.ImportErrorNotFound, "referenced resource not found"
+	case errors.Is(err, models.ErrConflict):
+		return dto.ImportErrorConflict, "subscription conflicts with existing data"
+	case errors.Is(err, models.ErrPreconditionFailed):
+		return dto.ImportErrorPrecondition, "precondition failed"
+	case errors.Is(err, models.ErrUnavailable):
+		return dto.ImportErrorUnavailable, "service temporarily unavailable"
+	default:
+		return dto.ImportErrorInternal, "internal error"
+	}
+}
+
+// jobError возвращает категорию и сообщение причины, по которой прерван импорт.
+// Текст ошибок разбора файла и остановки сервиса составлен сервисом и попадает в отчёт,
+// для остальных ошибок — только категория; сама ошибка записывается в журнал (run).
+func jobError(err error) (string, string) {
+	switch {
+	case errors.Is(err, models.ErrInvalidArgument):
+		return dto.ImportErrorInvalidArgument, err.Error()
+	case errors.Is(err, errImportStopped):
+		return dto.ImportErrorUnavailable, err.Error()
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return dto.ImportErrorUnavailable, "import cancelled"
+	}
+	return errorCategory(err)
+}
+
+// countingReader считает прочитанные байты для оценки хода импорта.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// importJob — отчёт импорта, который обновляется во время импорта и читается параллельно.
+type importJob struct {
+	id        uuid.UUID
+	maxErrors int
+
+	mu  sync.Mutex
+	job dto.ImportJob
+}
+
+func newImportJob(req dto.ImportRequest, maxErrors int) *importJob {
+	id := uuid.New()
+	return &importJob{
+		id:        id,
+		maxErrors: maxErrors,
+		job: dto.ImportJob{
+			Id:        id,
+			Status:    dto.ImportRunning,
+			DryRun:    req.DryRun,
+			Errors:    []dto.ImportRowError{},
+			StartedAt: time.Now(),
+		},
+	}
+}
+
+// row учитывает прочитанную строку; read из size байт файла уже прочитано.
+func (j *importJob) row(read, size int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.job.Rows++
+	if size > 0 {
+		j.job.Progress = min(float64(read)/float64(size), 1)
+	}
+}
+
+// valid учитывает строку, прошедшую проверку при dry_run.
+func (j *importJob) valid() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.job.Valid++
+}
+
+func (j *importJob) imported(n int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.job.Imported += int64(n)
+}
+
+// fail записывает ошибку строки line; в отчёт попадают первые maxErrors ошибок.
+func (j *importJob) fail(line int, err error) {
+	rowErr := newRowError(line, err)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.job.Failed++
+	if len(j.job.Errors) < j.maxErrors {
+		j.job.Errors = append(j.job.Errors, rowErr)
+	} else {
+		j.job.ErrorsTruncated = true
+	}
+}
+
+func (j *importJob) finish(err error) {
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.job.FinishedAt = &now
+	if err != nil {
+		j.job.Status = dto.ImportFailed
+		j.job.ErrorCategory, j.job.Error = jobError(err)
+		return
+	}
+	j.job.Status = dto.ImportCompleted
+	j.job.Progress = 1
+}
+
+func (j *importJob) finishedBefore(t time.Time) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.job.FinishedAt != nil && j.job.FinishedAt.Before(t)
+}
+
+// snapshot возвращает копию отчёта.
+func (j *importJob) snapshot() *dto.ImportJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job := j.job
+	job.Errors = slices.Clone(j.job.Errors)
+	return &job
+}
@@ -0,0 +1,380 @@
+package services_test
+
+import (
+	"SubscriptionService/internal/api/dto"
+	appInterfaces "SubscriptionService/internal/application/app_interfaces"
+	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"SubscriptionService/internal/persistence/memory"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const importUser = "7b1f6a52-63b5-4a4b-8a51-6a1d8a0f0c11"
+
+// newImportService возвращает сервис импорта над хранилищем в памяти.
+func newImportService(t *testing.T, mapping map[string]string, maxErrors int) (*services.ImportService, *memory.SubRepository) {
+	t.Helper()
+	logger := zerolog.Nop()
+	subs, repo := newSubService(t)
+	return services.NewImportService(subs, mapping, 1<<20, 1<<20, maxErrors, time.Hour, &logger), repo
+}
+
+// importCSV импортирует строки lines, соединённые переводами строк.
+func importCSV(service *services.ImportService, req dto.ImportRequest, lines ...string) (*dto.ImportJob, error) {
+	csv := strings.Join(lines, "\n") + "\n"
+	return service.Import(ctx, strings.NewReader(csv), int64(len(csv)), req)
+}
+
+func TestImportMapsColumns(t *testing.T) {
+	defaults := map[string]string{"сервис": "service_name"}
+	tests := []struct {
+		name    string
+		header  string
+		row     string
+		mapping string
+		wantErr string
+	}{
+		{name: "field names", header: "service_name,price,user_id,start_date", row: "Netflix,400," + importUser + ",2024-01-01"},
+		{name: "case and spaces", header: " Service_Name ,PRICE,User_Id,start_date", row: "Netflix,400," + importUser + ",2024-01-01"},
+		{name: "byte order mark", header: "\ufeffservice_name,price,user_id,start_date", row: "Netflix,400," + importUser + ",2024-01-01"},
+		{name: "unknown column", header: "service_name,price,user_id,start_date,comment", row: "Netflix,400," + importUser + ",2024-01-01,note"},
+		{name: "default mapping", header: "Сервис,price,user_id,start_date", row: "Netflix,400," + importUser + ",2024-01-01"},
+		{
+			name:    "request mapping",
+			header:  "Сервис,Стоимость,Пользователь,Начало",
+			row:     "Netflix,400," + importUser + ",2024-01-01",
+			mapping: "Стоимость=price, Пользователь=user_id,Начало=start_date",
+		},
+		{name: "duplicate column", header: "service_name,price,Price,user_id,start_date", wantErr: "both map to price"},
+		{name: "duplicate mapping", header: "service_name,price,Cost,user_id,start_date", mapping: "Cost=price", wantErr: "both map to price"},
+		{name: "missing column", header: "service_name,price,start_date", wantErr: "no column for required field user_id"},
+		{name: "empty file", wantErr: "file is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newImportService(t, defaults, 100)
+			var lines []string
+			if tt.header != "" {
+				lines = append(lines, tt.header, tt.row)
+			}
+			job, err := importCSV(service, dto.ImportRequest{DryRun: true, Mapping: tt.mapping}, lines...)
+
+			if tt.wantErr != "" {
+				if !errors.Is(err, models.ErrInvalidArgument) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want ErrInvalidArgument with %q", err, tt.wantErr)
+				}
+				if job.Status != dto.ImportFailed {
+					t.Errorf("status = %q, want %q", job.Status, dto.ImportFailed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if job.Valid != 1 || job.Failed != 0 {
+				t.Errorf("valid %d, failed %d, errors %+v; want 1 and 0", job.Valid, job.Failed, job.Errors)
+			}
+		})
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := services.ParseMapping(" Сервис = service_name ,,Cost=price")
+	if err != nil {
+		t.Fatalf("ParseMapping: %v", err)
+	}
+	if len(mapping) != 2 || mapping["сервис"] != "service_name" || mapping["cost"] != "price" {
+		t.Errorf("mapping = %v", mapping)
+	}
+
+	for _, s := range []string{"Сервис", "=price", "Cost=amount"} {
+		if _, err := services.ParseMapping(s); !errors.Is(err, models.ErrInvalidArgument) {
+			t.Errorf("ParseMapping(%q): got %v, want ErrInvalidArgument", s, err)
+		}
+	}
+}
+
+func TestImportParsesDates(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-03-01T00:00:00Z", date(2024, time.March, 1)},
+		{"2024-03-01", date(2024, time.March, 1)},
+		{"01.03.2024", date(2024, time.March, 1)},
+		{"03-2024", date(2024, time.March, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			service, repo := newImportService(t, nil, 100)
+			job, err := importCSV(service, dto.ImportRequest{},
+				"service_name,price,user_id,start_date",
+				"Netflix,400,"+importUser+","+tt.value)
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if job.Imported != 1 {
+				t.Fatalf("imported = %d, errors %+v", job.Imported, job.Errors)
+			}
+
+			subs, err := repo.GetAll(ctx, &filters.SubFilter{}, 1, 10)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			if len(subs) != 1 || !subs[0].StartDate.Equal(tt.want) {
+				t.Errorf("start date = %v, want %s", subs, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportDryRunCountsRows(t *testing.T) {
+	service, repo := newImportService(t, nil, 100)
+	job, err := importCSV(service, dto.ImportRequest{DryRun: true, Delimiter: ";"},
+		"service_name;price;user_id;start_date",
+		"Netflix;400;"+importUser+";2024-01-01",
+		"Spotify;abc;"+importUser+";2024-01-01",
+		"YouTube;200;"+importUser+";01.02.2024",
+		"Kinopoisk;300;"+importUser+";2024/01/01",
+		"Okko;300;;2024-01-01",
+	)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if job.Status != dto.ImportCompleted || job.Rows != 5 || job.Valid != 2 || job.Imported != 0 || job.Failed != 3 {
+		t.Errorf("status %s, rows %d, valid %d, imported %d, failed %d; want completed, 5, 2, 0, 3",
+			job.Status, job.Rows, job.Valid, job.Imported, job.Failed)
+	}
+
+	want := []dto.ImportRowError{
+		{Line: 3, Category: dto.ImportErrorValidation, Field: "price"},
+		{Line: 5, Category: dto.ImportErrorValidation, Field: "start_date"},
+		{Line: 6, Category: dto.ImportErrorValidation, Field: "user_id"},
+	}
+	if len(job.Errors) != len(want) {
+		t.Fatalf("errors = %+v, want %d", job.Errors, len(want))
+	}
+	for i, got := range job.Errors {
+		if got.Line != want[i].Line || got.Category != want[i].Category || got.Field != want[i].Field {
+			t.Errorf("error %d = %+v, want line %d, %s, field %s", i, got, want[i].Line, want[i].Category, want[i].Field)
+		}
+	}
+
+	subs, err := repo.GetAll(ctx, &filters.SubFilter{}, 1, 10)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(subs) != 0 {
+		t.Errorf("dry run created %d subscriptions", len(subs))
+	}
+}
+
+func TestImportCapsErrors(t *testing.T) {
+	service, _ := newImportService(t, nil, 2)
+	lines := []string{"service_name,price,user_id,start_date"}
+	for range 5 {
+		lines = append(lines, "Netflix,0,"+importUser+",2024-01-01")
+	}
+	lines = append(lines, "Netflix,400,"+importUser+",2024-01-01")
+
+	job, err := importCSV(service, dto.ImportRequest{}, lines...)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if job.Failed != 5 || job.Imported != 1 {
+		t.Errorf("failed %d, imported %d; want 5 and 1", job.Failed, job.Imported)
+	}
+	if len(job.Errors) != 2 || !job.ErrorsTruncated {
+		t.Errorf("errors %d, truncated %t; want 2 and true", len(job.Errors), job.ErrorsTruncated)
+	}
+}
+
+func TestImportValidatesRowsLikeAPI(t *testing.T) {
+	lines := []string{
+		"service_name,price,user_id,start_date,currency",
+		"x,400," + importUser + ",2024-01-01,",
+		strings.Repeat("x", 101) + ",400," + importUser + ",2024-01-01,",
+		"Netflix,400," + importUser + ",2024-01-01,RUBX",
+		"Netflix,400," + importUser + ",2024-01-01,",
+	}
+	want := []dto.ImportRowError{
+		{Line: 2, Category: dto.ImportErrorValidation, Field: "service_name"},
+		{Line: 3, Category: dto.ImportErrorValidation, Field: "service_name"},
+		{Line: 4, Category: dto.ImportErrorValidation, Field: "currency"},
+	}
+
+	// Проверка и импорт отклоняют одни и те же строки с одними и теми же полями
+	for _, dryRun := range []bool{true, false} {
+		service, repo := newImportService(t, nil, 100)
+		job, err := importCSV(service, dto.ImportRequest{DryRun: dryRun}, lines...)
+		if err != nil {
+			t.Fatalf("dry run %t: Import: %v", dryRun, err)
+		}
+		if len(job.Errors) != len(want) {
+			t.Fatalf("dry run %t: errors = %+v, want %d", dryRun, job.Errors, len(want))
+		}
+		for i, got := range job.Errors {
+			if got.Line != want[i].Line || got.Category != want[i].Category || got.Field != want[i].Field {
+				t.Errorf("dry run %t: error %d = %+v, want line %d, field %s", dryRun, i, got, want[i].Line, want[i].Field)
+			}
+		}
+
+		subs, err := repo.GetAll(ctx, &filters.SubFilter{}, 1, 10)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if created := int64(len(subs)); created != job.Imported || job.Valid+job.Imported != 1 {
+			t.Errorf("dry run %t: valid %d, imported %d, created %d", dryRun, job.Valid, job.Imported, created)
+		}
+	}
+}
+
+// rawErrorSubs — сервис подписок, который отклоняет каждую операцию пакета ошибкой
+// хранилища с текстом драйвера, а при whole — весь пакет.
+type rawErrorSubs struct {
+	appInterfaces.ISubService
+	err   error
+	whole bool
+}
+
+func (s rawErrorSubs) Batch(_ context.Context, req dto.BatchRequest) ([]*dto.BatchResult, error) {
+	if s.whole {
+		return nil, s.err
+	}
+	results := make([]*dto.BatchResult, len(req.Operations))
+	for i, op := range req.Operations {
+		results[i] = &dto.BatchResult{Index: i, Op: op.Op, Err: s.err}
+	}
+	return results, nil
+}
+
+func TestImportHidesStorageErrors(t *testing.T) {
+	logger := zerolog.Nop()
+	tests := []struct {
+		err      error
+		category string
+	}{
+		{fmt.Errorf("insert subscription: %w: ERROR: duplicate key value violates unique constraint \"subscriptions_pkey\" (SQLSTATE 23505)", models.ErrConflict), dto.ImportErrorConflict},
+		{fmt.Errorf("insert subscription: %w: ERROR: new row violates check constraint \"price_positive\" (SQLSTATE 23514)", models.ErrValidation), dto.ImportErrorValidation},
+		{fmt.Errorf("insert subscription: %w: dial tcp 10.0.0.5:5432: connection refused", models.ErrUnavailable), dto.ImportErrorUnavailable},
+		{errors.New("insert subscription: ERROR: relation \"subscriptions\" does not exist (SQLSTATE 42P01)"), dto.ImportErrorInternal},
+	}
+	for _, tt := range tests {
+		service := services.NewImportService(rawErrorSubs{err: tt.err}, nil, 1<<20, 1<<20, 100, time.Hour, &logger)
+		job, err := importCSV(service, dto.ImportRequest{},
+			"service_name,price,user_id,start_date",
+			"Netflix,400,"+importUser+",2024-01-01")
+		if err != nil {
+			t.Fatalf("Import: %v", err)
+		}
+		if len(job.Errors) != 1 {
+			t.Fatalf("errors = %+v, want 1", job.Errors)
+		}
+		got := job.Errors[0]
+		if got.Category != tt.category || got.Field != "" || strings.Contains(got.Message, "SQLSTATE") ||
+			strings.Contains(got.Message, "insert subscription") || strings.Contains(got.Message, "10.0.0.5") {
+			t.Errorf("row error = %+v, want category %s without storage details", got, tt.category)
+		}
+	}
+}
+
+func TestImportHidesJobStorageErrors(t *testing.T) {
+	logger := zerolog.Nop()
+	storageErr := fmt.Errorf("begin tx: %w: dial tcp 10.0.0.5:5432: connection refused (SQLSTATE 08006)", models.ErrUnavailable)
+	service := services.NewImportService(rawErrorSubs{err: storageErr, whole: true}, nil, 1<<20, 1<<20, 100, time.Hour, &logger)
+
+	job, err := importCSV(service, dto.ImportRequest{},
+		"service_name,price,user_id,start_date",
+		"Netflix,400,"+importUser+",2024-01-01")
+	if !errors.Is(err, models.ErrUnavailable) {
+		t.Fatalf("Import: got %v, want ErrUnavailable", err)
+	}
+	if job.Status != dto.ImportFailed || job.ErrorCategory != dto.ImportErrorUnavailable {
+		t.Errorf("job status %q, category %q; want failed and %s", job.Status, job.ErrorCategory, dto.ImportErrorUnavailable)
+	}
+	if strings.Contains(job.Error, "SQLSTATE") || strings.Contains(job.Error, "10.0.0.5") || strings.Contains(job.Error, "begin tx") {
+		t.Errorf("job error %q exposes storage details", job.Error)
+	}
+
+	// Ошибки разбора файла составлены сервисом и остаются в отчёте
+	job, _ = importCSV(service, dto.ImportRequest{}, "service_name,price,start_date")
+	if job.Status != dto.ImportFailed || job.ErrorCategory != dto.ImportErrorInvalidArgument || !strings.Contains(job.Error, "user_id") {
+		t.Errorf("job status %q, category %q, error %q; want failed on missing user_id column", job.Status, job.ErrorCategory, job.Error)
+	}
+}
+
+// blockingSubs — сервис подписок, пакет которого выполняется до отмены контекста.
+type blockingSubs struct {
+	appInterfaces.ISubService
+	started chan struct{}
+}
+
+func (s *blockingSubs) Batch(ctx context.Context, req dto.BatchRequest) ([]*dto.BatchResult, error) {
+	close(s.started)
+	<-ctx.Done()
+	results := make([]*dto.BatchResult, len(req.Operations))
+	for i, op := range req.Operations {
+		results[i] = &dto.BatchResult{Index: i, Op: op.Op, Err: ctx.Err()}
+	}
+	return results, nil
+}
+
+func TestImportShutdownStopsBackgroundImports(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	logger := zerolog.Nop()
+	subs := &blockingSubs{started: make(chan struct{})}
+	// Нулевой IMPORT_SYNC_LIMIT: любой файл импортируется в фоне
+	service := services.NewImportService(subs, nil, 1<<20, 0, 100, time.Hour, &logger)
+
+	csv := "service_name,price,user_id,start_date\nNetflix,400,7b1f6a52-63b5-4a4b-8a51-6a1d8a0f0c11,2024-01-01\n"
+	job, err := service.Start(ctx, strings.NewReader(csv), dto.ImportRequest{})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	select {
+	case <-subs.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("background import did not start")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := service.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	report, err := service.GetJob(ctx, job.Id)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if report.Status != dto.ImportFailed || report.ErrorCategory != dto.ImportErrorUnavailable ||
+		!strings.Contains(report.Error, "shutting down") {
+		t.Errorf("job status %q, error %q, want failed on shutdown", report.Status, report.Error)
+	}
+	// Строки, прерванные остановкой, не считаются ошибочными
+	if report.Failed != 0 {
+		t.Errorf("failed = %d, want 0", report.Failed)
+	}
+	if files, _ := filepath.Glob(filepath.Join(tmp, "subscriptions-import-*")); len(files) != 0 {
+		t.Errorf("import files left: %v", files)
+	}
+
+	_, err = service.Start(ctx, strings.NewReader(csv), dto.ImportRequest{})
+	if !errors.Is(err, models.ErrUnavailable) {
+		t.Errorf("Start after Shutdown: got %v, want ErrUnavailable", err)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Errorf("rejected import left files in %s", tmp)
+	}
+}
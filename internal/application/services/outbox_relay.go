@@ -0,0 +1,66 @@
+package services
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Сколько сообщений outbox передаётся в брокер за одну транзакцию
+const outboxBatchSize = 100
+
+// OutboxRelay публикует изменения подписок из outbox в брокер сообщений как CloudEvents.
+// Доставка at-least-once: потребители различают повторы по идентификатору CloudEvent.
+type OutboxRelay struct {
+	repo      core_interfaces.IOutboxRepository
+	publisher core_interfaces.IEventPublisher
+	// source — атрибут source публикуемых событий
+	source string
+	logger *zerolog.Logger
+}
+
+func NewOutboxRelay(
+	repo core_interfaces.IOutboxRepository,
+	publisher core_interfaces.IEventPublisher,
+	source string,
+	logger *zerolog.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		source:    source,
+		logger:    logger,
+	}
+}
+
+// DispatchDue публикует все накопившиеся сообщения. При ошибке брокера проход
+// прерывается, а неопубликованные сообщения повторяются следующим проходом в том же порядке.
+func (r *OutboxRelay) DispatchDue(ctx context.Context, _ time.Time) (int, error) {
+	published := 0
+	for {
+		n, err := r.repo.Relay(ctx, outboxBatchSize, func(msg *models.OutboxMessage) error {
+			return r.publisher.Publish(ctx, models.NewCloudEvent(msg, r.source))
+		})
+		published += n
+		if err != nil {
+			r.logger.Error().
+				Err(err).
+				Int("published", published).
+				Msg("Relay outbox: publish failed")
+			return published, fmt.Errorf("failed to relay outbox: %w", err)
+		}
+		if n < outboxBatchSize {
+			break
+		}
+	}
+
+	if published > 0 {
+		r.logger.Info().
+			Int("published", published).
+			Msg("Subscription events published")
+	}
+	return published, nil
+}
@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/broker"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// outboxQueue — outbox в памяти: Relay передаёт сообщения по порядку, удаляет
+// опубликованные и останавливается на первой ошибке publish, как хранилище.
+type outboxQueue struct {
+	messages []*models.OutboxMessage
+}
+
+func (q *outboxQueue) Relay(_ context.Context, limit int, publish func(msg *models.OutboxMessage) error) (int, error) {
+	published := 0
+	for _, msg := range q.messages[:min(limit, len(q.messages))] {
+		if err := publish(msg); err != nil {
+			q.messages = q.messages[published:]
+			return published, err
+		}
+		published++
+	}
+	q.messages = q.messages[published:]
+	return published, nil
+}
+
+// flakyPublisher отказывает в публикации с номером failAt, остальные передаёт MemoryPublisher.
+type flakyPublisher struct {
+	*broker.MemoryPublisher
+	calls  int
+	failAt int
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, event *models.CloudEvent) error {
+	p.calls++
+	if p.calls == p.failAt {
+		return errors.New("nats: no responders available")
+	}
+	return p.MemoryPublisher.Publish(ctx, event)
+}
+
+func TestOutboxRelayResumesInOrderAfterPublishError(t *testing.T) {
+	logger := zerolog.Nop()
+	subs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	queue := &outboxQueue{}
+	// Больше двух пачек, чтобы проход запрашивал следующую
+	const total = 250
+	for i := range total {
+		queue.messages = append(queue.messages, &models.OutboxMessage{
+			Id:             int64(i + 1),
+			EventId:        uuid.New(),
+			SubscriptionId: subs[i%len(subs)],
+			Operation:      models.OperationUpdate,
+			Data:           json.RawMessage(`{}`),
+			OccurredAt:     date(2024, time.March, 1),
+		})
+	}
+	publisher := &flakyPublisher{MemoryPublisher: broker.NewMemoryPublisher(0), failAt: 130}
+	relay := services.NewOutboxRelay(queue, publisher, "/subscription-service", &logger)
+
+	published, err := relay.DispatchDue(ctx, time.Now())
+	if err == nil {
+		t.Fatal("DispatchDue: want error from the broker")
+	}
+	if published != 129 || len(publisher.Events()) != 129 {
+		t.Fatalf("published %d, broker has %d; want 129", published, len(publisher.Events()))
+	}
+
+	// Следующий проход продолжает с неопубликованного сообщения
+	published, err = relay.DispatchDue(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+	if published != total-129 || len(queue.messages) != 0 {
+		t.Fatalf("published %d, left %d; want %d and 0", published, len(queue.messages), total-129)
+	}
+
+	events := publisher.Events()
+	if len(events) != total {
+		t.Fatalf("broker has %d events, want %d", len(events), total)
+	}
+	for i, event := range events {
+		if event.Sequence != strconv.Itoa(i+1) {
+			t.Fatalf("event %d has sequence %s: order is broken or duplicated", i, event.Sequence)
+		}
+		if event.PartitionKey != subs[i%len(subs)].String() || event.Source != "/subscription-service" ||
+			event.Type != models.EventType(models.OperationUpdate) {
+			t.Fatalf("event %d: %+v", i, event)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	appInterfaces "SubscriptionService/internal/application/app_interfaces"
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Сколько напоминаний захватывается за раз и на сколько
+const (
+	reminderBatchSize = 50
+	reminderLease     = 5 * time.Minute
+)
+
+// Горизонт по умолчанию для просмотра предстоящих событий
+const defaultUpcomingDays = 30
+
+type ReminderService struct {
+	repo     core_interfaces.IReminderRepository
+	notifier core_interfaces.INotifier
+	// lead — за сколько до события отправляется напоминание
+	lead   time.Duration
+	logger *zerolog.Logger
+}
+
+var _ appInterfaces.IReminderService = (*ReminderService)(nil)
+
+func NewReminderService(
+	repo core_interfaces.IReminderRepository,
+	notifier core_interfaces.INotifier,
+	lead time.Duration,
+	logger *zerolog.Logger) *ReminderService {
+	return &ReminderService{
+		repo:     repo,
+		notifier: notifier,
+		lead:     lead,
+		logger:   logger,
+	}
+}
+
+func (s *ReminderService) Upcoming(ctx context.Context, userID uuid.UUID, req dto.UpcomingQueryRequest) (dto.UpcomingResponse, error) {
+	days := req.WithinDays
+	if days == 0 {
+		days = defaultUpcomingDays
+	}
+	now := time.Now()
+
+	reminders, err := s.repo.GetUpcoming(ctx, userID, now, now.AddDate(0, 0, days))
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("userId", userID.String()).
+			Msg("Failed to get upcoming events")
+		return dto.UpcomingResponse{}, fmt.Errorf("failed to get upcoming events: %w", err)
+	}
+	for _, reminder := range reminders {
+		reminder.RemindAt = reminder.DueAt.Add(-s.lead)
+	}
+
+	s.logger.Info().
+		Str("userId", userID.String()).
+		Int("events", len(reminders)).
+		Msg("Upcoming events retrieved successfully")
+	return dto.UpcomingResponse{Data: reminders, Lead: s.lead.String()}, nil
+}
+
+// DispatchDue сохраняет напоминания о событиях в пределах срока напоминания и отправляет их.
+// Каждое напоминание отправляется один раз: отправленное отмечается в БД,
+// а неудачное откладывается с экспоненциальной задержкой (models.ReminderRetryDelay).
+func (s *ReminderService) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	if _, err := s.repo.Enqueue(ctx, now, now.Add(s.lead)); err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("Dispatch reminders: enqueue failed")
+		return 0, fmt.Errorf("failed to enqueue reminders: %w", err)
+	}
+
+	sent := 0
+	// Напоминание, снова захваченное в этом проходе, не отправляется повторно:
+	// проход заканчивается, когда новых напоминаний не осталось
+	seen := make(map[int64]bool)
+	for {
+		reminders, err := s.repo.Claim(ctx, now, reminderBatchSize, reminderLease)
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Msg("Dispatch reminders: claim failed")
+			return sent, fmt.Errorf("failed to claim reminders: %w", err)
+		}
+
+		fresh := 0
+		for _, reminder := range reminders {
+			if seen[reminder.Id] {
+				continue
+			}
+			seen[reminder.Id] = true
+			fresh++
+
+			reminder.RemindAt = reminder.DueAt.Add(-s.lead)
+			if err := s.notifier.Notify(ctx, reminder); err != nil {
+				retryAt := now.Add(models.ReminderRetryDelay(reminder.Attempts))
+				s.logger.Warn().
+					Err(err).
+					Str("subscriptionId", reminder.SubscriptionId.String()).
+					Str("kind", string(reminder.Kind)).
+					Int("attempt", reminder.Attempts).
+					Time("retryAt", retryAt).
+					Msg("Dispatch reminders: notify failed")
+				if err := s.repo.MarkFailed(ctx, reminder.Id, err.Error(), retryAt); err != nil {
+					return sent, fmt.Errorf("failed to mark reminder failed: %w", err)
+				}
+				continue
+			}
+			if err := s.repo.MarkSent(ctx, reminder.Id, time.Now()); err != nil {
+				return sent, fmt.Errorf("failed to mark reminder sent: %w", err)
+			}
+			sent++
+		}
+
+		if len(reminders) < reminderBatchSize || fresh == 0 {
+			break
+		}
+	}
+
+	if sent > 0 {
+		s.logger.Info().
+			Int("sent", sent).
+			Msg("Reminders sent")
+	}
+	return sent, nil
+}
@@ -0,0 +1,114 @@
+package services_test
+
+import (
+	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// stuckReminders — хранилище, которое на каждый Claim возвращает одни и те же
+// напоминания, как если бы их захват истёк сразу после неудачи.
+type stuckReminders struct {
+	reminders []*models.Reminder
+	claims    int
+	retryAt   map[int64]time.Time
+}
+
+func (r *stuckReminders) GetUpcoming(context.Context, uuid.UUID, time.Time, time.Time) ([]*models.Reminder, error) {
+	return nil, nil
+}
+
+func (r *stuckReminders) Enqueue(context.Context, time.Time, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *stuckReminders) Claim(_ context.Context, _ time.Time, limit int, _ time.Duration) ([]*models.Reminder, error) {
+	r.claims++
+	claimed := make([]*models.Reminder, 0, limit)
+	for _, reminder := range r.reminders[:min(limit, len(r.reminders))] {
+		copied := *reminder
+		copied.Attempts = r.claims
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (r *stuckReminders) MarkSent(context.Context, int64, time.Time) error {
+	return nil
+}
+
+func (r *stuckReminders) MarkFailed(_ context.Context, id int64, _ string, retryAt time.Time) error {
+	r.retryAt[id] = retryAt
+	return nil
+}
+
+type failingNotifier struct {
+	calls int
+}
+
+func (n *failingNotifier) Notify(context.Context, *models.Reminder) error {
+	n.calls++
+	return errors.New("smtp: connection refused")
+}
+
+func TestDispatchDueBacksOffFailedReminders(t *testing.T) {
+	logger := zerolog.Nop()
+	// Полная пачка, чтобы проход запросил следующую
+	repo := &stuckReminders{retryAt: make(map[int64]time.Time)}
+	for i := range 50 {
+		repo.reminders = append(repo.reminders, &models.Reminder{
+			Id:             int64(i + 1),
+			SubscriptionId: uuid.New(),
+			Kind:           models.ReminderRenewal,
+			DueAt:          date(2024, time.March, 1),
+		})
+	}
+	notifier := &failingNotifier{}
+	service := services.NewReminderService(repo, notifier, 72*time.Hour, &logger)
+
+	now := date(2024, time.February, 27)
+	sent, err := service.DispatchDue(ctx, now)
+	if err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+	if sent != 0 {
+		t.Errorf("sent = %d, want 0", sent)
+	}
+	// Повторно захваченные напоминания в том же проходе не отправляются
+	if notifier.calls != len(repo.reminders) {
+		t.Errorf("notify calls = %d, want %d", notifier.calls, len(repo.reminders))
+	}
+	if repo.claims != 2 {
+		t.Errorf("claims = %d, want 2", repo.claims)
+	}
+	want := now.Add(models.ReminderRetryDelay(1))
+	for _, reminder := range repo.reminders {
+		if got := repo.retryAt[reminder.Id]; !got.Equal(want) {
+			t.Fatalf("reminder %d retry at %s, want %s", reminder.Id, got, want)
+		}
+	}
+}
+
+func TestReminderRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{7, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := models.ReminderRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("ReminderRetryDelay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/application/events"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Batch выполняет операции пакетного запроса по порядку. В атомарном режиме (req.Atomic)
+// все операции выполняются в одной транзакции: первая ошибка отменяет весь пакет и
+// возвращается с номером операции. Иначе каждая операция выполняется отдельно,
+// а её ошибка возвращается в результате (BatchResult.Err).
+// Подряд идущие создания сохраняются одним вызовом CreateMany.
+func (s *SubService) Batch(ctx context.Context, req dto.BatchRequest) ([]*dto.BatchResult, error) {
+	s.logger.Debug().
+		Int("operations", len(req.Operations)).
+		Bool("atomic", req.Atomic).
+		Msg("Batch: started")
+
+	var run *batchRun
+	var err error
+	if req.Atomic {
+		err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			// При повторе транзакции пакет выполняется заново
+			run = newBatchRun(s, len(req.Operations), true)
+			return run.execute(ctx, req.Operations)
+		})
+	} else {
+		run = newBatchRun(s, len(req.Operations), false)
+		err = run.execute(ctx, req.Operations)
+	}
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Msg("Batch: aborted")
+		return nil, err
+	}
+
+	// События публикуются только после фиксации изменений
+	failed := run.publish(ctx)
+	s.logger.Info().
+		Int("operations", len(req.Operations)).
+		Int("failed", failed).
+		Bool("atomic", req.Atomic).
+		Msg("Batch: completed")
+	return run.results, nil
+}
+
+// batchRun — выполнение пакетного запроса.
+type batchRun struct {
+	s      *SubService
+	atomic bool
+	// results и before — результаты операций и состояния подписок до замены (для событий)
+	results []*dto.BatchResult
+	before  []*models.Subscription
+}
+
+func newBatchRun(s *SubService, n int, atomic bool) *batchRun {
+	return &batchRun{
+		s:       s,
+		atomic:  atomic,
+		results: make([]*dto.BatchResult, n),
+		before:  make([]*models.Subscription, n),
+	}
+}
+
+func (r *batchRun) execute(ctx context.Context, ops []dto.BatchOperation) error {
+	for i := 0; i < len(ops); {
+		if ops[i].Op != dto.BatchCreate {
+			if err := r.modify(ctx, i, ops[i]); err != nil {
+				return err
+			}
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(ops) && ops[end].Op == dto.BatchCreate {
+			end++
+		}
+		if err := r.create(ctx, i, ops[i:end]); err != nil {
+			return err
+		}
+		i = end
+	}
+	return nil
+}
+
+// done записывает результат операции i; в атомарном режиме её ошибка прерывает пакет.
+func (r *batchRun) done(i int, op dto.BatchOperation, sub *models.Subscription, err error) error {
+	result := &dto.BatchResult{Index: i, Op: op.Op, ID: op.ID, Subscription: sub, Err: err}
+	if sub != nil {
+		result.ID = &sub.Id
+	}
+	r.results[i] = result
+
+	if err != nil && r.atomic {
+		return fmt.Errorf("operation %d: %w", i, err)
+	}
+	return nil
+}
+
+// create выполняет подряд идущие создания ops, первое из которых — операция first.
+func (r *batchRun) create(ctx context.Context, first int, ops []dto.BatchOperation) error {
+	var subs []*models.Subscription
+	var index []int
+	for k, op := range ops {
+		sub, err := newSubscription(*op.Subscription.Create)
+		if err != nil {
+			if err := r.done(first+k, op, nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+		subs = append(subs, sub)
+		index = append(index, first+k)
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	created, err := r.s.repo.CreateMany(ctx, subs)
+	if err == nil {
+		for k, sub := range created {
+			_ = r.done(index[k], ops[index[k]-first], sub, nil)
+		}
+		return nil
+	}
+	if r.atomic {
+		return fmt.Errorf("operations %d-%d: failed to create subscriptions: %w", index[0], index[len(index)-1], err)
+	}
+
+	// Ошибку вставки относим к конкретным операциям, создавая подписки по одной
+	r.s.logger.Warn().
+		Err(err).
+		Int("subscriptions", len(subs)).
+		Msg("Batch: bulk insert failed, creating one by one")
+	for k, sub := range subs {
+		created, err := r.s.repo.Create(ctx, sub)
+		if err != nil {
+			err = fmt.Errorf("failed to create subscription: %w", err)
+		}
+		_ = r.done(index[k], ops[index[k]-first], created, err)
+	}
+	return nil
+}
+
+// modify выполняет замену или удаление подписки — операцию i.
+func (r *batchRun) modify(ctx context.Context, i int, op dto.BatchOperation) error {
+	if op.Op == dto.BatchDelete {
+		err := r.s.repo.Delete(ctx, *op.ID, op.Version)
+		if err != nil {
+			err = fmt.Errorf("failed to delete subscription: %w", err)
+		}
+		return r.done(i, op, nil, err)
+	}
+
+	var before, after *models.Subscription
+	err := r.s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		before, after, err = r.s.readModifyWrite(ctx, "Batch update", *op.ID, op.Version,
+			func(existing *models.Subscription) (*models.Subscription, error) {
+				return applyReplacement(existing, *op.Subscription.Update), nil
+			})
+		return err
+	})
+	r.before[i] = before
+	return r.done(i, op, after, err)
+}
+
+// publish публикует события выполненных операций и возвращает число невыполненных.
+func (r *batchRun) publish(ctx context.Context) int {
+	failed := 0
+	for i, result := range r.results {
+		if result.Err != nil {
+			failed++
+			continue
+		}
+		switch result.Op {
+		case dto.BatchCreate:
+			r.s.events.Publish(ctx, events.SubscriptionCreated{
+				Meta:         events.Meta{SubscriptionId: result.Subscription.Id, At: result.Subscription.CreatedAt},
+				Subscription: result.Subscription,
+			})
+		case dto.BatchUpdate:
+			r.s.publishUpdated(ctx, r.before[i], result.Subscription)
+		case dto.BatchDelete:
+			r.s.events.Publish(ctx, events.SubscriptionDeleted{Meta: events.Meta{SubscriptionId: *result.ID, At: time.Now()}})
+		}
+	}
+	return failed
+}
@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func createOp(name string, price int64) dto.BatchOperation {
+	return dto.BatchOperation{Op: dto.BatchCreate, Subscription: dto.BatchSubscription{
+		Create: &dto.CreateSubscriptionRequest{ServiceName: name, Price: price, UserID: uuid.New(), StartDate: date(2024, time.January, 1)},
+	}}
+}
+
+func updateOp(id uuid.UUID, name string, price int64) dto.BatchOperation {
+	return dto.BatchOperation{Op: dto.BatchUpdate, ID: &id, Subscription: dto.BatchSubscription{
+		Update: &dto.UpdateSubscriptionRequest{ServiceName: name, Price: price, StartDate: date(2024, time.January, 1)},
+	}}
+}
+
+func deleteOp(id uuid.UUID) dto.BatchOperation {
+	return dto.BatchOperation{Op: dto.BatchDelete, ID: &id}
+}
+
+func TestBatchAtomicRollsBackAllOperations(t *testing.T) {
+	service, repo := newSubService(t)
+	updated := createSub(t, service, "Netflix", 400, date(2024, time.January, 1))
+	deleted := createSub(t, service, "Spotify", 300, date(2024, time.January, 1))
+
+	_, err := service.Batch(ctx, dto.BatchRequest{Atomic: true, Operations: []dto.BatchOperation{
+		createOp("YouTube", 200),
+		updateOp(updated.Id, "Netflix", 999),
+		deleteOp(deleted.Id),
+		// Последняя операция не выполняется и отменяет весь пакет
+		updateOp(uuid.New(), "Missing", 100),
+	}})
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("Batch: got %v, want ErrNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "operation 3") {
+		t.Errorf("error %q does not name operation 3", err)
+	}
+
+	subs, err := repo.GetAll(ctx, &filters.SubFilter{}, 1, 100)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(subs) != 2 {
+		t.Errorf("subscriptions = %d, want 2: created subscription was not rolled back", len(subs))
+	}
+	got, err := repo.GetById(ctx, updated.Id)
+	if err != nil {
+		t.Fatalf("GetById updated: %v", err)
+	}
+	if got.Price != updated.Price || got.Version != updated.Version {
+		t.Errorf("updated subscription: price %d version %d, want %d %d", got.Price, got.Version, updated.Price, updated.Version)
+	}
+	if _, err := repo.GetById(ctx, deleted.Id); err != nil {
+		t.Errorf("deleted subscription was not restored: %v", err)
+	}
+}
+
+func TestBatchCreatesOneByOneAfterBulkInsertFails(t *testing.T) {
+	service, repo := newSubService(t)
+
+	// Длинное название проходит конструктор модели, но нарушает ограничение хранилища,
+	// поэтому вставка всех созданий одним вызовом завершается ошибкой
+	results, err := service.Batch(ctx, dto.BatchRequest{Operations: []dto.BatchOperation{
+		createOp("Netflix", 400),
+		createOp(strings.Repeat("x", 101), 300),
+		createOp("Spotify", 200),
+	}})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	for i, result := range results {
+		if i == 1 {
+			if !errors.Is(result.Err, models.ErrValidation) {
+				t.Errorf("operation 1: got %v, want ErrValidation", result.Err)
+			}
+			continue
+		}
+		if result.Err != nil || result.Subscription == nil {
+			t.Errorf("operation %d: err %v, subscription %v", i, result.Err, result.Subscription)
+		}
+	}
+
+	subs, err := repo.GetAll(ctx, &filters.SubFilter{}, 1, 100)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(subs) != 2 {
+		t.Errorf("subscriptions = %d, want 2", len(subs))
+	}
+}
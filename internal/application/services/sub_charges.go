@@ -0,0 +1,94 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Сколько подписок обрабатывается в одной транзакции планировщика
+const renewalBatchSize = 100
+
+// RenewDue создаёт списания за наступившие к now периоды оплаты активных подписок.
+// Подписки обрабатываются пачками, пока не останется должников.
+func (s *SubService) RenewDue(ctx context.Context, now time.Time) (int64, error) {
+	var total int64
+	for {
+		processed, created, err := s.repo.CreateDueCharges(ctx, now, renewalBatchSize)
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Time("now", now).
+				Msg("Renew subscriptions: repository error")
+			return total, fmt.Errorf("failed to create charges: %w", err)
+		}
+		total += created
+		if processed < renewalBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info().
+			Int64("charges", total).
+			Time("now", now).
+			Msg("Subscriptions renewed")
+	}
+	return total, nil
+}
+
+// GetCharges возвращает списания подписки от новых периодов к старым.
+// Списания доступны и для удалённых подписок.
+func (s *SubService) GetCharges(ctx context.Context, id uuid.UUID, page dto.PageRequest) (dto.ChargesResponse, error) {
+	s.logger.Debug().
+		Str("subscriptionId", id.String()).
+		Int64("page", page.Page).
+		Int64("pageSize", page.PageSize).
+		Msg("Getting subscription charges")
+
+	if _, err := s.repo.GetById(ctx, id); err != nil {
+		s.logger.Warn().
+			Err(err).
+			Str("subscriptionId", id.String()).
+			Msg("Subscription charges: subscription not found")
+		return dto.ChargesResponse{}, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	total, err := s.repo.CountCharges(ctx, id)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("subscriptionId", id.String()).
+			Msg("Failed to count subscription charges")
+		return dto.ChargesResponse{}, fmt.Errorf("failed to count subscription charges: %w", err)
+	}
+
+	charges, err := s.repo.GetCharges(ctx, id, page.Page, page.PageSize)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("subscriptionId", id.String()).
+			Msg("Failed to fetch subscription charges")
+		return dto.ChargesResponse{}, fmt.Errorf("failed to get subscription charges: %w", err)
+	}
+
+	totalPages := int64(math.Ceil(float64(total) / float64(page.PageSize)))
+	s.logger.Info().
+		Str("subscriptionId", id.String()).
+		Int("charges", len(charges)).
+		Msg("Subscription charges retrieved successfully")
+
+	return dto.ChargesResponse{
+		Data: charges,
+		Pagination: &dto.PaginationInfo{
+			Page:       page.Page,
+			PageSize:   page.PageSize,
+			TotalCount: &total,
+			TotalPages: &totalPages,
+		},
+	}, nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"time"
+)
+
+func (s *SubService) CalculateTotalCost(ctx context.Context, req dto.CostCalculationQueryRequest) (*models.CostSummary, error) {
+	s.logger.Debug().
+		Interface("filters", req).
+		Msg("Calculating total cost")
+
+	filter := subFilter(req.SubFilterQuery)
+
+	parts, err := s.repo.SumSubscriptionsCost(ctx, filter)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("Failed to calculate total cost")
+		return nil, err
+	}
+
+	currency := costCurrency(req)
+	summary, err := s.convertCosts(ctx, parts, currency, ratesDate(filter))
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("currency", currency).
+			Msg("Failed to convert total cost")
+		return nil, err
+	}
+
+	if req.Period != "" {
+		summary.Normalize(models.BillingInterval(req.Period))
+	}
+
+	s.logger.Info().
+		Int64("total", summary.TotalCost).
+		Str("currency", summary.Currency).
+		Int64("billableMonths", summary.BillableMonths).
+		Msg("Total cost calculated")
+	return summary, nil
+}
+
+func (s *SubService) CalculateCostBreakdown(ctx context.Context, req dto.CostBreakdownQueryRequest) (*dto.CostBreakdownResponse, error) {
+	s.logger.Debug().
+		Interface("filters", req).
+		Msg("Calculating cost breakdown")
+
+	filter := subFilter(req.SubFilterQuery)
+	groupBy := filters.CostGroupBy(req.GroupBy)
+
+	parts, err := s.repo.SumSubscriptionsCostGrouped(ctx, filter, groupBy)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("groupBy", req.GroupBy).
+			Msg("Failed to calculate cost breakdown")
+		return nil, err
+	}
+
+	currency := costCurrency(req.CostCalculationQueryRequest)
+	at := ratesDate(filter)
+
+	response := &dto.CostBreakdownResponse{
+		GroupBy:  req.GroupBy,
+		Currency: currency,
+		Groups:   []*dto.CostBreakdownGroup{},
+		Filters:  req,
+	}
+	usedRates := make(map[string]bool)
+	// Итог складывается из неокруглённых сумм групп — так же, как в CalculateTotalCost
+	total := &models.CostSummary{Currency: currency}
+
+	// Группы приходят отсортированными по ключу, суммы в разных валютах идут подряд
+	for i := 0; i < len(parts); {
+		key := parts[i].Key
+		var subscriptionCount int64
+		var sameKey []*models.CostSummary
+		for ; i < len(parts) && parts[i].Key == key; i++ {
+			subscriptionCount += parts[i].SubscriptionCount
+			sameKey = append(sameKey, &parts[i].CostSummary)
+		}
+
+		summary, err := s.convertCosts(ctx, sameKey, currency, at)
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Str("currency", currency).
+				Msg("Failed to convert cost breakdown")
+			return nil, err
+		}
+		if req.Period != "" {
+			summary.Normalize(models.BillingInterval(req.Period))
+		}
+
+		for _, rate := range summary.Rates {
+			if !usedRates[rate.Base] {
+				usedRates[rate.Base] = true
+				response.Rates = append(response.Rates, rate)
+			}
+		}
+		summary.Rates = nil
+
+		total.Add(summary, 1)
+		response.Groups = append(response.Groups, &dto.CostBreakdownGroup{
+			CostGroup: models.CostGroup{
+				Key:               key,
+				SubscriptionCount: subscriptionCount,
+				CostSummary:       *summary,
+			},
+			Filters: groupFilters(req.CostCalculationQueryRequest, groupBy, key),
+		})
+	}
+
+	response.TotalCost = total.TotalCost
+
+	s.logger.Info().
+		Str("groupBy", req.GroupBy).
+		Int("groups", len(response.Groups)).
+		Int64("total", response.TotalCost).
+		Msg("Cost breakdown calculated")
+	return response, nil
+}
+
+// convertCosts пересчитывает суммы в разных валютах в currency по курсам на дату at и складывает их.
+func (s *SubService) convertCosts(ctx context.Context, parts []*models.CostSummary, currency string, at time.Time) (*models.CostSummary, error) {
+	total := &models.CostSummary{Currency: currency}
+	for _, part := range parts {
+		if part.Currency == currency {
+			total.Add(part, 1)
+			continue
+		}
+
+		rate, err := s.rates.GetRate(ctx, part.Currency, currency, at)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(part, rate.Rate)
+		total.Rates = append(total.Rates, *rate)
+	}
+	return total, nil
+}
+
+func costCurrency(req dto.CostCalculationQueryRequest) string {
+	if req.Currency == "" {
+		return models.DefaultCurrency
+	}
+	return req.Currency
+}
+
+// ratesDate — дата курсов для пересчёта: конец периода, но не из будущего.
+func ratesDate(filter *filters.SubFilter) time.Time {
+	at := time.Now()
+	if filter.To != nil && filter.To.Before(at) {
+		at = *filter.To
+	}
+	return at
+}
+
+// groupFilters возвращает фильтры, с которыми /cost вернёт итог этой группы.
+func groupFilters(req dto.CostCalculationQueryRequest, groupBy filters.CostGroupBy, key string) dto.CostCalculationQueryRequest {
+	applied := req
+	applied.Period = ""
+
+	var periodStart, periodEnd time.Time
+	switch groupBy {
+	case filters.GroupByServiceName:
+		applied.ServiceName = key
+		return applied
+	case filters.GroupByUserID:
+		applied.UserID = key
+		return applied
+	case filters.GroupByMonth:
+		start, err := time.ParseInLocation("2006-01", key, time.Local)
+		if err != nil {
+			return applied
+		}
+		periodStart, periodEnd = start, start.AddDate(0, 1, 0).Add(-time.Second)
+	case filters.GroupByYear:
+		start, err := time.ParseInLocation("2006", key, time.Local)
+		if err != nil {
+			return applied
+		}
+		periodStart, periodEnd = start, start.AddDate(1, 0, 0).Add(-time.Second)
+	}
+
+	if applied.From.IsZero() || applied.From.Before(periodStart) {
+		applied.From = periodStart
+	}
+	if applied.To.IsZero() || applied.To.After(periodEnd) {
+		applied.To = periodEnd
+	}
+	return applied
+}
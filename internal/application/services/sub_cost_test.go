@@ -0,0 +1,38 @@
+package services_test
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"testing"
+)
+
+// Итог разбивки совпадает с /cost, даже если суммы групп дробные.
+func TestCostBreakdownTotalMatchesTotalCost(t *testing.T) {
+	service, _ := newSubService(t)
+	// 10 в неделю — около 43.48 в месяц: помесячные суммы 43 + 43 + 43, а итог за три месяца — 130
+	createSub(t, service, "Weekly", 10, date(2024, 1, 1), func(req *dto.CreateSubscriptionRequest) {
+		req.BillingInterval = "weekly"
+	})
+	createSub(t, service, "Monthly", 100, date(2024, 2, 1))
+
+	query := dto.CostCalculationQueryRequest{SubFilterQuery: dto.SubFilterQuery{To: date(2024, 3, 31)}}
+	total, err := service.CalculateTotalCost(ctx, query)
+	if err != nil {
+		t.Fatalf("total cost: %v", err)
+	}
+	if total.TotalCost != 330 {
+		t.Fatalf("expected total cost 330, got %d", total.TotalCost)
+	}
+
+	for _, groupBy := range []string{"month", "year", "service_name", "user_id"} {
+		breakdown, err := service.CalculateCostBreakdown(ctx, dto.CostBreakdownQueryRequest{
+			CostCalculationQueryRequest: query,
+			GroupBy:                     groupBy,
+		})
+		if err != nil {
+			t.Fatalf("cost breakdown by %s: %v", groupBy, err)
+		}
+		if breakdown.TotalCost != total.TotalCost {
+			t.Fatalf("breakdown by %s: expected total %d, got %d", groupBy, total.TotalCost, breakdown.TotalCost)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"SubscriptionService/internal/application/events"
+	"SubscriptionService/internal/core/models"
+	"context"
+)
+
+// publishUpdated публикует изменение подписки с разницей полей, а при смене цены — и PriceChanged.
+// Новая цена действует с момента изменения (или с начала подписки, если она ещё не началась).
+func (s *SubService) publishUpdated(ctx context.Context, before, after *models.Subscription) {
+	diff, err := events.Diff(before, after)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("subscriptionId", after.Id.String()).
+			Msg("Failed to compute subscription diff")
+		return
+	}
+
+	meta := events.Meta{SubscriptionId: after.Id, At: after.UpdatedAt}
+	s.events.Publish(ctx, events.SubscriptionUpdated{Meta: meta, Before: before, After: after, Diff: diff})
+
+	if after.Price != before.Price {
+		effectiveFrom := after.UpdatedAt
+		if after.StartDate.After(effectiveFrom) {
+			effectiveFrom = after.StartDate
+		}
+		s.events.Publish(ctx, events.PriceChanged{
+			Meta:          meta,
+			OldPrice:      before.Price,
+			NewPrice:      after.Price,
+			Currency:      after.Currency,
+			EffectiveFrom: effectiveFrom,
+		})
+	}
+}
+
+func (s *SubService) publishPriceScheduled(ctx context.Context, sub *models.Subscription, change *models.PriceChange) {
+	s.events.Publish(ctx, events.PriceChanged{
+		Meta:          events.Meta{SubscriptionId: sub.Id, At: change.CreatedAt},
+		OldPrice:      sub.Price,
+		NewPrice:      change.Price,
+		Currency:      sub.Currency,
+		EffectiveFrom: change.EffectiveFrom,
+	})
+}
+
+func (s *SubService) publishExpired(ctx context.Context, expired []*models.Subscription) {
+	for _, sub := range expired {
+		s.events.Publish(ctx, events.SubscriptionExpired{
+			Meta:         events.Meta{SubscriptionId: sub.Id, At: sub.UpdatedAt},
+			Subscription: sub,
+		})
+	}
+}
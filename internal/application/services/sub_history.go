@@ -0,0 +1,65 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// GetHistory возвращает журнал изменений подписки от новых событий к старым.
+// История доступна и для удалённых подписок.
+func (s *SubService) GetHistory(ctx context.Context, id uuid.UUID, page dto.PageRequest) (dto.HistoryResponse, error) {
+	s.logger.Debug().
+		Str("subscriptionId", id.String()).
+		Int64("page", page.Page).
+		Int64("pageSize", page.PageSize).
+		Msg("Getting subscription history")
+
+	total, err := s.repo.CountHistory(ctx, id)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("subscriptionId", id.String()).
+			Msg("Failed to count subscription events")
+		return dto.HistoryResponse{}, fmt.Errorf("failed to count subscription events: %w", err)
+	}
+
+	// Пустой журнал бывает у подписок, созданных до его появления; несуществующая подписка — 404
+	if total == 0 {
+		if _, err := s.repo.GetById(ctx, id); err != nil {
+			s.logger.Warn().
+				Err(err).
+				Str("subscriptionId", id.String()).
+				Msg("Subscription history: subscription not found")
+			return dto.HistoryResponse{}, fmt.Errorf("failed to get subscription: %w", err)
+		}
+	}
+
+	events, err := s.repo.GetHistory(ctx, id, page.Page, page.PageSize)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("subscriptionId", id.String()).
+			Msg("Failed to fetch subscription events")
+		return dto.HistoryResponse{}, fmt.Errorf("failed to get subscription events: %w", err)
+	}
+
+	totalPages := int64(math.Ceil(float64(total) / float64(page.PageSize)))
+	s.logger.Info().
+		Str("subscriptionId", id.String()).
+		Int("events", len(events)).
+		Msg("Subscription history retrieved successfully")
+
+	return dto.HistoryResponse{
+		Data: events,
+		Pagination: &dto.PaginationInfo{
+			Page:       page.Page,
+			PageSize:   page.PageSize,
+			TotalCount: &total,
+			TotalPages: &totalPages,
+		},
+	}, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChangeState выполняет действие op над подпиской; недопустимый переход — ErrInvalidTransition (409).
+func (s *SubService) ChangeState(ctx context.Context, id uuid.UUID, op models.Operation, ifMatch *int64) (*models.Subscription, error) {
+	s.logger.Debug().
+		Str("subscriptionId", id.String()).
+		Str("action", string(op)).
+		Msg("Changing subscription state")
+
+	var before, result *models.Subscription
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		before, result, err = s.changeState(ctx, id, op, ifMatch)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound),
+			errors.Is(err, models.ErrConflict),
+			errors.Is(err, models.ErrPreconditionFailed):
+			s.logger.Warn().
+				Err(err).
+				Str("subscriptionId", id.String()).
+				Str("action", string(op)).
+				Msg("Change subscription state: rejected")
+		default:
+			s.logger.Error().
+				Err(err).
+				Str("subscriptionId", id.String()).
+				Str("action", string(op)).
+				Msg("Change subscription state: repository error")
+		}
+		return nil, fmt.Errorf("failed to change subscription state: %w", err)
+	}
+
+	s.logger.Info().
+		Str("subscriptionId", id.String()).
+		Str("state", string(result.State)).
+		Msg("Subscription state changed successfully")
+
+	s.publishUpdated(ctx, before, result)
+	return result, nil
+}
+
+// changeState выполняет переход условно по прочитанной версии, чтобы событие
+// об изменении содержало точное состояние подписки до перехода.
+// Вызывается в единице работы: чтение и переход выполняются в одной транзакции.
+func (s *SubService) changeState(ctx context.Context, id uuid.UUID, op models.Operation, ifMatch *int64) (*models.Subscription, *models.Subscription, error) {
+	before, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ifMatch != nil && *ifMatch != before.Version {
+		return nil, nil, fmt.Errorf("%w: subscription was modified", models.ErrPreconditionFailed)
+	}
+
+	result, err := s.repo.ChangeState(ctx, id, op, &before.Version, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, result, nil
+}
+
+// ExpireDue переводит в expired подписки, дата окончания которых прошла к now.
+func (s *SubService) ExpireDue(ctx context.Context, now time.Time) (int64, error) {
+	expired, err := s.repo.ExpireDue(ctx, now)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("Expire subscriptions: repository error")
+		return 0, fmt.Errorf("failed to expire subscriptions: %w", err)
+	}
+
+	if len(expired) > 0 {
+		s.logger.Info().
+			Int("expired", len(expired)).
+			Msg("Subscriptions expired")
+	}
+	s.publishExpired(ctx, expired)
+	return int64(len(expired)), nil
+}
+
+// ConvertDueTrials переводит в active пробные подписки, пробный период которых закончился к now.
+// О каждой переведённой подписке публикуется то же событие, что и при ручной активации.
+func (s *SubService) ConvertDueTrials(ctx context.Context, now time.Time) (int64, error) {
+	converted, err := s.repo.ConvertDueTrials(ctx, now)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("Convert trials: repository error")
+		return 0, fmt.Errorf("failed to convert trials: %w", err)
+	}
+
+	if len(converted) > 0 {
+		s.logger.Info().
+			Int("converted", len(converted)).
+			Msg("Trials converted to paid subscriptions")
+	}
+	for _, change := range converted {
+		s.publishUpdated(ctx, change.Before, change.After)
+	}
+	return int64(len(converted)), nil
+}
+
+// Горизонт по умолчанию для списка заканчивающихся пробных периодов
+const defaultTrialWarningDays = 3
+
+// EndingTrials возвращает пробные подписки, пробный период которых закончится
+// в ближайшие req.WithinDays дней (или уже закончился, но ещё не обработан),
+// в порядке окончания пробного периода.
+func (s *SubService) EndingTrials(ctx context.Context, req dto.EndingTrialsQueryRequest, page dto.PageRequest) (dto.GetAllResponse, error) {
+	days := req.WithinDays
+	if days == 0 {
+		days = defaultTrialWarningDays
+	}
+	before := time.Now().AddDate(0, 0, days)
+
+	filter := &filters.SubFilter{
+		TrialEndsBefore: &before,
+		Sort:            []filters.SortField{{Field: "trial_end"}},
+	}
+	if userID, err := uuid.Parse(req.UserID); err == nil {
+		filter.UserID = &userID
+	}
+
+	response, err := s.getAllByPage(ctx, filter, page)
+	if err != nil {
+		return dto.GetAllResponse{}, err
+	}
+
+	s.logger.Info().
+		Int("days", days).
+		Int("subscriptionsCount", len(response.Data)).
+		Msg("Ending trials retrieved successfully")
+	return response, nil
+}
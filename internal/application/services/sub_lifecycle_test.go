@@ -0,0 +1,51 @@
+package services_test
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/application/events"
+	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/persistence/memory"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestConvertDueTrialsPublishesUpdated(t *testing.T) {
+	logger := zerolog.Nop()
+	dispatcher := events.NewSyncDispatcher(&logger)
+	var updated []events.SubscriptionUpdated
+	dispatcher.Subscribe("test", func(_ context.Context, event events.Event) error {
+		updated = append(updated, event.(events.SubscriptionUpdated))
+		return nil
+	}, events.NameSubscriptionUpdated)
+	repo := memory.NewSubRepository()
+	service := services.NewSubService(repo, memory.NewTxManager(repo), memory.ExchangeRates{}, dispatcher, 0, &logger)
+
+	trialDays := 14
+	trial := createSub(t, service, "Netflix", 400, date(2024, time.January, 1), func(req *dto.CreateSubscriptionRequest) {
+		req.TrialDays = &trialDays
+	})
+	createSub(t, service, "Spotify", 300, date(2024, time.January, 1))
+
+	converted, err := service.ConvertDueTrials(ctx, date(2024, time.January, 20))
+	if err != nil {
+		t.Fatalf("ConvertDueTrials: %v", err)
+	}
+	if converted != 1 {
+		t.Fatalf("converted = %d, want 1", converted)
+	}
+	if len(updated) != 1 {
+		t.Fatalf("published %d SubscriptionUpdated events, want 1", len(updated))
+	}
+	event := updated[0]
+	if event.SubscriptionID() != trial.Id || event.Before.State != models.StateTrial || event.After.State != models.StateActive {
+		t.Fatalf("unexpected event: %s %s -> %s", event.SubscriptionID(), event.Before.State, event.After.State)
+	}
+	if !slices.ContainsFunc(event.Diff, func(change events.FieldChange) bool { return change.Field == "state" }) {
+		t.Errorf("diff does not contain state: %+v", event.Diff)
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// Patch применяет к подписке JSON Merge Patch (RFC 7396) или JSON Patch (RFC 6902).
+// Патч применяется к документу изменяемых полей (dto.SubscriptionPatchDocument),
+// поэтому null в merge patch сбрасывает необязательные поля (end_date, interval_days).
+func (s *SubService) Patch(ctx context.Context, id uuid.UUID, req dto.PatchSubscriptionRequest, ifMatch *int64) (*models.Subscription, error) {
+	return s.modify(ctx, "Patch subscription", id, ifMatch, func(existing *models.Subscription) (*models.Subscription, error) {
+		return applyPatch(existing, req)
+	})
+}
+
+func applyPatch(existing *models.Subscription, req dto.PatchSubscriptionRequest) (*models.Subscription, error) {
+	original, err := json.Marshal(dto.SubscriptionPatchDocument{
+		ServiceName:     existing.ServiceName,
+		Price:           existing.Price,
+		Currency:        existing.Currency,
+		BillingInterval: string(existing.BillingInterval),
+		IntervalDays:    existing.IntervalDays,
+		StartDate:       existing.StartDate,
+		EndDate:         existing.EndDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal patch document: %w", err)
+	}
+
+	var patched []byte
+	switch req.Format {
+	case dto.PatchMerge:
+		patched, err = jsonpatch.MergePatch(original, req.Patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid merge patch: %v", models.ErrInvalidArgument, err)
+		}
+	case dto.PatchJSON:
+		patch, err := jsonpatch.DecodePatch(req.Patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid json patch: %v", models.ErrInvalidArgument, err)
+		}
+		patched, err = patch.Apply(original)
+		if err != nil {
+			// Операция test не прошла или путь отсутствует — патч не применим к текущему состоянию
+			return nil, fmt.Errorf("%w: json patch cannot be applied: %v", models.ErrConflict, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported patch format %q", models.ErrInvalidArgument, req.Format)
+	}
+
+	var doc dto.SubscriptionPatchDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, models.NewValidationError(typeErr.Field, "type",
+				fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type))
+		}
+		return nil, fmt.Errorf("%w: patched document is invalid: %v", models.ErrValidation, err)
+	}
+	// Те же ограничения полей, что при привязке PUT; ошибки — validator.ValidationErrors с полями
+	if err := binding.Validator.ValidateStruct(&doc); err != nil {
+		return nil, err
+	}
+
+	updated := &models.Subscription{
+		Id:              existing.Id,
+		ServiceName:     doc.ServiceName,
+		Price:           doc.Price,
+		Currency:        doc.Currency,
+		UserId:          existing.UserId,
+		BillingInterval: models.BillingInterval(doc.BillingInterval),
+		IntervalDays:    doc.IntervalDays,
+		State:           existing.State,
+		TrialEnd:        existing.TrialEnd,
+		StartDate:       doc.StartDate,
+		EndDate:         doc.EndDate,
+		CreatedAt:       existing.CreatedAt,
+		UpdatedAt:       time.Now(),
+		Version:         existing.Version,
+	}
+	updated.NormalizeInterval()
+
+	return updated, nil
+}
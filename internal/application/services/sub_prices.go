@@ -0,0 +1,77 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchedulePrice планирует изменение цены подписки с будущей даты.
+// Месяцы до этой даты продолжают считаться по прежней цене.
+func (s *SubService) SchedulePrice(ctx context.Context, id uuid.UUID, req dto.SchedulePriceRequest) (*models.PriceChange, error) {
+	s.logger.Debug().
+		Str("subscriptionId", id.String()).
+		Int64("price", req.Price).
+		Time("effectiveFrom", req.EffectiveFrom).
+		Msg("Scheduling price change")
+
+	sub, err := s.GetById(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := models.NewPriceChange(sub, req.Price, req.EffectiveFrom, time.Now())
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Str("subscriptionId", id.String()).
+			Msg("Invalid price change")
+		return nil, err
+	}
+
+	scheduled, err := s.repo.SchedulePrice(ctx, change)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.logger.Warn().
+				Str("subscriptionId", id.String()).
+				Msg("Schedule price: subscription not found")
+		} else {
+			s.logger.Error().
+				Err(err).
+				Str("subscriptionId", id.String()).
+				Msg("Failed to schedule price change")
+		}
+		return nil, fmt.Errorf("failed to schedule price change: %w", err)
+	}
+
+	s.logger.Info().
+		Str("subscriptionId", id.String()).
+		Int64("price", scheduled.Price).
+		Time("effectiveFrom", scheduled.EffectiveFrom).
+		Msg("Price change scheduled successfully")
+
+	s.publishPriceScheduled(ctx, sub, scheduled)
+	return scheduled, nil
+}
+
+// GetPrices возвращает историю и план цен подписки.
+func (s *SubService) GetPrices(ctx context.Context, id uuid.UUID) ([]*models.PriceChange, error) {
+	if _, err := s.GetById(ctx, id, true); err != nil {
+		return nil, err
+	}
+
+	prices, err := s.repo.GetPrices(ctx, id)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("subscriptionId", id.String()).
+			Msg("Failed to fetch subscription prices")
+		return nil, fmt.Errorf("failed to get subscription prices: %w", err)
+	}
+	return prices, nil
+}
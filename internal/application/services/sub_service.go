@@ -3,11 +3,14 @@ package services
 import (
 	"SubscriptionService/internal/api/dto"
 	appInterfaces "SubscriptionService/internal/application/app_interfaces"
+	"SubscriptionService/internal/application/events"
 	"SubscriptionService/internal/core/core_interfaces"
 	"SubscriptionService/internal/core/models"
 	"SubscriptionService/internal/core/ports/filters"
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,16 +18,33 @@ import (
 )
 
 type SubService struct {
-	repo   core_interfaces.ISubRepository
-	logger *zerolog.Logger
+	repo core_interfaces.ISubRepository
+	// tx объединяет несколько обращений к хранилищу в одну транзакцию
+	tx    core_interfaces.ITxManager
+	rates core_interfaces.IExchangeRateProvider
+	// events получает доменные события изменений подписок
+	events appInterfaces.IEventPublisher
+	// trashRetention — сколько удалённые подписки хранятся в корзине до очистки
+	trashRetention time.Duration
+	logger         *zerolog.Logger
 }
 
 var _ appInterfaces.ISubService = (*SubService)(nil)
 
-func NewSubService(repo core_interfaces.ISubRepository, logger *zerolog.Logger) *SubService {
+func NewSubService(
+	repo core_interfaces.ISubRepository,
+	tx core_interfaces.ITxManager,
+	rates core_interfaces.IExchangeRateProvider,
+	publisher appInterfaces.IEventPublisher,
+	trashRetention time.Duration,
+	logger *zerolog.Logger) *SubService {
 	return &SubService{
-		repo:   repo,
-		logger: logger,
+		repo:           repo,
+		tx:             tx,
+		rates:          rates,
+		events:         publisher,
+		trashRetention: trashRetention,
+		logger:         logger,
 	}
 }
 
@@ -34,14 +54,7 @@ func (s *SubService) Create(ctx context.Context, req dto.CreateSubscriptionReque
 		Str("userId", req.UserID.String()).
 		Msg("Creating subscription")
 
-	sub, err := models.NewSubscription(
-		req.ServiceName,
-		req.Price,
-		req.UserID,
-		req.StartDate,
-		req.EndDate,
-	)
-
+	sub, err := newSubscription(req)
 	if err != nil {
 		s.logger.Error().
 			Err(err).
@@ -64,87 +77,202 @@ func (s *SubService) Create(ctx context.Context, req dto.CreateSubscriptionReque
 		Int64("price", createdSub.Price).
 		Msg("Subscription created successfully")
 
+	s.events.Publish(ctx, events.SubscriptionCreated{
+		Meta:         events.Meta{SubscriptionId: createdSub.Id, At: createdSub.CreatedAt},
+		Subscription: createdSub,
+	})
 	return createdSub, nil
 }
 
-func (s *SubService) Update(ctx context.Context, id uuid.UUID, req dto.UpdateSubscriptionRequest) (*models.Subscription, error) {
+func (s *SubService) Update(ctx context.Context, id uuid.UUID, req dto.UpdateSubscriptionRequest, ifMatch *int64) (*models.Subscription, error) {
+	return s.modify(ctx, "Update subscription", id, ifMatch, func(existing *models.Subscription) (*models.Subscription, error) {
+		return applyReplacement(existing, req), nil
+	})
+}
+
+// modify загружает подписку, строит её новое состояние через apply, валидирует
+// и сохраняет условно по прочитанной версии. op — префикс сообщений лога.
+func (s *SubService) modify(
+	ctx context.Context,
+	op string,
+	id uuid.UUID,
+	ifMatch *int64,
+	apply func(existing *models.Subscription) (*models.Subscription, error)) (*models.Subscription, error) {
 
 	s.logger.Debug().
 		Str("id", id.String()).
-		Msg("Update subscription: started")
+		Msg(op + ": started")
+
+	// Чтение и условная запись выполняются в одной транзакции
+	var existing, result *models.Subscription
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		existing, result, err = s.readModifyWrite(ctx, op, id, ifMatch, apply)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("id", id.String()).
+		Msg(op + ": success")
+
+	s.publishUpdated(ctx, existing, result)
+	return result, nil
+}
+
+// readModifyWrite загружает подписку, применяет apply, валидирует результат и сохраняет его.
+// Возвращает состояния до и после изменения.
+func (s *SubService) readModifyWrite(
+	ctx context.Context,
+	op string,
+	id uuid.UUID,
+	ifMatch *int64,
+	apply func(existing *models.Subscription) (*models.Subscription, error)) (*models.Subscription, *models.Subscription, error) {
 
 	// 1. Получаем существующую запись
 	existing, err := s.repo.GetById(ctx, id)
 	if err != nil {
-		s.logger.Error().
-			Err(err).
+		if errors.Is(err, models.ErrNotFound) {
+			s.logger.Warn().
+				Str("id", id.String()).
+				Msg(op + ": not found")
+		} else {
+			s.logger.Error().
+				Err(err).
+				Str("id", id.String()).
+				Msg(op + ": failed to get existing")
+		}
+		return nil, nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if existing.IsDeleted() {
+		s.logger.Warn().
 			Str("id", id.String()).
-			Msg("Update subscription: failed to get existing")
-
-		return nil, fmt.Errorf("failed to get subscription: %w", err)
+			Msg(op + ": subscription is deleted")
+		return nil, nil, fmt.Errorf("failed to get subscription: %w", models.ErrNotFound)
 	}
-	if existing == nil {
+
+	if ifMatch != nil && *ifMatch != existing.Version {
 		s.logger.Warn().
 			Str("id", id.String()).
-			Msg("Update subscription: not found")
-		return nil, fmt.Errorf("subscription not found")
+			Int64("ifMatch", *ifMatch).
+			Int64("version", existing.Version).
+			Msg(op + ": version mismatch")
+		return nil, nil, fmt.Errorf("%w: subscription was modified", models.ErrPreconditionFailed)
 	}
 
-	// 2. Применяем partial update
-	updated := s.applyPartialUpdate(existing, req)
-	s.logger.Debug().
-		Str("id", id.String()).
-		Msg("Update subscription: partial update applied")
+	// 2. Строим новое состояние
+	updated, err := apply(existing)
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Str("id", id.String()).
+			Msg(op + ": failed to apply changes")
+		return nil, nil, err
+	}
 
 	// 3. Валидируем модель
 	if err := updated.Validate(); err != nil {
 		s.logger.Warn().
 			Err(err).
 			Str("id", id.String()).
-			Msg("Update subscription: validation failed")
-		return nil, err
+			Msg(op + ": validation failed")
+		return nil, nil, err
 	}
 
-	// 4. Сохраняем в репозитории
+	// 4. Сохраняем в репозитории (условно по прочитанной версии)
 	result, err := s.repo.Update(ctx, updated)
+	if errors.Is(err, models.ErrPreconditionFailed) {
+		s.logger.Warn().
+			Str("id", id.String()).
+			Msg(op + ": concurrent modification")
+		return nil, nil, err
+	}
 	if err != nil {
 		s.logger.Error().
 			Err(err).
 			Str("id", id.String()).
-			Msg("Update subscription: failed to update subscription")
-		return nil, fmt.Errorf("repository error: %w", err)
+			Msg(op + ": failed to update subscription")
+		return nil, nil, fmt.Errorf("failed to update subscription: %w", err)
 	}
+	return existing, result, nil
+}
 
-	if result == nil {
-		s.logger.Warn().
-			Str("id", id.String()).
-			Msg("Update subscription: not found in repository")
-		return nil, fmt.Errorf("subscription not found")
+func (s *SubService) Delete(ctx context.Context, id uuid.UUID, ifMatch *int64) error {
+
+	err := s.repo.Delete(ctx, id, ifMatch)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.logger.Warn().
+				Str("subscriptionId", id.String()).
+				Msg("Delete subscription: not found")
+		} else if errors.Is(err, models.ErrPreconditionFailed) {
+			s.logger.Warn().
+				Str("subscriptionId", id.String()).
+				Msg("Delete subscription: version mismatch")
+		} else {
+			s.logger.Error().
+				Err(err).
+				Str("subscriptionId", id.String()).
+				Msg("Delete subscription: repository error")
+		}
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	s.logger.Info().
+		Str("subscriptionId", id.String()).
+		Msg("Subscription deleted successfully")
+
+	s.events.Publish(ctx, events.SubscriptionDeleted{Meta: events.Meta{SubscriptionId: id, At: time.Now()}})
+	return nil
+}
+
+// Restore возвращает подписку из корзины.
+func (s *SubService) Restore(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	restored, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) || errors.Is(err, models.ErrConflict) {
+			s.logger.Warn().
+				Err(err).
+				Str("subscriptionId", id.String()).
+				Msg("Restore subscription: not in trash")
+		} else {
+			s.logger.Error().
+				Err(err).
+				Str("subscriptionId", id.String()).
+				Msg("Restore subscription: repository error")
+		}
+		return nil, fmt.Errorf("failed to restore subscription: %w", err)
 	}
 
 	s.logger.Info().
-		Str("id", id.String()).
-		Msg("Update subscription: success")
-	return result, nil
+		Str("subscriptionId", id.String()).
+		Msg("Subscription restored successfully")
+	return restored, nil
 }
 
-func (s *SubService) Delete(ctx context.Context, id uuid.UUID) error {
+// PurgeTrash окончательно удаляет подписки, пролежавшие в корзине дольше срока хранения.
+func (s *SubService) PurgeTrash(ctx context.Context) (dto.PurgeResponse, error) {
+	before := time.Now().Add(-s.trashRetention)
 
-	err := s.repo.Delete(ctx, id)
+	purged, err := s.repo.Purge(ctx, before)
 	if err != nil {
 		s.logger.Error().
 			Err(err).
-			Str("subscriptionId", id.String()).
-			Msg("Delete subscription: repository error")
-		return fmt.Errorf("failed to delete subscription: %w", err)
+			Time("before", before).
+			Msg("Purge trash: repository error")
+		return dto.PurgeResponse{}, fmt.Errorf("failed to purge subscriptions: %w", err)
 	}
+
 	s.logger.Info().
-		Str("subscriptionId", id.String()).
-		Msg("Subscription deleted successfully")
-	return nil
+		Int64("purged", purged).
+		Time("before", before).
+		Msg("Trash purged successfully")
+	return dto.PurgeResponse{Purged: purged, DeletedBefore: before}, nil
 }
 
-func (s *SubService) GetById(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+// GetById возвращает подписку; удалённая подписка видна только при includeDeleted.
+func (s *SubService) GetById(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Subscription, error) {
 	s.logger.Debug().
 		Str("subscriptionId", id.String()).
 		Msg("Getting subscription by ID")
@@ -155,18 +283,23 @@ func (s *SubService) GetById(ctx context.Context, id uuid.UUID) (*models.Subscri
 
 	subscription, err := s.repo.GetById(ctx, id)
 	if err != nil {
-		s.logger.Error().
-			Err(err).
-			Str("subscriptionId", id.String()).
-			Msg("Failed to fetch subscription from repository")
+		if errors.Is(err, models.ErrNotFound) {
+			s.logger.Warn().
+				Str("subscriptionId", id.String()).
+				Msg("Subscription not found")
+		} else {
+			s.logger.Error().
+				Err(err).
+				Str("subscriptionId", id.String()).
+				Msg("Failed to fetch subscription from repository")
+		}
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
-
-	if subscription == nil {
+	if subscription.IsDeleted() && !includeDeleted {
 		s.logger.Warn().
 			Str("subscriptionId", id.String()).
-			Msg("Subscription not found")
-		return nil, fmt.Errorf("subscription not found")
+			Msg("Subscription is deleted")
+		return nil, fmt.Errorf("failed to get subscription: %w", models.ErrNotFound)
 	}
 
 	s.logger.Info().
@@ -178,121 +311,212 @@ func (s *SubService) GetById(ctx context.Context, id uuid.UUID) (*models.Subscri
 	return subscription, nil
 }
 
-func (s *SubService) GetAll(ctx context.Context, page, pageSize int64) (dto.GetAllResponse, error) {
+func (s *SubService) GetAll(ctx context.Context, req dto.GetAllQueryRequest, page dto.PageRequest) (dto.GetAllResponse, error) {
 	s.logger.Debug().
-		Int64("page", page).
-		Int64("pageSize", pageSize).
+		Int64("page", page.Page).
+		Int64("pageSize", page.PageSize).
+		Bool("cursorMode", page.Cursor != nil).
+		Interface("filters", req).
 		Msg("Getting all subscriptions")
 
-	subscriptions, totalCount, totalPages, err := s.repo.GetAll(ctx, page, pageSize)
+	sort, err := filters.ParseSort(req.Sort)
 	if err != nil {
-		s.logger.Error().
+		s.logger.Warn().
 			Err(err).
-			Int64("page", page).
-			Int64("pageSize", pageSize).
-			Msg("Failed to fetch subscriptions from repository")
-		return dto.GetAllResponse{}, fmt.Errorf("failed to get subscriptions: %w", err)
+			Str("sort", req.Sort).
+			Msg("Invalid sort for subscriptions")
+		return dto.GetAllResponse{}, err
 	}
 
-	s.logger.Info().
-		Int64("page", page).
-		Int64("pageSize", pageSize).
-		Int64("totalCount", totalCount).
-		Int64("totalPages", totalPages).
-		Int("subscriptionsCount", len(subscriptions)).
-		Msg("Subscriptions retrieved successfully")
+	filter := subFilter(req.SubFilterQuery)
+	filter.OnlyDeleted = req.OnlyDeleted
+	filter.Sort = sort
 
-	response := dto.GetAllResponse{
-		Data: subscriptions,
-		Pagination: &dto.PaginationInfo{
-			Page:       page,
-			PageSize:   pageSize,
-			TotalCount: totalCount,
-			TotalPages: totalPages,
-		},
+	var response dto.GetAllResponse
+	if page.Cursor != nil {
+		response, err = s.getAllByCursor(ctx, filter, page)
+	} else {
+		response, err = s.getAllByPage(ctx, filter, page)
+	}
+	if err != nil {
+		return dto.GetAllResponse{}, err
+	}
+
+	if page.WithTotal {
+		totalCount, err := s.repo.Count(ctx, filter)
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Msg("Failed to count subscriptions")
+			return dto.GetAllResponse{}, fmt.Errorf("failed to count subscriptions: %w", err)
+		}
+		totalPages := int64(math.Ceil(float64(totalCount) / float64(page.PageSize)))
+		response.Pagination.TotalCount = &totalCount
+		response.Pagination.TotalPages = &totalPages
 	}
 
+	s.logger.Info().
+		Int64("page", page.Page).
+		Int64("pageSize", page.PageSize).
+		Int("subscriptionsCount", len(response.Data)).
+		Msg("Subscriptions retrieved successfully")
+
 	return response, nil
 }
 
-func (s *SubService) CalculateTotalCost(ctx context.Context, req dto.CostCalculationQueryRequest) (int64, error) {
-	s.logger.Debug().
-		Interface("filters", req).
-		Msg("Calculating total cost")
-
-	var userID *uuid.UUID
-	if req.UserID != uuid.Nil {
-		userID = &req.UserID
+func (s *SubService) getAllByPage(ctx context.Context, filter *filters.SubFilter, page dto.PageRequest) (dto.GetAllResponse, error) {
+	subscriptions, err := s.repo.GetAll(ctx, filter, page.Page, page.PageSize)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Int64("page", page.Page).
+			Int64("pageSize", page.PageSize).
+			Msg("Failed to fetch subscriptions from repository")
+		return dto.GetAllResponse{}, fmt.Errorf("failed to get subscriptions: %w", err)
 	}
 
-	var serviceName *string
-	if req.ServiceName != "" {
-		serviceName = &req.ServiceName
-	}
+	return dto.GetAllResponse{
+		Data: subscriptions,
+		Pagination: &dto.PaginationInfo{
+			Page:     page.Page,
+			PageSize: page.PageSize,
+		},
+	}, nil
+}
 
-	var from *time.Time
-	if !req.From.IsZero() {
-		from = &req.From
+// getAllByCursor — keyset-пагинация по (created_at, id), порядок сортировки фиксирован.
+func (s *SubService) getAllByCursor(ctx context.Context, filter *filters.SubFilter, page dto.PageRequest) (dto.GetAllResponse, error) {
+	if len(filter.Sort) > 0 {
+		return dto.GetAllResponse{}, fmt.Errorf("%w: sort is not supported with cursor pagination", filters.ErrInvalidCursor)
 	}
 
-	var to *time.Time
-	if !req.To.IsZero() {
-		to = &req.To
+	var cursor *filters.Cursor
+	if *page.Cursor != "" {
+		decoded, err := filters.DecodeCursor(*page.Cursor)
+		if err != nil {
+			s.logger.Warn().
+				Str("cursor", *page.Cursor).
+				Msg("Invalid subscriptions cursor")
+			return dto.GetAllResponse{}, err
+		}
+		cursor = decoded
 	}
 
-	filter := &filters.SubFilter{
-		UserID:      userID,
-		ServiceName: serviceName,
-		From:        from,
-		To:          to,
-	}
-
-	total, err := s.repo.SumSubscriptionsCost(ctx, filter)
+	subscriptions, hasMore, err := s.repo.GetAllByCursor(ctx, filter, cursor, page.PageSize)
 	if err != nil {
 		s.logger.Error().
 			Err(err).
-			Msg("Failed to calculate total cost")
-		return 0, err
+			Int64("pageSize", page.PageSize).
+			Msg("Failed to fetch subscriptions from repository")
+		return dto.GetAllResponse{}, fmt.Errorf("failed to get subscriptions: %w", err)
 	}
 
-	s.logger.Info().
-		Int64("total", total).
-		Msg("Total cost calculated")
-	return total, nil
+	pagination := &dto.PaginationInfo{PageSize: page.PageSize}
+	if len(subscriptions) > 0 {
+		first, last := subscriptions[0], subscriptions[len(subscriptions)-1]
+		backward := cursor != nil && cursor.Backward
+
+		// В направлении движения записи есть, если hasMore; в обратном — если страница не первая
+		if backward || hasMore {
+			pagination.NextCursor = filters.Cursor{CreatedAt: last.CreatedAt, Id: last.Id}.Encode()
+		}
+		if (backward && hasMore) || (!backward && cursor != nil) {
+			pagination.PrevCursor = filters.Cursor{CreatedAt: first.CreatedAt, Id: first.Id, Backward: true}.Encode()
+		}
+	}
+
+	return dto.GetAllResponse{
+		Data:       subscriptions,
+		Pagination: pagination,
+	}, nil
 }
 
-func (s *SubService) applyPartialUpdate(existing *models.Subscription, request dto.UpdateSubscriptionRequest) *models.Subscription {
-	updated := &models.Subscription{
-		Id:        existing.Id,
-		UserId:    existing.UserId,
-		CreatedAt: existing.CreatedAt,
-		UpdatedAt: time.Now(),
+// newSubscription строит модель подписки по запросу на создание.
+func newSubscription(req dto.CreateSubscriptionRequest) (*models.Subscription, error) {
+	return models.NewSubscription(
+		req.ServiceName,
+		req.Price,
+		req.Currency,
+		req.UserID,
+		models.BillingInterval(req.BillingInterval),
+		req.IntervalDays,
+		models.SubscriptionState(req.State),
+		trialEnd(req),
+		req.StartDate,
+		req.EndDate,
+	)
+}
+
+// trialEnd возвращает окончание пробного периода из запроса: дату или start_date + trial_days.
+func trialEnd(req dto.CreateSubscriptionRequest) *time.Time {
+	if req.TrialDays != nil {
+		end := req.StartDate.AddDate(0, 0, *req.TrialDays)
+		return &end
 	}
+	return req.TrialEnd
+}
 
-	// Обновляем только переданные поля
-	if request.ServiceName != nil {
-		updated.ServiceName = *request.ServiceName
-	} else {
-		updated.ServiceName = existing.ServiceName
+// subFilter переводит фильтры запроса в фильтр репозитория.
+func subFilter(req dto.SubFilterQuery) *filters.SubFilter {
+	filter := &filters.SubFilter{
+		MinPrice:       req.MinPrice,
+		MaxPrice:       req.MaxPrice,
+		IncludeDeleted: req.IncludeDeleted,
 	}
 
-	if request.Price != nil {
-		updated.Price = *request.Price
-	} else {
-		updated.Price = existing.Price
+	if userID, err := uuid.Parse(req.UserID); err == nil {
+		filter.UserID = &userID
+	}
+	if req.ServiceName != "" {
+		filter.ServiceName = &req.ServiceName
+	}
+	if req.ServiceNamePrefix != "" {
+		filter.ServiceNamePrefix = &req.ServiceNamePrefix
+	}
+	if req.State != "" {
+		state := models.SubscriptionState(req.State)
+		filter.State = &state
+	}
+	if req.Status != "" {
+		status := filters.SubStatus(req.Status)
+		filter.Status = &status
+	}
+	if !req.From.IsZero() {
+		filter.From = &req.From
+	}
+	if !req.To.IsZero() {
+		filter.To = &req.To
 	}
+	return filter
+}
 
-	if request.StartDate != nil {
-		updated.StartDate = *request.StartDate
-	} else {
-		updated.StartDate = existing.StartDate
+// applyReplacement строит подписку, полностью заменяющую existing.
+// Неизменяемыми остаются идентификатор, пользователь, дата создания и версия.
+func applyReplacement(existing *models.Subscription, req dto.UpdateSubscriptionRequest) *models.Subscription {
+	updated := &models.Subscription{
+		Id:              existing.Id,
+		ServiceName:     req.ServiceName,
+		Price:           req.Price,
+		Currency:        req.Currency,
+		UserId:          existing.UserId,
+		BillingInterval: models.BillingInterval(req.BillingInterval),
+		IntervalDays:    req.IntervalDays,
+		State:           existing.State,
+		TrialEnd:        existing.TrialEnd,
+		StartDate:       req.StartDate,
+		EndDate:         req.EndDate,
+		CreatedAt:       existing.CreatedAt,
+		UpdatedAt:       time.Now(),
+		Version:         existing.Version,
 	}
 
-	if request.EndDate != nil {
-		updated.EndDate = request.EndDate
-	} else {
-		updated.EndDate = existing.EndDate
+	if updated.Currency == "" {
+		updated.Currency = models.DefaultCurrency
+	}
+	if updated.BillingInterval == "" {
+		updated.BillingInterval = models.BillingMonthly
 	}
+	updated.NormalizeInterval()
 
 	return updated
 }
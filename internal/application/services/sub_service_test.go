@@ -0,0 +1,45 @@
+package services_test
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/application/events"
+	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/persistence/memory"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var ctx = context.Background()
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// newSubService возвращает сервис подписок над хранилищем в памяти.
+func newSubService(t *testing.T) (*services.SubService, *memory.SubRepository) {
+	t.Helper()
+	logger := zerolog.Nop()
+	repo := memory.NewSubRepository()
+	service := services.NewSubService(repo, memory.NewTxManager(repo), memory.ExchangeRates{}, events.NewSyncDispatcher(&logger), 0, &logger)
+	return service, repo
+}
+
+// createSub создаёт месячную подписку в RUB; opts изменяют запрос перед созданием.
+func createSub(t *testing.T, service *services.SubService, name string, price int64, start time.Time,
+	opts ...func(req *dto.CreateSubscriptionRequest)) *models.Subscription {
+	t.Helper()
+	req := dto.CreateSubscriptionRequest{ServiceName: name, Price: price, UserID: uuid.New(), StartDate: start}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	sub, err := service.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	return sub
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"SubscriptionService/internal/api/dto"
+	appInterfaces "SubscriptionService/internal/application/app_interfaces"
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Сколько доставок захватывается за раз и на сколько
+const (
+	webhookBatchSize = 50
+	webhookLease     = 2 * time.Minute
+)
+
+type WebhookService struct {
+	repo   core_interfaces.IWebhookRepository
+	sender core_interfaces.IWebhookSender
+	logger *zerolog.Logger
+}
+
+var _ appInterfaces.IWebhookService = (*WebhookService)(nil)
+
+func NewWebhookService(
+	repo core_interfaces.IWebhookRepository,
+	sender core_interfaces.IWebhookSender,
+	logger *zerolog.Logger) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		sender: sender,
+		logger: logger,
+	}
+}
+
+func (s *WebhookService) CreateEndpoint(ctx context.Context, req dto.WebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	active := req.Active == nil || *req.Active
+	endpoint, err := models.NewWebhookEndpoint(req.URL, req.Secret, models.ToOperations(req.Events), active)
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Str("url", req.URL).
+			Msg("Webhook endpoint validation failed")
+		return nil, fmt.Errorf("invalid webhook endpoint: %w", err)
+	}
+
+	created, err := s.repo.CreateEndpoint(ctx, endpoint)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("url", req.URL).
+			Msg("Failed to create webhook endpoint")
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	s.logger.Info().
+		Str("endpointId", created.Id.String()).
+		Str("url", created.URL).
+		Msg("Webhook endpoint created")
+	return created, nil
+}
+
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, id uuid.UUID, req dto.WebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	existing, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Str("endpointId", id.String()).
+			Msg("Webhook endpoint to update not found")
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	existing.URL = req.URL
+	existing.Secret = req.Secret
+	existing.Events = models.ToOperations(req.Events)
+	existing.Active = req.Active == nil || *req.Active
+	existing.UpdatedAt = time.Now()
+	if err := existing.Validate(); err != nil {
+		s.logger.Warn().
+			Err(err).
+			Str("endpointId", id.String()).
+			Msg("Webhook endpoint validation failed")
+		return nil, fmt.Errorf("invalid webhook endpoint: %w", err)
+	}
+
+	updated, err := s.repo.UpdateEndpoint(ctx, existing)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("endpointId", id.String()).
+			Msg("Failed to update webhook endpoint")
+		return nil, fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+
+	s.logger.Info().
+		Str("endpointId", id.String()).
+		Msg("Webhook endpoint updated")
+	return updated, nil
+}
+
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteEndpoint(ctx, id); err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("endpointId", id.String()).
+			Msg("Failed to delete webhook endpoint")
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	s.logger.Info().
+		Str("endpointId", id.String()).
+		Msg("Webhook endpoint deleted")
+	return nil
+}
+
+func (s *WebhookService) GetEndpoint(ctx context.Context, id uuid.UUID) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("endpointId", id.String()).
+			Msg("Failed to get webhook endpoint")
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return endpoint, nil
+}
+
+func (s *WebhookService) GetEndpoints(ctx context.Context) (dto.WebhookEndpointsResponse, error) {
+	endpoints, err := s.repo.GetEndpoints(ctx)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("Failed to get webhook endpoints")
+		return dto.WebhookEndpointsResponse{}, fmt.Errorf("failed to get webhook endpoints: %w", err)
+	}
+	return dto.WebhookEndpointsResponse{Data: endpoints}, nil
+}
+
+// GetDeliveries возвращает доставки от новых к старым.
+func (s *WebhookService) GetDeliveries(ctx context.Context, req dto.DeliveriesQueryRequest, page dto.PageRequest) (dto.DeliveriesResponse, error) {
+	filter := &filters.DeliveryFilter{}
+	if req.EndpointID != "" {
+		endpointID, err := uuid.Parse(req.EndpointID)
+		if err != nil {
+			return dto.DeliveriesResponse{}, fmt.Errorf("%w: invalid endpoint_id format", models.ErrInvalidArgument)
+		}
+		filter.EndpointID = &endpointID
+	}
+	if req.Status != "" {
+		status := models.DeliveryStatus(req.Status)
+		filter.Status = &status
+	}
+
+	total, err := s.repo.CountDeliveries(ctx, filter)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("Failed to count webhook deliveries")
+		return dto.DeliveriesResponse{}, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	deliveries, err := s.repo.GetDeliveries(ctx, filter, page.Page, page.PageSize)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("Failed to fetch webhook deliveries")
+		return dto.DeliveriesResponse{}, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	totalPages := int64(math.Ceil(float64(total) / float64(page.PageSize)))
+	return dto.DeliveriesResponse{
+		Data: deliveries,
+		Pagination: &dto.PaginationInfo{
+			Page:       page.Page,
+			PageSize:   page.PageSize,
+			TotalCount: &total,
+			TotalPages: &totalPages,
+		},
+	}, nil
+}
+
+func (s *WebhookService) Redeliver(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	delivery, err := s.repo.Redeliver(ctx, id, time.Now())
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Int64("deliveryId", id).
+			Msg("Failed to redeliver webhook")
+		return nil, fmt.Errorf("failed to redeliver webhook: %w", err)
+	}
+
+	s.logger.Info().
+		Int64("deliveryId", id).
+		Msg("Webhook delivery queued for redelivery")
+	return delivery, nil
+}
+
+// DispatchDue отправляет доставки, время попытки которых наступило. Неудачная попытка
+// откладывается с экспоненциальной задержкой (models.RetryDelay), а после
+// models.MaxWebhookAttempts попыток доставка попадает в список недоставленных.
+func (s *WebhookService) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	delivered := 0
+	for {
+		deliveries, err := s.repo.Claim(ctx, now, webhookBatchSize, webhookLease)
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Msg("Dispatch webhooks: claim failed")
+			return delivered, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+		}
+
+		for _, delivery := range deliveries {
+			status, err := s.sender.Send(ctx, delivery)
+			if err == nil {
+				if err := s.repo.MarkDelivered(ctx, delivery.Id, status, time.Now()); err != nil {
+					return delivered, fmt.Errorf("failed to mark webhook delivered: %w", err)
+				}
+				delivered++
+				continue
+			}
+
+			var statusCode *int
+			if status != 0 {
+				statusCode = &status
+			}
+			var retryAt *time.Time
+			if delivery.Attempts < models.MaxWebhookAttempts {
+				next := now.Add(models.RetryDelay(delivery.Attempts))
+				retryAt = &next
+			}
+			s.logger.Warn().
+				Err(err).
+				Int64("deliveryId", delivery.Id).
+				Str("endpointId", delivery.EndpointId.String()).
+				Int("attempt", delivery.Attempts).
+				Bool("dead", retryAt == nil).
+				Msg("Dispatch webhooks: send failed")
+			if err := s.repo.MarkFailed(ctx, delivery.Id, statusCode, err.Error(), retryAt); err != nil {
+				return delivered, fmt.Errorf("failed to mark webhook failed: %w", err)
+			}
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			break
+		}
+	}
+
+	if delivered > 0 {
+		s.logger.Info().
+			Int("delivered", delivered).
+			Msg("Webhooks delivered")
+	}
+	return delivered, nil
+}
@@ -0,0 +1,195 @@
+package services_test
+
+import (
+	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// webhookQueue — очередь доставок в памяти: Claim выдаёт ожидающие доставки,
+// время попытки которых наступило, и увеличивает счётчик попыток, как хранилище.
+type webhookQueue struct {
+	deliveries []*models.WebhookDelivery
+	retryAt    map[int64]*time.Time
+}
+
+func (q *webhookQueue) CreateEndpoint(context.Context, *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	return nil, nil
+}
+
+func (q *webhookQueue) UpdateEndpoint(context.Context, *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	return nil, nil
+}
+
+func (q *webhookQueue) DeleteEndpoint(context.Context, uuid.UUID) error {
+	return nil
+}
+
+func (q *webhookQueue) GetEndpoint(context.Context, uuid.UUID) (*models.WebhookEndpoint, error) {
+	return nil, nil
+}
+
+func (q *webhookQueue) GetEndpoints(context.Context) ([]*models.WebhookEndpoint, error) {
+	return nil, nil
+}
+
+func (q *webhookQueue) GetDeliveries(context.Context, *filters.DeliveryFilter, int64, int64) ([]*models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (q *webhookQueue) CountDeliveries(context.Context, *filters.DeliveryFilter) (int64, error) {
+	return 0, nil
+}
+
+func (q *webhookQueue) Redeliver(context.Context, int64, time.Time) (*models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (q *webhookQueue) Claim(_ context.Context, now time.Time, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	var claimed []*models.WebhookDelivery
+	for _, delivery := range q.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if delivery.Status != models.DeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.Attempts++
+		delivery.NextAttemptAt = now.Add(lease)
+		copied := *delivery
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (q *webhookQueue) find(id int64) *models.WebhookDelivery {
+	for _, delivery := range q.deliveries {
+		if delivery.Id == id {
+			return delivery
+		}
+	}
+	return nil
+}
+
+func (q *webhookQueue) MarkDelivered(_ context.Context, id int64, statusCode int, at time.Time) error {
+	delivery := q.find(id)
+	delivery.Status = models.DeliveryDelivered
+	delivery.LastStatusCode = &statusCode
+	delivery.DeliveredAt = &at
+	return nil
+}
+
+func (q *webhookQueue) MarkFailed(_ context.Context, id int64, statusCode *int, reason string, retryAt *time.Time) error {
+	delivery := q.find(id)
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = &reason
+	q.retryAt[id] = retryAt
+	if retryAt == nil {
+		delivery.Status = models.DeliveryDead
+		return nil
+	}
+	delivery.NextAttemptAt = *retryAt
+	return nil
+}
+
+// statusSender отвечает на доставку статусом, заданным для её URL; 0 — получатель недоступен.
+type statusSender map[string]int
+
+func (s statusSender) Send(_ context.Context, delivery *models.WebhookDelivery) (int, error) {
+	status := s[delivery.URL]
+	switch {
+	case status == 0:
+		return 0, errors.New("dial tcp: connection refused")
+	case status < 200 || status >= 300:
+		return status, errors.New("send webhook: unexpected status")
+	}
+	return status, nil
+}
+
+func TestWebhookDispatchDue(t *testing.T) {
+	logger := zerolog.Nop()
+	now := date(2024, time.March, 1)
+	queue := &webhookQueue{retryAt: make(map[int64]*time.Time)}
+	for i, tt := range []struct {
+		url      string
+		attempts int
+	}{
+		{"https://ok.example", 0},
+		{"https://error.example", 0},
+		{"https://error.example", 3},
+		{"https://down.example", models.MaxWebhookAttempts - 1},
+	} {
+		queue.deliveries = append(queue.deliveries, &models.WebhookDelivery{
+			Id:            int64(i + 1),
+			EndpointId:    uuid.New(),
+			URL:           tt.url,
+			Status:        models.DeliveryPending,
+			Attempts:      tt.attempts,
+			NextAttemptAt: now,
+		})
+	}
+	sender := statusSender{"https://ok.example": http.StatusOK, "https://error.example": http.StatusInternalServerError}
+	service := services.NewWebhookService(queue, sender, &logger)
+
+	delivered, err := service.DispatchDue(ctx, now)
+	if err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+	if delivered != 1 {
+		t.Errorf("delivered = %d, want 1", delivered)
+	}
+
+	if got := queue.find(1); got.Status != models.DeliveryDelivered || *got.LastStatusCode != http.StatusOK {
+		t.Errorf("delivery 1: status %s, code %v", got.Status, got.LastStatusCode)
+	}
+	// Задержка растёт с числом попыток
+	for id, attempts := range map[int64]int{2: 1, 3: 4} {
+		got := queue.find(id)
+		want := now.Add(models.RetryDelay(attempts))
+		if got.Status != models.DeliveryPending || queue.retryAt[id] == nil || !queue.retryAt[id].Equal(want) {
+			t.Errorf("delivery %d: status %s, retry at %v, want pending at %s", id, got.Status, queue.retryAt[id], want)
+		}
+		if got.LastStatusCode == nil || *got.LastStatusCode != http.StatusInternalServerError {
+			t.Errorf("delivery %d: last status code %v, want 500", id, got.LastStatusCode)
+		}
+	}
+	// Последняя попытка: доставка уходит в недоставленные без кода ответа
+	if got := queue.find(4); got.Status != models.DeliveryDead || got.LastStatusCode != nil || got.LastError == nil {
+		t.Errorf("delivery 4: status %s, code %v, error %v; want dead", got.Status, got.LastStatusCode, got.LastError)
+	}
+
+	// До наступления времени повтора доставки не отправляются
+	if delivered, err := service.DispatchDue(ctx, now.Add(time.Second)); err != nil || delivered != 0 {
+		t.Errorf("second pass: delivered %d, err %v", delivered, err)
+	}
+	if queue.find(2).Attempts != 1 {
+		t.Errorf("delivery 2 was retried before its retry time")
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{10, 4*time.Hour + 16*time.Minute},
+		{11, 6 * time.Hour},
+		{50, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := models.RetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("RetryDelay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
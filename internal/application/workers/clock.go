@@ -0,0 +1,21 @@
+package workers
+
+import "time"
+
+// Clock — источник времени для фоновых задач; в тестах подменяется управляемыми часами.
+type Clock interface {
+	Now() time.Time
+	// After возвращает канал, в который придёт время через d
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock — системные часы.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
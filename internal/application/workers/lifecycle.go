@@ -0,0 +1,36 @@
+package workers
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Lifecycle — автоматические переходы состояний подписок.
+type Lifecycle interface {
+	// ExpireDue переводит подписки, закончившиеся к now, в expired
+	ExpireDue(ctx context.Context, now time.Time) (int64, error)
+	// ConvertDueTrials переводит подписки, пробный период которых закончился к now, в active
+	ConvertDueTrials(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Transitions возвращает проход автоматических переходов состояний для Worker.
+// Сначала истекают закончившиеся подписки, чтобы подписка, закончившаяся
+// в пробный период, не стала платной. Изменения записываются от имени system:lifecycle.
+func Transitions(lifecycle Lifecycle) Dispatcher {
+	return DispatcherFunc(func(ctx context.Context, now time.Time) (int, error) {
+		ctx = models.WithAuditInfo(ctx, models.AuditInfo{Actor: "system:lifecycle"})
+
+		expired, expireErr := lifecycle.ExpireDue(ctx, now)
+		if expireErr != nil {
+			expireErr = fmt.Errorf("expire: %w", expireErr)
+		}
+		converted, convertErr := lifecycle.ConvertDueTrials(ctx, now)
+		if convertErr != nil {
+			convertErr = fmt.Errorf("trial conversion: %w", convertErr)
+		}
+		return int(expired + converted), errors.Join(expireErr, convertErr)
+	})
+}
@@ -0,0 +1,90 @@
+package workers_test
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/application/events"
+	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/application/workers"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/persistence/memory"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestLifecycleWorkerFollowsClock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := zerolog.Nop()
+	repo := memory.NewSubRepository()
+	service := services.NewSubService(repo, memory.NewTxManager(repo), memory.ExchangeRates{}, events.NewSyncDispatcher(&logger), 0, &logger)
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	create := func(req dto.CreateSubscriptionRequest) *models.Subscription {
+		t.Helper()
+		req.Price, req.UserID, req.StartDate = 400, uuid.New(), start
+		sub, err := service.Create(ctx, req)
+		if err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+		return sub
+	}
+	trialDays := 14
+	end := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+	trial := create(dto.CreateSubscriptionRequest{ServiceName: "Netflix", TrialDays: &trialDays})
+	ending := create(dto.CreateSubscriptionRequest{ServiceName: "Spotify", EndDate: &end})
+
+	clock := newFakeClock(time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC))
+	worker := workers.NewWorker("lifecycle", workers.Transitions(service), clock, time.Hour, &logger)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+
+	states := func() (models.SubscriptionState, models.SubscriptionState) {
+		t.Helper()
+		var got [2]models.SubscriptionState
+		for i, id := range []uuid.UUID{trial.Id, ending.Id} {
+			sub, err := service.GetById(ctx, id, false)
+			if err != nil {
+				t.Fatalf("get subscription: %v", err)
+			}
+			got[i] = sub.State
+		}
+		return got[0], got[1]
+	}
+
+	tick := clock.pass(t)
+	if trialState, endingState := states(); trialState != models.StateTrial || endingState != models.StateActive {
+		t.Fatalf("after first pass: states %s, %s", trialState, endingState)
+	}
+
+	steps := []struct {
+		now           time.Time
+		trial, ending models.SubscriptionState
+	}{
+		{time.Date(2024, time.January, 14, 23, 0, 0, 0, time.UTC), models.StateTrial, models.StateActive},
+		{time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), models.StateActive, models.StateActive},
+		{time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC), models.StateActive, models.StateExpired},
+	}
+	for _, step := range steps {
+		clock.set(step.now)
+		tick <- step.now
+		tick = clock.pass(t)
+		if trialState, endingState := states(); trialState != step.trial || endingState != step.ending {
+			t.Fatalf("at %s: states %s, %s; want %s, %s", step.now.Format(time.DateTime),
+				trialState, endingState, step.trial, step.ending)
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not stop after cancel")
+	}
+}
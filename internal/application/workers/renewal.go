@@ -0,0 +1,19 @@
+package workers
+
+import (
+	"context"
+	"time"
+)
+
+// Renewer — создаёт списания за наступившие периоды оплаты.
+type Renewer interface {
+	RenewDue(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Renewal возвращает проход продления подписок для Worker.
+func Renewal(renewer Renewer) Dispatcher {
+	return DispatcherFunc(func(ctx context.Context, now time.Time) (int, error) {
+		created, err := renewer.RenewDue(ctx, now)
+		return int(created), err
+	})
+}
@@ -0,0 +1,127 @@
+package workers_test
+
+import (
+	"SubscriptionService/internal/api/dto"
+	"SubscriptionService/internal/application/events"
+	"SubscriptionService/internal/application/services"
+	"SubscriptionService/internal/application/workers"
+	"SubscriptionService/internal/persistence/memory"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// fakeClock — управляемые часы: время двигает тест, а каждый вызов After
+// означает, что проход воркера завершён, и отдаёт тесту канал следующего тика.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits chan chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waits: make(chan chan time.Time)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(time.Duration) <-chan time.Time {
+	tick := make(chan time.Time, 1)
+	c.waits <- tick
+	return tick
+}
+
+func (c *fakeClock) set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// pass ждёт, пока воркер завершит текущий проход, и возвращает канал следующего тика.
+func (c *fakeClock) pass(t *testing.T) chan time.Time {
+	t.Helper()
+	select {
+	case tick := <-c.waits:
+		return tick
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not finish the pass")
+		return nil
+	}
+}
+
+func TestRenewalWorkerChargesOncePerPeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := zerolog.Nop()
+	repo := memory.NewSubRepository()
+	service := services.NewSubService(repo, memory.NewTxManager(repo), memory.ExchangeRates{}, events.NewSyncDispatcher(&logger), 0, &logger)
+	sub, err := service.Create(ctx, dto.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       400,
+		UserID:      uuid.New(),
+		StartDate:   time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+
+	clock := newFakeClock(time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC))
+	worker := workers.NewWorker("renewal", workers.Renewal(service), clock, time.Hour, &logger)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+
+	charges := func() int {
+		t.Helper()
+		resp, err := service.GetCharges(ctx, sub.Id, dto.PageRequest{Page: 1, PageSize: 100})
+		if err != nil {
+			t.Fatalf("get charges: %v", err)
+		}
+		return len(resp.Data)
+	}
+
+	tick := clock.pass(t)
+	if got := charges(); got != 1 {
+		t.Fatalf("after first pass: charges = %d, want 1", got)
+	}
+
+	steps := []struct {
+		now  time.Time
+		want int
+	}{
+		// Повторные проходы в том же периоде не создают списаний
+		{time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2024, time.January, 31, 23, 0, 0, 0, time.UTC), 1},
+		{time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), 2},
+		{time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), 2},
+		{time.Date(2024, time.February, 20, 0, 0, 0, 0, time.UTC), 2},
+		{time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), 3},
+		{time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC), 3},
+	}
+	for _, step := range steps {
+		clock.set(step.now)
+		tick <- step.now
+		tick = clock.pass(t)
+		if got := charges(); got != step.want {
+			t.Fatalf("at %s: charges = %d, want %d", step.now.Format(time.DateTime), got, step.want)
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not stop after cancel")
+	}
+}
@@ -0,0 +1,67 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Dispatcher — один проход фоновой задачи: обрабатывает всё, что наступило к now
+// (напоминания, вебхуки, outbox, продления), и возвращает число обработанных записей.
+type Dispatcher interface {
+	DispatchDue(ctx context.Context, now time.Time) (int, error)
+}
+
+// DispatcherFunc позволяет использовать функцию как Dispatcher.
+type DispatcherFunc func(ctx context.Context, now time.Time) (int, error)
+
+func (f DispatcherFunc) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	return f(ctx, now)
+}
+
+// Worker периодически выполняет проход dispatcher. Время берётся из clock,
+// поэтому воркер можно проверить без ожидания.
+type Worker struct {
+	name       string
+	dispatcher Dispatcher
+	clock      Clock
+	interval   time.Duration
+	logger     *zerolog.Logger
+}
+
+// NewWorker создаёт воркер; name попадает в его логи.
+func NewWorker(name string, dispatcher Dispatcher, clock Clock, interval time.Duration, logger *zerolog.Logger) *Worker {
+	return &Worker{
+		name:       name,
+		dispatcher: dispatcher,
+		clock:      clock,
+		interval:   interval,
+		logger:     logger,
+	}
+}
+
+// Run выполняет проход сразу и затем каждые interval, пока не отменён ctx.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info().
+		Str("worker", w.name).
+		Dur("interval", w.interval).
+		Msg("Worker started")
+	for {
+		if _, err := w.dispatcher.DispatchDue(ctx, w.clock.Now()); err != nil && ctx.Err() == nil {
+			w.logger.Error().
+				Err(err).
+				Str("worker", w.name).
+				Msg("Worker: pass failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info().
+				Str("worker", w.name).
+				Msg("Worker stopped")
+			return
+		case <-w.clock.After(w.interval):
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Package broker — адаптеры публикации событий изменений подписок во внешние брокеры сообщений.
+package broker
+
+import (
+	"SubscriptionService/configs"
+	"SubscriptionService/internal/core/core_interfaces"
+	"fmt"
+)
+
+// New создаёт адаптер брокера, выбранный в конфигурации.
+func New(config *configs.BrokerConfig) (core_interfaces.IEventPublisher, error) {
+	switch config.Kind {
+	case "", "memory":
+		return NewMemoryPublisher(config.MemoryLimit), nil
+	case "file":
+		if config.File == "" {
+			return nil, fmt.Errorf("BROKER_FILE is required for file broker")
+		}
+		return NewFilePublisher(config.File)
+	case "nats":
+		return NewNATSPublisher(config.NATSURL, config.NATSSubjectPrefix, config.NATSStream, config.Timeout)
+	default:
+		return nil, fmt.Errorf("unknown broker %q", config.Kind)
+	}
+}
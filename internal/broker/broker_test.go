@@ -0,0 +1,86 @@
+package broker
+
+import (
+	"SubscriptionService/internal/core/models"
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func cloudEvent(seq int64) *models.CloudEvent {
+	return models.NewCloudEvent(&models.OutboxMessage{
+		Id:             seq,
+		EventId:        uuid.New(),
+		SubscriptionId: uuid.New(),
+		Operation:      models.OperationUpdate,
+		Data:           json.RawMessage(`{}`),
+	}, "test")
+}
+
+func TestMemoryPublisherKeepsLastEvents(t *testing.T) {
+	publisher := NewMemoryPublisher(3)
+	for seq := int64(1); seq <= 5; seq++ {
+		if err := publisher.Publish(context.Background(), cloudEvent(seq)); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	events := publisher.Events()
+	if len(events) != 3 {
+		t.Fatalf("events = %d, want 3", len(events))
+	}
+	for i, event := range events {
+		if want := []string{"3", "4", "5"}[i]; event.Sequence != want {
+			t.Errorf("event %d: sequence %s, want %s", i, event.Sequence, want)
+		}
+	}
+}
+
+func TestFilePublisherAppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	published := []*models.CloudEvent{cloudEvent(1), cloudEvent(2), cloudEvent(3)}
+
+	// Второй издатель дописывает в тот же файл, не затирая его
+	for _, batch := range [][]*models.CloudEvent{published[:2], published[2:]} {
+		publisher, err := NewFilePublisher(path)
+		if err != nil {
+			t.Fatalf("NewFilePublisher: %v", err)
+		}
+		for _, event := range batch {
+			if err := publisher.Publish(context.Background(), event); err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+		}
+		if err := publisher.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer file.Close()
+	var got []models.CloudEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event models.CloudEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %d: %v", len(got)+1, err)
+		}
+		got = append(got, event)
+	}
+	if len(got) != len(published) {
+		t.Fatalf("lines = %d, want %d", len(got), len(published))
+	}
+	for i, event := range got {
+		if event.Id != published[i].Id || event.Sequence != published[i].Sequence || event.PartitionKey != published[i].PartitionKey {
+			t.Errorf("line %d = %+v, want %+v", i+1, event, published[i])
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package broker
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FilePublisher дописывает события в файл по одному JSON на строку (JSON Lines).
+// Событие считается опубликованным после записи на диск.
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+var _ core_interfaces.IEventPublisher = (*FilePublisher)(nil)
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open events file: %w", err)
+	}
+	return &FilePublisher{file: file}, nil
+}
+
+func (p *FilePublisher) Publish(ctx context.Context, event *models.CloudEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal cloud event: %w", err)
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.file.Write(line); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+	if err := p.file.Sync(); err != nil {
+		return fmt.Errorf("sync events file: %w", err)
+	}
+	return nil
+}
+
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
@@ -0,0 +1,43 @@
+package broker
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"sync"
+)
+
+// MemoryPublisher хранит опубликованные события в памяти — для локального запуска и тестов.
+// Хранятся последние limit событий (0 — без ограничения).
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	limit  int
+	events []*models.CloudEvent
+}
+
+var _ core_interfaces.IEventPublisher = (*MemoryPublisher)(nil)
+
+func NewMemoryPublisher(limit int) *MemoryPublisher {
+	return &MemoryPublisher{limit: limit}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event *models.CloudEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	if p.limit > 0 && len(p.events) > p.limit {
+		p.events = p.events[len(p.events)-p.limit:]
+	}
+	return nil
+}
+
+// Events возвращает опубликованные события в порядке публикации.
+func (p *MemoryPublisher) Events() []*models.CloudEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*models.CloudEvent(nil), p.events...)
+}
+
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,84 @@
+package broker
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSPublisher публикует события в JetStream в subject "<prefix>.<вид события>"
+// (например, subscriptions.created). Событие считается опубликованным, только когда
+// поток подтвердил его сохранение (PubAck). Заголовок Nats-Msg-Id равен идентификатору
+// CloudEvent, поэтому поток отбрасывает повторные публикации одного события
+// в пределах окна дедупликации.
+type NATSPublisher struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	prefix  string
+	timeout time.Duration
+}
+
+var _ core_interfaces.IEventPublisher = (*NATSPublisher)(nil)
+
+// NewNATSPublisher подключается к NATS и создаёт поток stream для subject "<prefix>.>",
+// если его ещё нет. Настройки существующего потока не меняются.
+func NewNATSPublisher(url, prefix, stream string, timeout time.Duration) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("subscription-service"))
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("create jetstream context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if _, err := js.Stream(ctx, stream); errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: stream, Subjects: []string{prefix + ".>"}})
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("create jetstream stream %s: %w", stream, err)
+		}
+	} else if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("get jetstream stream %s: %w", stream, err)
+	}
+	return &NATSPublisher{conn: conn, js: js, prefix: prefix, timeout: timeout}, nil
+}
+
+// Publish отправляет событие в поток и ждёт подтверждения (PubAck). Без подтверждения —
+// нет потока для subject, сервер недоступен или истекло время ожидания — возвращается
+// ошибка, и событие публикуется повторно.
+func (p *NATSPublisher) Publish(ctx context.Context, event *models.CloudEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal cloud event: %w", err)
+	}
+
+	// Последний сегмент типа события: com.subscriptionservice.subscription.created → created
+	name := event.Type[strings.LastIndex(event.Type, ".")+1:]
+	msg := nats.NewMsg(p.prefix + "." + name)
+	msg.Header.Set("Content-Type", "application/cloudevents+json")
+	msg.Data = data
+
+	publishCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	if _, err := p.js.PublishMsg(publishCtx, msg, jetstream.WithMsgID(event.Id)); err != nil {
+		return fmt.Errorf("publish to jetstream: %w", err)
+	}
+	return nil
+}
+
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
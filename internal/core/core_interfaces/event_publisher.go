@@ -0,0 +1,13 @@
+package core_interfaces
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+)
+
+// IEventPublisher публикует события изменений подписок во внешний брокер сообщений.
+type IEventPublisher interface {
+	// Publish возвращает nil, только когда брокер принял событие; иначе публикация повторяется
+	Publish(ctx context.Context, event *models.CloudEvent) error
+	Close() error
+}
@@ -0,0 +1,13 @@
+package core_interfaces
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"time"
+)
+
+type IExchangeRateProvider interface {
+	// GetRate возвращает последний известный на дату at курс base→quote.
+	// Если курс не найден, возвращается models.ErrExchangeRateNotFound.
+	GetRate(ctx context.Context, base, quote string, at time.Time) (*models.ExchangeRate, error)
+}
@@ -0,0 +1,11 @@
+package core_interfaces
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+)
+
+type INotifier interface {
+	// Notify доставляет напоминание пользователю; ошибка означает, что доставку нужно повторить
+	Notify(ctx context.Context, reminder *models.Reminder) error
+}
@@ -0,0 +1,13 @@
+package core_interfaces
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+)
+
+type IOutboxRepository interface {
+	// Relay передаёт publish до limit неопубликованных сообщений в порядке их записи и удаляет
+	// опубликованные. Передача останавливается на первой ошибке publish, чтобы сохранить порядок;
+	// одновременно сообщения передаёт только один экземпляр сервиса.
+	Relay(ctx context.Context, limit int, publish func(msg *models.OutboxMessage) error) (int, error)
+}
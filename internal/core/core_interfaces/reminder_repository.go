@@ -0,0 +1,23 @@
+package core_interfaces
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IReminderRepository interface {
+	// GetUpcoming возвращает события подписок пользователя в интервале (from, until]
+	// с отметкой об отправке напоминания
+	GetUpcoming(ctx context.Context, userID uuid.UUID, from, until time.Time) ([]*models.Reminder, error)
+	// Enqueue сохраняет напоминания о событиях в интервале (from, until]; сохранённые ранее пропускаются
+	Enqueue(ctx context.Context, from, until time.Time) (int64, error)
+	// Claim захватывает до limit неотправленных напоминаний на время lease;
+	// напоминания о событиях, которые больше не наступят, пропускаются
+	Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.Reminder, error)
+	MarkSent(ctx context.Context, id int64, at time.Time) error
+	// MarkFailed снимает захват; следующая попытка — не раньше retryAt
+	MarkFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error
+}
@@ -4,15 +4,39 @@ import (
 	"SubscriptionService/internal/core/models"
 	"SubscriptionService/internal/core/ports/filters"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type ISubRepository interface {
 	Create(ctx context.Context, sub *models.Subscription) (*models.Subscription, error)
+	// CreateMany создаёт подписки в одной транзакции (при ошибке — ни одной) и возвращает их в порядке subs
+	CreateMany(ctx context.Context, subs []*models.Subscription) ([]*models.Subscription, error)
 	Update(ctx context.Context, sub *models.Subscription) (*models.Subscription, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID, version *int64) error
+	Restore(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	// ChangeState выполняет действие над подпиской; недопустимый переход — models.ErrInvalidTransition
+	ChangeState(ctx context.Context, id uuid.UUID, op models.Operation, version *int64, at time.Time) (*models.Subscription, error)
+	// ExpireDue переводит в expired подписки, закончившиеся раньше now, и возвращает их
+	ExpireDue(ctx context.Context, now time.Time) ([]*models.Subscription, error)
+	// ConvertDueTrials переводит в active пробные подписки, пробный период которых закончился к now,
+	// и возвращает их до и после перехода
+	ConvertDueTrials(ctx context.Context, now time.Time) ([]models.StateChange, error)
 	GetById(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
-	GetAll(ctx context.Context, page, pageSize int64) ([]*models.Subscription, int64, int64, error)
-	SumSubscriptionsCost(ctx context.Context, filter *filters.SubFilter) (int64, error)
+	GetAll(ctx context.Context, filter *filters.SubFilter, page, pageSize int64) ([]*models.Subscription, error)
+	GetAllByCursor(ctx context.Context, filter *filters.SubFilter, cursor *filters.Cursor, limit int64) ([]*models.Subscription, bool, error)
+	Count(ctx context.Context, filter *filters.SubFilter) (int64, error)
+	SchedulePrice(ctx context.Context, change *models.PriceChange) (*models.PriceChange, error)
+	GetPrices(ctx context.Context, id uuid.UUID) ([]*models.PriceChange, error)
+	// GetHistory и CountHistory читают журнал аудита подписки
+	GetHistory(ctx context.Context, id uuid.UUID, page, pageSize int64) ([]*models.SubscriptionEvent, error)
+	CountHistory(ctx context.Context, id uuid.UUID) (int64, error)
+	// CreateDueCharges создаёт списания за наступившие периоды не более чем limit подписок
+	CreateDueCharges(ctx context.Context, now time.Time, limit int) (int, int64, error)
+	GetCharges(ctx context.Context, id uuid.UUID, page, pageSize int64) ([]*models.Charge, error)
+	CountCharges(ctx context.Context, id uuid.UUID) (int64, error)
+	SumSubscriptionsCost(ctx context.Context, filter *filters.SubFilter) ([]*models.CostSummary, error)
+	SumSubscriptionsCostGrouped(ctx context.Context, filter *filters.SubFilter, groupBy filters.CostGroupBy) ([]*models.CostGroup, error)
 }
@@ -0,0 +1,14 @@
+package core_interfaces
+
+import "context"
+
+// ITxManager — единица работы над хранилищем: изменения всех репозиториев,
+// выполненные внутри fn с переданным ей контекстом, фиксируются или откатываются вместе.
+type ITxManager interface {
+	// WithinTx выполняет fn в транзакции, которую методы репозиториев находят в ctx.
+	// Вложенный вызов открывает точку сохранения во внешней транзакции: ошибка fn
+	// откатывает только изменения вложенного вызова. При конфликте сериализации
+	// внешняя транзакция повторяется целиком, поэтому fn не должна иметь побочных
+	// эффектов вне хранилища. Контекст fn нельзя использовать из нескольких горутин.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,30 @@
+package core_interfaces
+
+import (
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IWebhookRepository хранит получателей вебхуков и очередь доставок (outbox).
+// Доставки ставятся в очередь репозиторием подписок в транзакции изменения.
+type IWebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	// GetEndpoint и GetEndpoints не возвращают секрет получателя
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*models.WebhookEndpoint, error)
+	GetEndpoints(ctx context.Context) ([]*models.WebhookEndpoint, error)
+	GetDeliveries(ctx context.Context, filter *filters.DeliveryFilter, page, pageSize int64) ([]*models.WebhookDelivery, error)
+	CountDeliveries(ctx context.Context, filter *filters.DeliveryFilter) (int64, error)
+	// Redeliver возвращает недоставленную доставку в очередь с обнулённым счётчиком попыток
+	Redeliver(ctx context.Context, id int64, at time.Time) (*models.WebhookDelivery, error)
+	// Claim захватывает до limit доставок, время попытки которых наступило, на время lease
+	Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, statusCode int, at time.Time) error
+	// MarkFailed записывает неудачную попытку; retryAt == nil переводит доставку в dead
+	MarkFailed(ctx context.Context, id int64, statusCode *int, reason string, retryAt *time.Time) error
+}
@@ -0,0 +1,12 @@
+package core_interfaces
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+)
+
+type IWebhookSender interface {
+	// Send отправляет подписанную доставку получателю и возвращает код ответа (0, если ответа нет);
+	// ошибка означает, что доставку нужно повторить
+	Send(ctx context.Context, delivery *models.WebhookDelivery) (int, error)
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operation — вид изменения подписки в журнале аудита.
+type Operation string
+
+const (
+	OperationCreate  Operation = "create"
+	OperationUpdate  Operation = "update"
+	OperationDelete  Operation = "delete"
+	OperationRestore Operation = "restore"
+	OperationPurge   Operation = "purge"
+	// Переходы между состояниями жизненного цикла
+	OperationActivate Operation = "activate"
+	OperationPause    Operation = "pause"
+	OperationResume   Operation = "resume"
+	OperationCancel   Operation = "cancel"
+	OperationExpire   Operation = "expire"
+	// OperationSchedulePrice — изменение плана цен; снимки — models.PriceChange
+	OperationSchedulePrice Operation = "schedule_price"
+)
+
+func (o Operation) IsValid() bool {
+	switch o {
+	case OperationCreate, OperationUpdate, OperationDelete, OperationRestore, OperationPurge,
+		OperationActivate, OperationPause, OperationResume, OperationCancel, OperationExpire,
+		OperationSchedulePrice:
+		return true
+	}
+	return false
+}
+
+// ToOperations приводит имена операций к Operation без проверки.
+func ToOperations(names []string) []Operation {
+	ops := make([]Operation, len(names))
+	for i, name := range names {
+		ops[i] = Operation(name)
+	}
+	return ops
+}
+
+// SubscriptionEvent — неизменяемая запись журнала аудита подписки.
+// Before и After — снимки подписки до и после изменения (null для создания и очистки),
+// для schedule_price — снимки цены (PriceChange).
+type SubscriptionEvent struct {
+	Id             int64           `json:"id"`
+	SubscriptionId uuid.UUID       `json:"subscription_id"`
+	Operation      Operation       `json:"operation"`
+	Actor          string          `json:"actor"`
+	RequestId      string          `json:"request_id,omitempty"`
+	Before         json.RawMessage `json:"before"`
+	After          json.RawMessage `json:"after"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+}
+
+// AuditInfo — кто и в рамках какого запроса выполняет изменение.
+type AuditInfo struct {
+	Actor     string
+	RequestId string
+}
+
+// UnknownActor записывается в журнал, если инициатор изменения не передан.
+const UnknownActor = "anonymous"
+
+type auditInfoKey struct{}
+
+// WithAuditInfo кладёт сведения об инициаторе изменения в контекст.
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+// AuditInfoFrom достаёт сведения об инициаторе изменения из контекста.
+func AuditInfoFrom(ctx context.Context) AuditInfo {
+	info, _ := ctx.Value(auditInfoKey{}).(AuditInfo)
+	if info.Actor == "" {
+		info.Actor = UnknownActor
+	}
+	return info
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+var (
+	ErrBillingIntervalInvalid = NewValidationError("billing_interval", "oneof", "billing interval must be one of weekly, monthly, quarterly, yearly, custom")
+	ErrIntervalDaysRequired   = NewValidationError("interval_days", "required", "interval days must be positive for custom billing interval")
+)
+
+// BillingInterval — периодичность списания цены подписки.
+type BillingInterval string
+
+const (
+	BillingWeekly    BillingInterval = "weekly"
+	BillingMonthly   BillingInterval = "monthly"
+	BillingQuarterly BillingInterval = "quarterly"
+	BillingYearly    BillingInterval = "yearly"
+	BillingCustom    BillingInterval = "custom"
+)
+
+// Средняя длина месяца в днях (365.25 / 12)
+const avgDaysInMonth = 30.4375
+
+func (b BillingInterval) IsValid() bool {
+	switch b {
+	case BillingWeekly, BillingMonthly, BillingQuarterly, BillingYearly, BillingCustom:
+		return true
+	}
+	return false
+}
+
+// Months возвращает длину периода в месяцах. Для custom используется days.
+func (b BillingInterval) Months(days int) float64 {
+	switch b {
+	case BillingWeekly:
+		return 7 / avgDaysInMonth
+	case BillingQuarterly:
+		return 3
+	case BillingYearly:
+		return 12
+	case BillingCustom:
+		return float64(days) / avgDaysInMonth
+	default:
+		return 1
+	}
+}
+
+// Next возвращает начало следующего периода после start. Для custom используется days.
+// Месяцы прибавляются без переполнения: 31 января + 1 месяц = последний день февраля.
+func (b BillingInterval) Next(start time.Time, days int) time.Time {
+	switch b {
+	case BillingWeekly:
+		return start.AddDate(0, 0, 7)
+	case BillingQuarterly:
+		return addMonths(start, 3)
+	case BillingYearly:
+		return addMonths(start, 12)
+	case BillingCustom:
+		return start.AddDate(0, 0, days)
+	default:
+		return addMonths(start, 1)
+	}
+}
+
+func addMonths(t time.Time, months int) time.Time {
+	next := t.AddDate(0, months, 0)
+	// AddDate нормализует 31 февраля в начало марта — откатываемся к концу нужного месяца
+	if next.Day() != t.Day() {
+		next = next.AddDate(0, 0, -next.Day())
+	}
+	return next
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Charge — списание за один период оплаты подписки [PeriodStart, PeriodEnd).
+// Сумма — цена подписки, действующая на начало периода.
+type Charge struct {
+	Id             int64     `json:"id"`
+	SubscriptionId uuid.UUID `json:"subscription_id"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	Amount         int64     `json:"amount"`
+	Currency       string    `json:"currency"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PeriodEnd возвращает конец периода оплаты, начинающегося в start.
+func (s *Subscription) PeriodEnd(start time.Time) time.Time {
+	days := 0
+	if s.IntervalDays != nil {
+		days = *s.IntervalDays
+	}
+	return s.BillingInterval.Next(start, days)
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventSchemaVersion — версия схемы данных (data) публикуемых событий.
+// Увеличивается при несовместимом изменении SubscriptionEvent.
+const EventSchemaVersion = "1"
+
+// Префикс типов публикуемых событий: com.subscriptionservice.subscription.created и т. д.
+const eventTypePrefix = "com.subscriptionservice.subscription."
+
+// Тип события по операции журнала аудита
+var eventTypes = map[Operation]string{
+	OperationCreate:        "created",
+	OperationUpdate:        "updated",
+	OperationDelete:        "deleted",
+	OperationRestore:       "restored",
+	OperationPurge:         "purged",
+	OperationActivate:      "activated",
+	OperationPause:         "paused",
+	OperationResume:        "resumed",
+	OperationCancel:        "cancelled",
+	OperationExpire:        "expired",
+	OperationSchedulePrice: "price_scheduled",
+}
+
+// OutboxMessage — изменение подписки, ожидающее публикации в брокер.
+// Data — событие журнала аудита (SubscriptionEvent) в JSON.
+type OutboxMessage struct {
+	Id             int64
+	EventId        uuid.UUID
+	SubscriptionId uuid.UUID
+	Operation      Operation
+	Data           json.RawMessage
+	OccurredAt     time.Time
+}
+
+// CloudEvent — событие в формате CloudEvents 1.0 (structured mode, JSON).
+// Расширения: schemaversion — версия схемы data, partitionkey — ключ упорядочивания
+// (идентификатор подписки), sequence — номер события в outbox, возрастающий в порядке изменений.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	Id              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   string          `json:"schemaversion"`
+	PartitionKey    string          `json:"partitionkey"`
+	Sequence        string          `json:"sequence"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// NewCloudEvent оборачивает сообщение outbox в CloudEvent с источником source.
+func NewCloudEvent(msg *OutboxMessage, source string) *CloudEvent {
+	return &CloudEvent{
+		SpecVersion:     "1.0",
+		Id:              msg.EventId.String(),
+		Source:          source,
+		Type:            EventType(msg.Operation),
+		Subject:         msg.SubscriptionId.String(),
+		Time:            msg.OccurredAt,
+		DataContentType: "application/json",
+		SchemaVersion:   EventSchemaVersion,
+		PartitionKey:    msg.SubscriptionId.String(),
+		Sequence:        strconv.FormatInt(msg.Id, 10),
+		Data:            msg.Data,
+	}
+}
+
+// EventType возвращает тип CloudEvent для операции журнала аудита.
+func EventType(op Operation) string {
+	if suffix, ok := eventTypes[op]; ok {
+		return eventTypePrefix + suffix
+	}
+	return eventTypePrefix + string(op)
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewCloudEvent(t *testing.T) {
+	msg := &OutboxMessage{
+		Id:             42,
+		EventId:        uuid.New(),
+		SubscriptionId: uuid.New(),
+		Operation:      OperationCancel,
+		Data:           json.RawMessage(`{"operation":"cancel"}`),
+		OccurredAt:     time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC),
+	}
+	event := NewCloudEvent(msg, "/subscription-service")
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var attrs map[string]any
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	want := map[string]any{
+		"specversion":     "1.0",
+		"id":              msg.EventId.String(),
+		"source":          "/subscription-service",
+		"type":            "com.subscriptionservice.subscription.cancelled",
+		"subject":         msg.SubscriptionId.String(),
+		"time":            "2024-03-01T12:00:00Z",
+		"datacontenttype": "application/json",
+		"schemaversion":   EventSchemaVersion,
+		"partitionkey":    msg.SubscriptionId.String(),
+		"sequence":        "42",
+		"data":            map[string]any{"operation": "cancel"},
+	}
+	if len(attrs) != len(want) {
+		t.Errorf("attributes = %v, want %d", attrs, len(want))
+	}
+	for name, value := range want {
+		got, _ := json.Marshal(attrs[name])
+		expected, _ := json.Marshal(value)
+		if string(got) != string(expected) {
+			t.Errorf("%s = %s, want %s", name, got, expected)
+		}
+	}
+}
+
+func TestEventType(t *testing.T) {
+	tests := map[Operation]string{
+		OperationCreate:        "com.subscriptionservice.subscription.created",
+		OperationSchedulePrice: "com.subscriptionservice.subscription.price_scheduled",
+		OperationExpire:        "com.subscriptionservice.subscription.expired",
+		// Операция без своего типа публикуется под своим именем
+		Operation("archive"): "com.subscriptionservice.subscription.archive",
+	}
+	for op, want := range tests {
+		if got := EventType(op); got != want {
+			t.Errorf("EventType(%s) = %s, want %s", op, got, want)
+		}
+	}
+}
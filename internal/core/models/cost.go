@@ -0,0 +1,43 @@
+package models
+
+import "math"
+
+// CostSummary — результат расчёта стоимости подписок за период.
+// Цена каждой подписки приводится к месячному эквиваленту согласно её периодичности.
+type CostSummary struct {
+	TotalCost      int64  `json:"total_cost"`
+	BillableMonths int64  `json:"billable_months"`
+	Currency       string `json:"currency"`
+	// Amount — TotalCost до округления: суммы складываются неокруглёнными,
+	// чтобы итог разбивки совпадал с общим итогом
+	Amount float64 `json:"-"`
+	// MonthlyRate — сумма месячных эквивалентов цен подписок, попавших в период
+	MonthlyRate float64 `json:"-"`
+	// Period и NormalizedCost заполняются, если запрошено приведение к периоду
+	Period         BillingInterval `json:"period,omitempty"`
+	NormalizedCost *int64          `json:"normalized_cost,omitempty"`
+	// Rates — курсы, по которым суммы пересчитаны в Currency
+	Rates []ExchangeRate `json:"rates,omitempty"`
+}
+
+// Normalize приводит суммарную месячную стоимость подписок к указанному периоду.
+func (c *CostSummary) Normalize(period BillingInterval) {
+	normalized := int64(math.Round(c.MonthlyRate * period.Months(0)))
+	c.Period = period
+	c.NormalizedCost = &normalized
+}
+
+// Add прибавляет сумму другой валюты, пересчитанную по курсу rate.
+func (c *CostSummary) Add(other *CostSummary, rate float64) {
+	c.Amount += other.Amount * rate
+	c.TotalCost = int64(math.Round(c.Amount))
+	c.BillableMonths += other.BillableMonths
+	c.MonthlyRate += other.MonthlyRate * rate
+}
+
+// CostGroup — стоимость подписок одной группы разбивки.
+type CostGroup struct {
+	Key               string `json:"key"`
+	SubscriptionCount int64  `json:"subscription_count"`
+	CostSummary
+}
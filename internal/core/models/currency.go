@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultCurrency — валюта подписок и расчётов по умолчанию
+const DefaultCurrency = "RUB"
+
+var (
+	ErrCurrencyInvalid      = NewValidationError("currency", "iso4217", "currency must be an ISO 4217 code")
+	ErrExchangeRateNotFound = fmt.Errorf("%w: exchange rate not found", ErrValidation)
+	currencyCodePattern     = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+func IsCurrencyCode(code string) bool {
+	return currencyCodePattern.MatchString(code)
+}
+
+// ExchangeRate — курс: 1 единица Base стоит Rate единиц Quote на дату Date.
+type ExchangeRate struct {
+	Base  string    `json:"base"`
+	Quote string    `json:"quote"`
+	Rate  float64   `json:"rate"`
+	Date  time.Time `json:"date"`
+}
+
+// Invert возвращает обратный курс Quote→Base.
+func (r ExchangeRate) Invert() ExchangeRate {
+	return ExchangeRate{
+		Base:  r.Quote,
+		Quote: r.Base,
+		Rate:  1 / r.Rate,
+		Date:  r.Date,
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import "errors"
+
+// Категории ошибок домена. Конкретные ошибки оборачивают одну из них,
+// по категории API выбирает HTTP-статус.
+var (
+	// ErrInvalidArgument — некорректные параметры запроса (формат id, сортировка, курсор)
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrValidation — данные не прошли проверку правил домена
+	ErrValidation = errors.New("validation failed")
+	// ErrNotFound — запись не найдена
+	ErrNotFound = errors.New("not found")
+	// ErrConflict — операция противоречит текущему состоянию данных
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed — запись изменилась с момента чтения (не совпала версия)
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrUnavailable — хранилище или внешний сервис временно недоступны
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// ValidationError — ошибка валидации конкретного поля.
+// Code совпадает с именем правила go-playground/validator, если оно есть.
+type ValidationError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func NewValidationError(field, code, message string) *ValidationError {
+	return &ValidationError{Field: field, Code: code, Message: message}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
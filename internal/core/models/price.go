@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPriceChangeNotFuture   = NewValidationError("effective_from", "gt", "price change must take effect in the future")
+	ErrPriceChangeOutOfPeriod = NewValidationError("effective_from", "range", "price change must take effect within the subscription period")
+)
+
+// PriceChange — цена подписки, действующая с EffectiveFrom (дата без времени)
+// до следующего изменения. Первая запись — начальная цена с даты начала подписки.
+type PriceChange struct {
+	SubscriptionId uuid.UUID `json:"subscription_id"`
+	Price          int64     `json:"price"`
+	EffectiveFrom  time.Time `json:"effective_from"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// NewPriceChange создаёт запланированное изменение цены подписки sub.
+// Изменение должно вступать в силу после today и в пределах периода подписки.
+func NewPriceChange(sub *Subscription, price int64, effectiveFrom, today time.Time) (*PriceChange, error) {
+	change := &PriceChange{
+		SubscriptionId: sub.Id,
+		Price:          price,
+		EffectiveFrom:  truncateToDate(effectiveFrom),
+		CreatedAt:      time.Now(),
+	}
+
+	if price <= 0 {
+		return nil, ErrPriceInvalid
+	}
+	if !change.EffectiveFrom.After(truncateToDate(today)) {
+		return nil, ErrPriceChangeNotFuture
+	}
+	if change.EffectiveFrom.Before(truncateToDate(sub.StartDate)) ||
+		(sub.EndDate != nil && change.EffectiveFrom.After(*sub.EndDate)) {
+		return nil, ErrPriceChangeOutOfPeriod
+	}
+
+	return change, nil
+}
+
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderKind — событие подписки, о котором предупреждается пользователь.
+type ReminderKind string
+
+const (
+	// ReminderRenewal — следующее списание за период оплаты
+	ReminderRenewal ReminderKind = "renewal"
+	// ReminderTrialEnd — окончание пробного периода, после которого начинается оплата
+	ReminderTrialEnd ReminderKind = "trial_end"
+	// ReminderExpiry — окончание подписки (end_date)
+	ReminderExpiry ReminderKind = "expiry"
+)
+
+// Reminder — напоминание о событии подписки, наступающем в DueAt.
+type Reminder struct {
+	Id             int64        `json:"-"`
+	SubscriptionId uuid.UUID    `json:"subscription_id"`
+	UserId         uuid.UUID    `json:"user_id"`
+	ServiceName    string       `json:"service_name"`
+	Kind           ReminderKind `json:"kind"`
+	DueAt          time.Time    `json:"due_at"`
+	// RemindAt — когда напоминание будет отправлено
+	RemindAt time.Time `json:"remind_at"`
+	// Amount — цена подписки, действующая на DueAt
+	Amount   int64      `json:"amount"`
+	Currency string     `json:"currency"`
+	SentAt   *time.Time `json:"sent_at,omitempty"`
+	// Attempts — число попыток отправки, включая текущую
+	Attempts int `json:"-"`
+}
+
+// Повторы отправки напоминания: задержка удваивается от reminderBaseDelay до reminderMaxDelay
+const (
+	reminderBaseDelay = time.Minute
+	reminderMaxDelay  = time.Hour
+)
+
+// ReminderRetryDelay возвращает задержку перед следующей попыткой после attempts неудачных.
+func ReminderRetryDelay(attempts int) time.Duration {
+	return backoff(attempts, reminderBaseDelay, reminderMaxDelay)
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrStateInvalid      = NewValidationError("state", "oneof", "state must be one of trial, active, paused, cancelled, expired")
+	ErrInvalidTransition = fmt.Errorf("%w: state transition is not allowed", ErrConflict)
+)
+
+// SubscriptionState — состояние жизненного цикла подписки.
+type SubscriptionState string
+
+const (
+	StateTrial     SubscriptionState = "trial"
+	StateActive    SubscriptionState = "active"
+	StatePaused    SubscriptionState = "paused"
+	StateCancelled SubscriptionState = "cancelled"
+	StateExpired   SubscriptionState = "expired"
+)
+
+// Переход состояния, выполняемый действием над подпиской.
+type transition struct {
+	from []SubscriptionState
+	to   SubscriptionState
+}
+
+// Допустимые переходы. В expired подписка переводится автоматически,
+// когда проходит дата окончания.
+var transitions = map[Operation]transition{
+	OperationActivate: {from: []SubscriptionState{StateTrial}, to: StateActive},
+	OperationPause:    {from: []SubscriptionState{StateActive}, to: StatePaused},
+	OperationResume:   {from: []SubscriptionState{StatePaused}, to: StateActive},
+	OperationCancel:   {from: []SubscriptionState{StateActive}, to: StateCancelled},
+	OperationExpire:   {from: []SubscriptionState{StateTrial, StateActive, StatePaused}, to: StateExpired},
+}
+
+func (s SubscriptionState) IsValid() bool {
+	switch s {
+	case StateTrial, StateActive, StatePaused, StateCancelled, StateExpired:
+		return true
+	}
+	return false
+}
+
+// Transition выполняет действие op над подпиской в момент at.
+// При отмене подписка заканчивается в момент отмены, если не закончилась раньше;
+// досрочная активация завершает пробный период в момент активации.
+func (s *Subscription) Transition(op Operation, at time.Time) error {
+	t, ok := transitions[op]
+	if !ok || !slices.Contains(t.from, s.State) {
+		return fmt.Errorf("%w: cannot %s %s subscription", ErrInvalidTransition, op, s.State)
+	}
+
+	if t.to == StateCancelled && (s.EndDate == nil || s.EndDate.After(at)) {
+		s.EndDate = &at
+	}
+	if op == OperationActivate && s.TrialEnd != nil && s.TrialEnd.After(at) {
+		trialEnd := at
+		if trialEnd.Before(s.StartDate) {
+			trialEnd = s.StartDate
+		}
+		s.TrialEnd = &trialEnd
+	}
+	s.State = t.to
+	s.UpdatedAt = at
+	return nil
+}
+
+// StateChange — подписка до и после автоматического перехода состояния.
+type StateChange struct {
+	Before *Subscription
+	After  *Subscription
+}
@@ -1,28 +1,39 @@
 package models
 
 import (
-	"errors"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 var (
-	ErrServiceNameRequired = errors.New("service name is required")
-	ErrPriceInvalid        = errors.New("price must be positive")
-	ErrStartDateRequired   = errors.New("start date is required")
-	ErrEndDateBeforeStart  = errors.New("end date cannot be before start date")
+	ErrServiceNameRequired = NewValidationError("service_name", "required", "service name is required")
+	ErrPriceInvalid        = NewValidationError("price", "min", "price must be positive")
+	ErrStartDateRequired   = NewValidationError("start_date", "required", "start date is required")
+	ErrEndDateBeforeStart  = NewValidationError("end_date", "gtefield", "end date cannot be before start date")
+	ErrTrialEndRequired    = NewValidationError("trial_end", "required", "trial end is required for trial subscription")
+	ErrTrialEndBeforeStart = NewValidationError("trial_end", "gtefield", "trial end cannot be before start date")
+	ErrTrialNotAllowed     = NewValidationError("state", "oneof", "trial period requires trial state")
 )
 
 type Subscription struct {
-	Id          uuid.UUID  `json:"id"`
-	ServiceName string     `json:"service_name"`
-	Price       int64      `json:"price"`
-	UserId      uuid.UUID  `json:"user_id"`
-	StartDate   time.Time  `json:"start_date"`
-	EndDate     *time.Time `json:"end_date,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	Id              uuid.UUID         `json:"id"`
+	ServiceName     string            `json:"service_name"`
+	Price           int64             `json:"price"`
+	Currency        string            `json:"currency"`
+	UserId          uuid.UUID         `json:"user_id"`
+	BillingInterval BillingInterval   `json:"billing_interval"`
+	State           SubscriptionState `json:"state"`
+	// TrialEnd — окончание пробного периода; оплата начинается с этого момента
+	TrialEnd     *time.Time `json:"trial_end,omitempty"`
+	IntervalDays *int       `json:"interval_days,omitempty"`
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      *time.Time `json:"end_date,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Version      int64      `json:"version"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
 }
 
 func (s *Subscription) Validate() error {
@@ -34,10 +45,33 @@ func (s *Subscription) Validate() error {
 		return ErrPriceInvalid
 	}
 
+	if !IsCurrencyCode(s.Currency) {
+		return ErrCurrencyInvalid
+	}
+
+	if !s.State.IsValid() {
+		return ErrStateInvalid
+	}
+
+	if !s.BillingInterval.IsValid() {
+		return ErrBillingIntervalInvalid
+	}
+
+	if s.BillingInterval == BillingCustom && (s.IntervalDays == nil || *s.IntervalDays <= 0) {
+		return ErrIntervalDaysRequired
+	}
+
 	if s.StartDate.IsZero() {
 		return ErrStartDateRequired
 	}
 
+	if s.State == StateTrial && s.TrialEnd == nil {
+		return ErrTrialEndRequired
+	}
+	if s.TrialEnd != nil && s.TrialEnd.Before(s.StartDate) {
+		return ErrTrialEndBeforeStart
+	}
+
 	// Валидация опционального EndDate
 	if s.EndDate != nil && !s.EndDate.IsZero() {
 		if s.EndDate.Before(s.StartDate) {
@@ -51,19 +85,50 @@ func (s *Subscription) Validate() error {
 func NewSubscription(
 	serviceName string,
 	price int64,
+	currency string,
 	userID uuid.UUID,
+	billingInterval BillingInterval,
+	intervalDays *int,
+	state SubscriptionState,
+	trialEnd *time.Time,
 	startDate time.Time,
 	endDate *time.Time) (*Subscription, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if billingInterval == "" {
+		billingInterval = BillingMonthly
+	}
+	// Пробный период задаёт начальное состояние trial
+	if trialEnd != nil {
+		if state == "" {
+			state = StateTrial
+		}
+		if state != StateTrial {
+			return nil, ErrTrialNotAllowed
+		}
+	}
+	if state == "" {
+		state = StateActive
+	}
+
 	sub := &Subscription{
-		Id:          uuid.New(),
-		ServiceName: serviceName,
-		Price:       price,
-		UserId:      userID,
-		StartDate:   startDate,
-		EndDate:     endDate,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		Id:              uuid.New(),
+		ServiceName:     serviceName,
+		Price:           price,
+		Currency:        currency,
+		UserId:          userID,
+		BillingInterval: billingInterval,
+		IntervalDays:    intervalDays,
+		State:           state,
+		TrialEnd:        trialEnd,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		Version:         1,
 	}
+	sub.NormalizeInterval()
 
 	if err := sub.Validate(); err != nil {
 		return nil, err
@@ -72,12 +137,45 @@ func NewSubscription(
 	return sub, nil
 }
 
+// IsActive сообщает, оплачивается ли подписка сейчас: она в состоянии active
+// и её период ещё не закончился.
 func (s *Subscription) IsActive() bool {
-	now := time.Now()
+	if s.State != StateActive {
+		return false
+	}
 
+	now := time.Now()
 	if s.EndDate == nil || s.EndDate.IsZero() {
-		return true
+		return !now.Before(s.StartDate)
 	}
 
 	return now.After(s.StartDate) && now.Before(*s.EndDate)
 }
+
+// ETag возвращает сильный ETag текущей версии подписки.
+// Версия увеличивается при каждом изменении записи.
+func (s *Subscription) ETag() string {
+	return strconv.Quote(strconv.FormatInt(s.Version, 10))
+}
+
+// IsDeleted сообщает, находится ли подписка в корзине.
+func (s *Subscription) IsDeleted() bool {
+	return s.DeletedAt != nil
+}
+
+// MonthlyPrice возвращает цену подписки, приведённую к одному месяцу.
+func (s *Subscription) MonthlyPrice() float64 {
+	days := 0
+	if s.IntervalDays != nil {
+		days = *s.IntervalDays
+	}
+	return float64(s.Price) / s.BillingInterval.Months(days)
+}
+
+// NormalizeInterval сбрасывает количество дней для нестандартных периодов,
+// так как оно имеет смысл только для custom.
+func (s *Subscription) NormalizeInterval() {
+	if s.BillingInterval != BillingCustom {
+		s.IntervalDays = nil
+	}
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWebhookURLInvalid   = NewValidationError("url", "url", "url must be an absolute http or https URL")
+	ErrWebhookEventInvalid = NewValidationError("events", "oneof", "events must contain subscription operations")
+	ErrDeliveryNotDead     = fmt.Errorf("%w: only dead deliveries can be redelivered", ErrConflict)
+	ErrEndpointDeleted     = fmt.Errorf("%w: webhook endpoint is deleted", ErrConflict)
+)
+
+// WebhookEndpoint — получатель событий подписок. Events — операции журнала аудита,
+// о которых сообщать; пустой список означает все операции.
+type WebhookEndpoint struct {
+	Id  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+	// Secret — ключ подписи HMAC-SHA256; возвращается только при создании
+	Secret    string      `json:"secret,omitempty"`
+	Events    []Operation `json:"events"`
+	Active    bool        `json:"active"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// NewWebhookEndpoint создаёт получателя; при пустом secret генерируется случайный ключ.
+func NewWebhookEndpoint(rawURL, secret string, events []Operation, active bool) (*WebhookEndpoint, error) {
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(key)
+	}
+	if events == nil {
+		events = []Operation{}
+	}
+
+	now := time.Now()
+	endpoint := &WebhookEndpoint{
+		Id:        uuid.New(),
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    events,
+		Active:    active,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (e *WebhookEndpoint) Validate() error {
+	u, err := url.Parse(e.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrWebhookURLInvalid
+	}
+	for _, op := range e.Events {
+		if !op.IsValid() {
+			return ErrWebhookEventInvalid
+		}
+	}
+	return nil
+}
+
+// DeliveryStatus — состояние доставки события получателю.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryDead — попытки исчерпаны, доставка в списке недоставленных (dead letters)
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+func (s DeliveryStatus) IsValid() bool {
+	switch s {
+	case DeliveryPending, DeliveryDelivered, DeliveryDead:
+		return true
+	}
+	return false
+}
+
+// Повторы доставки: задержка удваивается от webhookBaseDelay до webhookMaxDelay,
+// после MaxWebhookAttempts попыток доставка считается недоставленной
+const (
+	MaxWebhookAttempts = 10
+	webhookBaseDelay   = 30 * time.Second
+	webhookMaxDelay    = 6 * time.Hour
+)
+
+// WebhookDelivery — доставка одного события журнала аудита одному получателю.
+// Payload — событие (SubscriptionEvent) в JSON, тело запроса к получателю.
+type WebhookDelivery struct {
+	Id             int64           `json:"id"`
+	EndpointId     uuid.UUID       `json:"endpoint_id"`
+	EventId        int64           `json:"event_id"`
+	EventType      Operation       `json:"event_type"`
+	SubscriptionId uuid.UUID       `json:"subscription_id"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	// URL и Secret получателя заполняются при захвате доставки для отправки
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// RetryDelay возвращает задержку перед следующей попыткой после attempts неудачных.
+func RetryDelay(attempts int) time.Duration {
+	return backoff(attempts, webhookBaseDelay, webhookMaxDelay)
+}
+
+// backoff удваивает задержку base с каждой неудачной попыткой, но не больше limit.
+func backoff(attempts int, base, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
@@ -0,0 +1,41 @@
+package filters
+
+import (
+	"SubscriptionService/internal/core/models"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = fmt.Errorf("%w: invalid cursor", models.ErrInvalidArgument)
+
+// Cursor — позиция в списке подписок для keyset-пагинации по (created_at, id).
+// Список в этом режиме всегда упорядочен по created_at и id по убыванию.
+type Cursor struct {
+	CreatedAt time.Time `json:"c"`
+	Id        uuid.UUID `json:"i"`
+	// Backward — выбрать страницу перед позицией, а не после неё
+	Backward bool `json:"b,omitempty"`
+}
+
+// Encode возвращает непрозрачное строковое представление курсора.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
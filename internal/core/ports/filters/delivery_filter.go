@@ -0,0 +1,13 @@
+package filters
+
+import (
+	"SubscriptionService/internal/core/models"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryFilter — фильтр доставок вебхуков.
+type DeliveryFilter struct {
+	EndpointID *uuid.UUID
+	Status     *models.DeliveryStatus
+}
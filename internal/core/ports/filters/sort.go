@@ -0,0 +1,46 @@
+package filters
+
+import (
+	"SubscriptionService/internal/core/models"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidSort = fmt.Errorf("%w: invalid sort", models.ErrInvalidArgument)
+
+// SortField — поле сортировки; Desc означает порядок по убыванию.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Поля, по которым разрешена сортировка
+var sortableFields = map[string]bool{
+	"service_name": true,
+	"price":        true,
+	"start_date":   true,
+	"end_date":     true,
+	"trial_end":    true,
+	"created_at":   true,
+	"updated_at":   true,
+	"deleted_at":   true,
+}
+
+// ParseSort разбирает строку вида "price,-start_date": минус означает убывание.
+func ParseSort(sort string) ([]SortField, error) {
+	if strings.TrimSpace(sort) == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+		if !sortableFields[name] {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSort, name)
+		}
+		fields = append(fields, SortField{Field: name, Desc: desc})
+	}
+	return fields, nil
+}
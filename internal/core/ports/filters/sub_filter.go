@@ -1,14 +1,49 @@
 package filters
 
 import (
+	"SubscriptionService/internal/core/models"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// SubFilter — фильтр подписок, общий для списка, подсчёта и расчёта стоимости.
 type SubFilter struct {
 	UserID      *uuid.UUID
 	ServiceName *string
-	From        *time.Time
-	To          *time.Time
+	// ServiceNamePrefix — префикс имени сервиса без учёта регистра
+	ServiceNamePrefix *string
+	Status            *SubStatus
+	State             *models.SubscriptionState
+	// TrialEndsBefore оставляет пробные подписки, пробный период которых заканчивается не позже момента
+	TrialEndsBefore *time.Time
+	MinPrice        *int64
+	MaxPrice        *int64
+	// From и To — период, с которым пересекается подписка
+	From *time.Time
+	To   *time.Time
+	// IncludeDeleted добавляет к выборке удалённые (находящиеся в корзине) подписки
+	IncludeDeleted bool
+	// OnlyDeleted оставляет только удалённые подписки (корзина)
+	OnlyDeleted bool
+	// Sort — порядок сортировки списка; пустой означает created_at по убыванию
+	Sort []SortField
 }
+
+// SubStatus — состояние подписки относительно текущего момента.
+type SubStatus string
+
+const (
+	StatusActive  SubStatus = "active"
+	StatusExpired SubStatus = "expired"
+)
+
+// CostGroupBy — признак группировки при разбивке стоимости подписок.
+type CostGroupBy string
+
+const (
+	GroupByServiceName CostGroupBy = "service_name"
+	GroupByUserID      CostGroupBy = "user_id"
+	GroupByMonth       CostGroupBy = "month"
+	GroupByYear        CostGroupBy = "year"
+)
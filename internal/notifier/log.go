@@ -0,0 +1,32 @@
+package notifier
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// LogNotifier пишет напоминания в журнал сервиса — для разработки и отладки.
+type LogNotifier struct {
+	logger *zerolog.Logger
+}
+
+var _ core_interfaces.INotifier = (*LogNotifier)(nil)
+
+func NewLogNotifier(logger *zerolog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, reminder *models.Reminder) error {
+	n.logger.Info().
+		Str("subscriptionId", reminder.SubscriptionId.String()).
+		Str("userId", reminder.UserId.String()).
+		Str("kind", string(reminder.Kind)).
+		Time("dueAt", reminder.DueAt).
+		Int64("amount", reminder.Amount).
+		Str("currency", reminder.Currency).
+		Msg(subject(reminder))
+	return nil
+}
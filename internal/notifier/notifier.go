@@ -0,0 +1,41 @@
+package notifier
+
+import (
+	"SubscriptionService/configs"
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// New создаёт способ доставки напоминаний, выбранный в конфигурации.
+func New(config *configs.NotifierConfig, logger *zerolog.Logger) (core_interfaces.INotifier, error) {
+	switch config.Kind {
+	case "", "log":
+		return NewLogNotifier(logger), nil
+	case "smtp":
+		return NewSMTPNotifier(config.SMTPAddr, config.SMTPUsername, config.SMTPPassword,
+			config.SMTPFrom, config.SMTPRecipient), nil
+	case "webhook":
+		if config.WebhookURL == "" {
+			return nil, fmt.Errorf("NOTIFY_WEBHOOK_URL is required for webhook notifier")
+		}
+		return NewWebhookNotifier(config.WebhookURL, 10*time.Second), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", config.Kind)
+	}
+}
+
+// subject — тема напоминания, общая для всех способов доставки.
+func subject(reminder *models.Reminder) string {
+	switch reminder.Kind {
+	case models.ReminderRenewal:
+		return fmt.Sprintf("Your %s subscription renews on %s", reminder.ServiceName, reminder.DueAt.Format(time.DateOnly))
+	case models.ReminderTrialEnd:
+		return fmt.Sprintf("Your %s trial ends on %s", reminder.ServiceName, reminder.DueAt.Format(time.DateOnly))
+	default:
+		return fmt.Sprintf("Your %s subscription ends on %s", reminder.ServiceName, reminder.DueAt.Format(time.DateOnly))
+	}
+}
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier отправляет напоминания письмом. Адрес получателя строится
+// из шаблона, в котором {user_id} заменяется идентификатором пользователя.
+type SMTPNotifier struct {
+	addr      string
+	auth      smtp.Auth
+	from      string
+	recipient string
+}
+
+var _ core_interfaces.INotifier = (*SMTPNotifier)(nil)
+
+// NewSMTPNotifier создаёт отправителя через сервер addr (host:port);
+// при пустом username письма отправляются без аутентификации.
+func NewSMTPNotifier(addr, username, password, from, recipient string) *SMTPNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPNotifier{
+		addr:      addr,
+		auth:      auth,
+		from:      from,
+		recipient: recipient,
+	}
+}
+
+func (n *SMTPNotifier) Notify(_ context.Context, reminder *models.Reminder) error {
+	to := strings.ReplaceAll(n.recipient, "{user_id}", reminder.UserId.String())
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, []string{to}, n.message(to, reminder)); err != nil {
+		return fmt.Errorf("send reminder email: %w", err)
+	}
+	return nil
+}
+
+func (n *SMTPNotifier) message(to string, reminder *models.Reminder) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject(reminder))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "%s.\r\n", subject(reminder))
+	if reminder.Kind != models.ReminderExpiry {
+		fmt.Fprintf(&b, "Amount: %d %s\r\n", reminder.Amount, reminder.Currency)
+	}
+	fmt.Fprintf(&b, "Subscription: %s\r\n", reminder.SubscriptionId)
+	return []byte(b.String())
+}
@@ -0,0 +1,181 @@
+package notifier
+
+import (
+	"SubscriptionService/internal/core/models"
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeSMTP — SMTP-сервер на локальном порту, принимающий одно письмо.
+// rcptReply — ответ на RCPT TO; с кодом 5xx письмо отклоняется.
+type fakeSMTP struct {
+	addr      string
+	rcptReply string
+	// Поля заполняются после завершения сессии (done закрыт)
+	auth string
+	from string
+	to   []string
+	data string
+	done chan struct{}
+}
+
+func startFakeSMTP(t *testing.T, rcptReply string) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	server := &fakeSMTP{addr: listener.Addr().String(), rcptReply: rcptReply, done: make(chan struct{})}
+	go func() {
+		defer close(server.done)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		server.serve(bufio.NewReader(conn), conn)
+	}()
+	return server
+}
+
+func (s *fakeSMTP) serve(r *bufio.Reader, w net.Conn) {
+	reply := func(lines ...string) {
+		_, _ = w.Write([]byte(strings.Join(lines, "\r\n") + "\r\n"))
+	}
+	reply("220 localhost ESMTP fake")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			reply("250-localhost", "250 AUTH PLAIN")
+		case "AUTH":
+			s.auth = line
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.to = append(s.to, line)
+			reply(s.rcptReply)
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.data = data.String()
+			reply("250 OK queued")
+		case "RSET", "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTP) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP session did not finish")
+	}
+}
+
+func testReminder() *models.Reminder {
+	return &models.Reminder{
+		SubscriptionId: uuid.MustParse("6f1c0a52-3a8e-4f4f-9d7e-8a1b2c3d4e5f"),
+		UserId:         uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba"),
+		ServiceName:    "Yandex Plus",
+		Kind:           models.ReminderRenewal,
+		DueAt:          time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Amount:         400,
+		Currency:       "RUB",
+	}
+}
+
+func TestSMTPNotifierSendsReminder(t *testing.T) {
+	server := startFakeSMTP(t, "250 OK")
+	notifier := NewSMTPNotifier(server.addr, "", "", "billing@example.com", "{user_id}@users.example.com")
+
+	if err := notifier.Notify(context.Background(), testReminder()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	server.wait(t)
+
+	if server.auth != "" {
+		t.Errorf("AUTH sent without username: %q", server.auth)
+	}
+	if server.from != "MAIL FROM:<billing@example.com>" {
+		t.Errorf("MAIL = %q", server.from)
+	}
+	wantTo := "RCPT TO:<60601fee-2bf1-4721-ae6f-7636e79a0cba@users.example.com>"
+	if len(server.to) != 1 || server.to[0] != wantTo {
+		t.Errorf("RCPT = %q, want [%q]", server.to, wantTo)
+	}
+	for _, want := range []string{
+		"From: billing@example.com\r\n",
+		"To: 60601fee-2bf1-4721-ae6f-7636e79a0cba@users.example.com\r\n",
+		"Subject: " + subject(testReminder()) + "\r\n",
+		"Amount: 400 RUB\r\n",
+		"Subscription: 6f1c0a52-3a8e-4f4f-9d7e-8a1b2c3d4e5f\r\n",
+	} {
+		if !strings.Contains(server.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, server.data)
+		}
+	}
+}
+
+func TestSMTPNotifierAuthenticates(t *testing.T) {
+	server := startFakeSMTP(t, "250 OK")
+	notifier := NewSMTPNotifier(server.addr, "billing", "secret", "billing@example.com", "{user_id}@users.example.com")
+
+	if err := notifier.Notify(context.Background(), testReminder()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	server.wait(t)
+
+	want := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00billing\x00secret"))
+	if server.auth != want {
+		t.Errorf("AUTH = %q, want %q", server.auth, want)
+	}
+}
+
+func TestSMTPNotifierRejectedRecipient(t *testing.T) {
+	server := startFakeSMTP(t, "550 5.1.1 No such user")
+	notifier := NewSMTPNotifier(server.addr, "", "", "billing@example.com", "{user_id}@users.example.com")
+
+	err := notifier.Notify(context.Background(), testReminder())
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Fatalf("Notify error = %v, want 550 rejection", err)
+	}
+	server.wait(t)
+	if server.data != "" {
+		t.Errorf("message sent after rejected recipient:\n%s", server.data)
+	}
+}
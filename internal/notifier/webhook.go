@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier отправляет напоминание POST-запросом с JSON-телом на заданный URL.
+// Любой ответ, кроме 2xx, считается ошибкой доставки.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+var _ core_interfaces.INotifier = (*WebhookNotifier)(nil)
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type webhookPayload struct {
+	Subject string `json:"subject"`
+	*models.Reminder
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder *models.Reminder) error {
+	body, err := json.Marshal(webhookPayload{Subject: subject(reminder), Reminder: reminder})
+	if err != nil {
+		return fmt.Errorf("marshal reminder: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send reminder webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("send reminder webhook: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
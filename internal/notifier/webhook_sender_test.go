@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWebhookSenderSignsRequest(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := &models.WebhookDelivery{
+		Id:        42,
+		EventType: models.OperationCreate,
+		Payload:   []byte(`{"operation":"create"}`),
+		URL:       server.URL,
+		Secret:    "secret",
+	}
+	status, err := NewWebhookSender(time.Second).Send(context.Background(), delivery)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Send: status %d, err %v", status, err)
+	}
+
+	if string(body) != string(delivery.Payload) {
+		t.Errorf("body = %s, want %s", body, delivery.Payload)
+	}
+	if got.Header.Get(HeaderWebhookId) != "42" || got.Header.Get(HeaderWebhookEvent) != string(models.OperationCreate) {
+		t.Errorf("id %q, event %q", got.Header.Get(HeaderWebhookId), got.Header.Get(HeaderWebhookEvent))
+	}
+	timestamp, err := strconv.ParseInt(got.Header.Get(HeaderWebhookTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp: %v", err)
+	}
+	if want := Sign("secret", timestamp, body); got.Header.Get(HeaderWebhookSignature) != want {
+		t.Errorf("signature = %q, want %q", got.Header.Get(HeaderWebhookSignature), want)
+	}
+	// Подпись зависит от секрета и от метки времени
+	if Sign("other", timestamp, body) == Sign("secret", timestamp, body) || Sign("secret", timestamp+1, body) == Sign("secret", timestamp, body) {
+		t.Error("signature does not depend on secret and timestamp")
+	}
+}
+
+func TestWebhookSenderRejectsNon2xx(t *testing.T) {
+	for _, code := range []int{http.StatusBadRequest, http.StatusGone, http.StatusServiceUnavailable} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(code)
+		}))
+		delivery := &models.WebhookDelivery{Id: 1, Payload: []byte(`{}`), URL: server.URL + "/hook", Secret: "secret"}
+		status, err := NewWebhookSender(time.Second).Send(context.Background(), delivery)
+		server.Close()
+		if err == nil || status != code {
+			t.Errorf("response %d: status %d, err %v", code, status, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// mapError оборачивает ошибку pgx категорией ошибок домена (models.Err*),
+// сохраняя исходную ошибку для логов. Уже отнесённые к категории ошибки
+// (например, возвращённые из транзакции) не оборачиваются повторно.
+func mapError(op string, err error) error {
+	if err == nil || isDomainError(err) {
+		return err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, models.ErrNotFound)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23505" || pgErr.Code == "23503":
+			// unique_violation, foreign_key_violation
+			return fmt.Errorf("%s: %w: %w", op, models.ErrConflict, err)
+		case pgErr.Code == "23514" || pgErr.Code == "23502" || pgErr.Code == "22001":
+			// check_violation, not_null_violation, string_data_right_truncation
+			return fmt.Errorf("%s: %w: %w", op, models.ErrValidation, err)
+		case pgErr.Code == "40001" || pgErr.Code == "40P01":
+			// serialization_failure, deadlock_detected: повтор транзакции не помог
+			return fmt.Errorf("%s: %w: %w", op, models.ErrConflict, err)
+		case strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "53") ||
+			strings.HasPrefix(pgErr.Code, "57P"):
+			// connection_exception, insufficient_resources, operator_intervention
+			return fmt.Errorf("%s: %w: %w", op, models.ErrUnavailable, err)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connectErr) || errors.As(err, &netErr) || pgconn.Timeout(err) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s: %w: %w", op, models.ErrUnavailable, err)
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+func isDomainError(err error) bool {
+	for _, category := range []error{
+		models.ErrInvalidArgument, models.ErrValidation, models.ErrNotFound,
+		models.ErrConflict, models.ErrPreconditionFailed, models.ErrUnavailable,
+	} {
+		if errors.Is(err, category) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// FileExchangeRateProvider — курсы валют из JSON-файла вида
+// [{"base": "USD", "quote": "RUB", "rate": 92.5, "date": "2025-01-01"}].
+type FileExchangeRateProvider struct {
+	rates []models.ExchangeRate
+}
+
+var _ core_interfaces.IExchangeRateProvider = (*FileExchangeRateProvider)(nil)
+
+type fileExchangeRate struct {
+	Base  string  `json:"base"`
+	Quote string  `json:"quote"`
+	Rate  float64 `json:"rate"`
+	Date  string  `json:"date"`
+}
+
+func NewFileExchangeRateProvider(path string) (*FileExchangeRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read exchange rates file: %w", err)
+	}
+
+	var raw []fileExchangeRate
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse exchange rates file: %w", err)
+	}
+
+	rates := make([]models.ExchangeRate, 0, len(raw))
+	for i, r := range raw {
+		date, err := time.Parse(time.DateOnly, r.Date)
+		if err != nil {
+			return nil, fmt.Errorf("exchange rate #%d: invalid date %q: %w", i, r.Date, err)
+		}
+		if !models.IsCurrencyCode(r.Base) || !models.IsCurrencyCode(r.Quote) || r.Rate <= 0 {
+			return nil, fmt.Errorf("exchange rate #%d: invalid currency pair or rate", i)
+		}
+		rates = append(rates, models.ExchangeRate{Base: r.Base, Quote: r.Quote, Rate: r.Rate, Date: date})
+	}
+
+	// Свежие курсы первыми
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Date.After(rates[j].Date)
+	})
+
+	return &FileExchangeRateProvider{rates: rates}, nil
+}
+
+// GetRate ищет прямой или обратный курс, действовавший на дату at.
+func (p *FileExchangeRateProvider) GetRate(_ context.Context, base, quote string, at time.Time) (*models.ExchangeRate, error) {
+	for _, rate := range p.rates {
+		if rate.Date.After(at) {
+			continue
+		}
+		if rate.Base == base && rate.Quote == quote {
+			found := rate
+			return &found, nil
+		}
+		if rate.Base == quote && rate.Quote == base {
+			found := rate.Invert()
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%s", models.ErrExchangeRateNotFound, base, quote)
+}
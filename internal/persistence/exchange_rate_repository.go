@@ -0,0 +1,59 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExchangeRateRepository — курсы валют из таблицы exchange_rates.
+type ExchangeRateRepository struct {
+	db *pgxpool.Pool
+}
+
+var _ core_interfaces.IExchangeRateProvider = (*ExchangeRateRepository)(nil)
+
+func NewExchangeRateRepository(db *pgxpool.Pool) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+const exchangeRatesTable = "exchange_rates"
+
+// GetRate ищет прямой или обратный курс, действовавший на дату at.
+func (r *ExchangeRateRepository) GetRate(ctx context.Context, base, quote string, at time.Time) (*models.ExchangeRate, error) {
+	query := psql.Select("base_currency", "quote_currency", "rate::float8", "rate_date").
+		From(exchangeRatesTable).
+		Where(squirrel.Or{
+			squirrel.Eq{"base_currency": base, "quote_currency": quote},
+			squirrel.Eq{"base_currency": quote, "quote_currency": base},
+		}).
+		Where(squirrel.LtOrEq{"rate_date": at}).
+		OrderBy("rate_date DESC").
+		Limit(1)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build exchange rate query: %w", err)
+	}
+
+	var rate models.ExchangeRate
+	err = conn(ctx, r.db).QueryRow(ctx, sqlStr, args...).Scan(&rate.Base, &rate.Quote, &rate.Rate, &rate.Date)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s/%s", models.ErrExchangeRateNotFound, base, quote)
+		}
+		return nil, mapError("get exchange rate", err)
+	}
+
+	if rate.Base != base {
+		rate = rate.Invert()
+	}
+	return &rate, nil
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// CreateMany --- BULK INSERT ---
+// Все подписки проверяются до вставки, поэтому при ошибке не создаётся ни одна.
+func (r *SubRepository) CreateMany(ctx context.Context, subs []*models.Subscription) ([]*models.Subscription, error) {
+	const op = "bulk insert subscriptions"
+	rows := make([]*models.Subscription, len(subs))
+	for i, sub := range subs {
+		rows[i] = cloneSub(sub)
+		normalizeTimes(rows[i])
+		if err := checkConstraints(rows[i]); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	defer r.lock(ctx)()
+
+	seen := make(map[uuid.UUID]bool, len(rows))
+	for _, row := range rows {
+		if _, ok := r.subs[row.Id]; ok || seen[row.Id] {
+			return nil, fmt.Errorf("%s: %w: subscription %s already exists", op, models.ErrConflict, row.Id)
+		}
+		seen[row.Id] = true
+	}
+
+	created := make([]*models.Subscription, len(rows))
+	for i, row := range rows {
+		r.subs[row.Id] = row
+		created[i] = r.view(row)
+
+		// Начальная цена действует с даты начала подписки
+		r.upsertPrice(row.Id, row.Price, row.StartDate)
+		r.recordEvent(ctx, models.OperationCreate, row.Id, nil, created[i])
+	}
+	return created, nil
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Наибольшее число периодов, списываемых по одной подписке за проход
+const maxPeriodsPerRun = 100
+
+// nextCharge — начало следующего неоплаченного периода подписки row:
+// конец последнего списания, а без списаний — начало оплаты (после пробного периода).
+// Период, в котором подписка была возобновлена после паузы, начинается с момента возобновления.
+func (r *SubRepository) nextCharge(row *models.Subscription) time.Time {
+	next := billingStart(row)
+	if charges := r.charges[row.Id]; len(charges) > 0 {
+		next = charges[0].PeriodEnd
+		for _, charge := range charges[1:] {
+			if charge.PeriodEnd.After(next) {
+				next = charge.PeriodEnd
+			}
+		}
+	}
+	for _, p := range r.pauses[row.Id] {
+		if p.ResumedAt != nil && p.ResumedAt.After(next) {
+			next = *p.ResumedAt
+		}
+	}
+	return next
+}
+
+// CreateDueCharges --- RENEW ---
+// Создаёт списания за наступившие периоды оплаты активных подписок, обрабатывая
+// не больше limit подписок. Возвращает число обработанных подписок и созданных списаний.
+func (r *SubRepository) CreateDueCharges(ctx context.Context, now time.Time, limit int) (int, int64, error) {
+	defer r.lock(ctx)()
+
+	type dueSub struct {
+		row  *models.Subscription
+		next time.Time
+	}
+	var due []dueSub
+	for _, row := range r.subs {
+		if row.State != models.StateActive || row.DeletedAt != nil {
+			continue
+		}
+		next := r.nextCharge(row)
+		if next.After(now) || (row.EndDate != nil && !next.Before(*row.EndDate)) {
+			continue
+		}
+		due = append(due, dueSub{row: row, next: next})
+	}
+	slices.SortFunc(due, func(a, b dueSub) int {
+		if c := a.next.Compare(b.next); c != 0 {
+			return c
+		}
+		return compareCreated(a.row, b.row)
+	})
+	due = due[:min(max(limit, 0), len(due))]
+
+	var created int64
+	for _, d := range due {
+		created += r.insertCharges(d.row, d.next, now)
+	}
+	return len(due), created, nil
+}
+
+// insertCharges создаёт списания подписки за периоды, начинающиеся с start и не позже now.
+// Период, за который списание уже есть, пропускается.
+func (r *SubRepository) insertCharges(row *models.Subscription, start, now time.Time) int64 {
+	var created int64
+	for i := 0; i < maxPeriodsPerRun && !start.After(now); i++ {
+		if row.EndDate != nil && !start.Before(*row.EndDate) {
+			break
+		}
+		end := row.PeriodEnd(start)
+
+		exists := slices.ContainsFunc(r.charges[row.Id], func(c *models.Charge) bool {
+			return c.PeriodStart.Equal(start)
+		})
+		if !exists {
+			r.chargeSeq++
+			r.charges[row.Id] = append(r.charges[row.Id], &models.Charge{
+				Id:             r.chargeSeq,
+				SubscriptionId: row.Id,
+				PeriodStart:    dbTime(start),
+				PeriodEnd:      dbTime(end),
+				Amount:         r.priceAt(row, dateOf(start)),
+				Currency:       row.Currency,
+				CreatedAt:      dbTime(time.Now()),
+			})
+			created++
+		}
+		start = end
+	}
+	return created
+}
+
+// GetCharges --- CHARGES ---
+// Возвращает списания подписки от новых периодов к старым.
+func (r *SubRepository) GetCharges(ctx context.Context, id uuid.UUID, page, pageSize int64) ([]*models.Charge, error) {
+	defer r.rlock(ctx)()
+
+	charges := slices.Clone(r.charges[id])
+	slices.SortFunc(charges, func(a, b *models.Charge) int {
+		return b.PeriodStart.Compare(a.PeriodStart)
+	})
+
+	selected := paginate(charges, page, pageSize)
+	result := make([]*models.Charge, 0, len(selected))
+	for _, charge := range selected {
+		c := *charge
+		result = append(result, &c)
+	}
+	return result, nil
+}
+
+// CountCharges --- CHARGES COUNT ---
+func (r *SubRepository) CountCharges(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer r.rlock(ctx)()
+
+	return int64(len(r.charges[id])), nil
+}
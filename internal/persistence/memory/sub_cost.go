@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// SumSubscriptionsCost --- SUM (Filter) ---
+// Правила расчёта совпадают с адаптером PostgreSQL: каждая подписка тарифицируется
+// один раз за каждый календарный месяц, пересекающийся с периодом [from, to],
+// по месячному эквиваленту цены, действовавшей в этом месяце, за вычетом доли
+// месяца, пришедшейся на пробный период и паузы.
+func (r *SubRepository) SumSubscriptionsCost(ctx context.Context, filter *filters.SubFilter) ([]*models.CostSummary, error) {
+	groups := r.sumCost(ctx, filter, func(*models.Subscription, time.Time) string { return "" })
+
+	summaries := make([]*models.CostSummary, 0, len(groups))
+	for _, group := range groups {
+		summaries = append(summaries, &group.CostSummary)
+	}
+	return summaries, nil
+}
+
+// SumSubscriptionsCostGrouped --- SUM (Filter, Group) ---
+func (r *SubRepository) SumSubscriptionsCostGrouped(ctx context.Context, filter *filters.SubFilter, groupBy filters.CostGroupBy) ([]*models.CostGroup, error) {
+	var key func(sub *models.Subscription, period time.Time) string
+	switch groupBy {
+	case filters.GroupByServiceName:
+		key = func(sub *models.Subscription, _ time.Time) string { return sub.ServiceName }
+	case filters.GroupByUserID:
+		key = func(sub *models.Subscription, _ time.Time) string { return sub.UserId.String() }
+	case filters.GroupByMonth:
+		key = func(_ *models.Subscription, period time.Time) string { return period.Format("2006-01") }
+	case filters.GroupByYear:
+		key = func(_ *models.Subscription, period time.Time) string { return period.Format("2006") }
+	default:
+		return nil, fmt.Errorf("unsupported cost grouping %q", groupBy)
+	}
+
+	return r.sumCost(ctx, filter, key), nil
+}
+
+// billingStart — начало оплаты подписки: дни пробного периода не оплачиваются.
+func billingStart(sub *models.Subscription) time.Time {
+	if sub.TrialEnd != nil {
+		return *sub.TrialEnd
+	}
+	return sub.StartDate
+}
+
+// monthStart — начало месяца момента t в часовом поясе сессии базы (UTC).
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// sumCost агрегирует стоимость по ключу key и валюте: сначала по подписке
+// внутри группы, затем по группе.
+func (r *SubRepository) sumCost(ctx context.Context, filter *filters.SubFilter, key func(sub *models.Subscription, period time.Time) string) []*models.CostGroup {
+	defer r.rlock(ctx)()
+
+	to := time.Now()
+	if filter.To != nil && !filter.To.IsZero() {
+		to = *filter.To
+	}
+	var from *time.Time
+	if filter.From != nil && !filter.From.IsZero() {
+		from = filter.From
+	}
+
+	type groupKey struct{ key, currency string }
+	type subCost struct {
+		cost    float64
+		months  int64
+		monthly float64
+	}
+	type groupCost struct {
+		cost    float64
+		months  int64
+		monthly float64
+		subs    int64
+	}
+	groups := make(map[groupKey]*groupCost)
+
+	for _, sub := range r.selectSubs(filter) {
+		start := billingStart(sub)
+		// Подписка, закончившаяся в пробный период, не оплачивается
+		if start.After(to) || (sub.EndDate != nil && sub.EndDate.Before(start)) {
+			continue
+		}
+
+		first := start
+		if from != nil && from.After(first) {
+			first = *from
+		}
+		last := to
+		if sub.EndDate != nil && sub.EndDate.Before(last) {
+			last = *sub.EndDate
+		}
+
+		days := 0
+		if sub.IntervalDays != nil {
+			days = *sub.IntervalDays
+		}
+		row := r.subs[sub.Id]
+
+		perSub := make(map[string]*subCost)
+		for period := monthStart(first); !period.After(monthStart(last)); period = period.AddDate(0, 1, 0) {
+			// Момент оплаты месяца: его начало или начало оплаты подписки в первом месяце
+			billedAt := period
+			if start.After(billedAt) {
+				billedAt = start
+			}
+			// Месяц окончания пробного периода оплачивается с этого момента;
+			// месяц, целиком проведённый на паузе, не оплачивается
+			monthEnd := period.AddDate(0, 1, 0)
+			billableFrom := period
+			if sub.TrialEnd != nil && sub.TrialEnd.After(billableFrom) {
+				billableFrom = *sub.TrialEnd
+			}
+			billable := monthEnd.Sub(billableFrom) - r.pausedWithin(sub.Id, billableFrom, monthEnd)
+			share := billable.Seconds() / monthEnd.Sub(period).Seconds()
+			if share <= 0 {
+				continue
+			}
+
+			monthly := float64(r.priceAt(row, sessionDate(billedAt))) / sub.BillingInterval.Months(days)
+			k := key(sub, period)
+			total, ok := perSub[k]
+			if !ok {
+				total = &subCost{}
+				perSub[k] = total
+			}
+			total.cost += monthly * share
+			total.months++
+			total.monthly = max(total.monthly, monthly)
+		}
+
+		for k, total := range perSub {
+			gk := groupKey{key: k, currency: sub.Currency}
+			group, ok := groups[gk]
+			if !ok {
+				group = &groupCost{}
+				groups[gk] = group
+			}
+			group.cost += total.cost
+			group.months += total.months
+			group.monthly += total.monthly
+			group.subs++
+		}
+	}
+
+	result := make([]*models.CostGroup, 0, len(groups))
+	for gk, group := range groups {
+		result = append(result, &models.CostGroup{
+			Key:               gk.key,
+			SubscriptionCount: group.subs,
+			CostSummary: models.CostSummary{
+				TotalCost:      int64(math.Round(group.cost)),
+				Amount:         group.cost,
+				BillableMonths: group.months,
+				Currency:       gk.currency,
+				MonthlyRate:    group.monthly,
+			},
+		})
+	}
+	slices.SortFunc(result, func(a, b *models.CostGroup) int {
+		return cmp.Or(cmp.Compare(a.Key, b.Key), cmp.Compare(a.Currency, b.Currency))
+	})
+	return result
+}
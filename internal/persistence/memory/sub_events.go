@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// recordEvent добавляет запись в журнал аудита. Инициатор и идентификатор запроса
+// берутся из контекста (models.AuditInfoFrom). Вызывается под блокировкой на запись.
+// В отличие от адаптера PostgreSQL, событие не ставится в очередь вебхуков и брокера.
+func (r *SubRepository) recordEvent(ctx context.Context, op models.Operation, id uuid.UUID, before, after *models.Subscription) {
+	r.insertEvent(ctx, op, id, snapshot(before), snapshot(after))
+}
+
+// recordPriceEvent добавляет в журнал аудита изменение плана цен:
+// before — заменённая цена на ту же дату (если была), after — новая.
+func (r *SubRepository) recordPriceEvent(ctx context.Context, id uuid.UUID, before, after *models.PriceChange) {
+	r.insertEvent(ctx, models.OperationSchedulePrice, id, snapshot(before), snapshot(after))
+}
+
+func (r *SubRepository) insertEvent(ctx context.Context, op models.Operation, id uuid.UUID, before, after json.RawMessage) {
+	info := models.AuditInfoFrom(ctx)
+	r.eventSeq++
+	r.events = append(r.events, &models.SubscriptionEvent{
+		Id:             r.eventSeq,
+		SubscriptionId: id,
+		Operation:      op,
+		Actor:          info.Actor,
+		RequestId:      info.RequestId,
+		Before:         before,
+		After:          after,
+		OccurredAt:     dbTime(time.Now()),
+	})
+}
+
+// snapshot сериализует состояние для журнала; nil — отсутствие состояния.
+// Модели подписок и цен сериализуются без ошибок.
+func snapshot[T any](v *T) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// GetHistory --- HISTORY ---
+// Возвращает события подписки от новых к старым.
+func (r *SubRepository) GetHistory(ctx context.Context, id uuid.UUID, page, pageSize int64) ([]*models.SubscriptionEvent, error) {
+	defer r.rlock(ctx)()
+
+	var events []*models.SubscriptionEvent
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if r.events[i].SubscriptionId == id {
+			events = append(events, r.events[i])
+		}
+	}
+
+	selected := paginate(events, page, pageSize)
+	result := make([]*models.SubscriptionEvent, 0, len(selected))
+	for _, event := range selected {
+		e := *event
+		result = append(result, &e)
+	}
+	return result, nil
+}
+
+// CountHistory --- HISTORY COUNT ---
+func (r *SubRepository) CountHistory(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer r.rlock(ctx)()
+
+	var total int64
+	for _, event := range r.events {
+		if event.SubscriptionId == id {
+			total++
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// pause — интервал приостановки подписки; ResumedAt == nil — текущая пауза.
+type pause struct {
+	PausedAt  time.Time
+	ResumedAt *time.Time
+}
+
+// ChangeState --- CHANGE STATE ---
+// Выполняет действие op над подпиской. Недопустимый переход — models.ErrInvalidTransition.
+// Если version задан, переход выполняется только при совпадении версии.
+func (r *SubRepository) ChangeState(ctx context.Context, id uuid.UUID, op models.Operation, version *int64, at time.Time) (*models.Subscription, error) {
+	defer r.lock(ctx)()
+
+	before, err := r.lockSub("change state", id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("change state", before, version); err != nil {
+		return nil, err
+	}
+
+	return r.transition(ctx, r.subs[id], op, at)
+}
+
+// ExpireDue --- EXPIRE ---
+// Переводит в expired все подписки, дата окончания которых раньше now, и возвращает их.
+func (r *SubRepository) ExpireDue(ctx context.Context, now time.Time) ([]*models.Subscription, error) {
+	due := func(row *models.Subscription) bool {
+		return (row.State == models.StateTrial || row.State == models.StateActive || row.State == models.StatePaused) &&
+			row.EndDate != nil && row.EndDate.Before(now)
+	}
+	changes, err := r.transitionDue(ctx, models.OperationExpire, due, now)
+	if err != nil {
+		return nil, err
+	}
+	expired := make([]*models.Subscription, len(changes))
+	for i, change := range changes {
+		expired[i] = change.After
+	}
+	return expired, nil
+}
+
+// ConvertDueTrials --- CONVERT TRIALS ---
+// Переводит в active пробные подписки, пробный период которых закончился к now.
+func (r *SubRepository) ConvertDueTrials(ctx context.Context, now time.Time) ([]models.StateChange, error) {
+	due := func(row *models.Subscription) bool {
+		return row.State == models.StateTrial && row.TrialEnd != nil && !row.TrialEnd.After(now)
+	}
+	return r.transitionDue(ctx, models.OperationActivate, due, now)
+}
+
+// transitionDue выполняет действие op над всеми неудалёнными подписками, подходящими под due,
+// и возвращает подписки до и после перехода.
+func (r *SubRepository) transitionDue(ctx context.Context, op models.Operation, due func(*models.Subscription) bool, now time.Time) ([]models.StateChange, error) {
+	defer r.lock(ctx)()
+
+	var rows []*models.Subscription
+	for _, row := range r.subs {
+		if row.DeletedAt == nil && due(row) {
+			rows = append(rows, row)
+		}
+	}
+	slices.SortFunc(rows, compareCreated)
+
+	result := make([]models.StateChange, 0, len(rows))
+	for _, row := range rows {
+		before := r.view(row)
+		after, err := r.transition(ctx, row, op, now)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models.StateChange{Before: before, After: after})
+	}
+	return result, nil
+}
+
+// transition сохраняет переход подписки row, выполненный действием op,
+// ведёт интервалы приостановки и пишет событие в журнал аудита.
+func (r *SubRepository) transition(ctx context.Context, row *models.Subscription, op models.Operation, at time.Time) (*models.Subscription, error) {
+	before := r.view(row)
+	next := cloneSub(row)
+	if err := next.Transition(op, at); err != nil {
+		return nil, err
+	}
+	next.Version++
+	normalizeTimes(next)
+	if err := checkConstraints(next); err != nil {
+		return nil, err
+	}
+
+	r.subs[row.Id] = next
+	after := r.view(next)
+
+	at = dbTime(at)
+	if before.State == models.StatePaused {
+		for _, p := range r.pauses[row.Id] {
+			if p.ResumedAt == nil {
+				p.ResumedAt = &at
+			}
+		}
+	}
+	if after.State == models.StatePaused {
+		r.pauses[row.Id] = append(r.pauses[row.Id], &pause{PausedAt: at})
+	}
+
+	r.recordEvent(ctx, op, row.Id, before, after)
+	return after, nil
+}
+
+// pausedWithin возвращает, сколько времени из [from, to) подписка id провела на паузе.
+func (r *SubRepository) pausedWithin(id uuid.UUID, from, to time.Time) time.Duration {
+	var paused time.Duration
+	for _, p := range r.pauses[id] {
+		start, end := p.PausedAt, to
+		if p.ResumedAt != nil && p.ResumedAt.Before(end) {
+			end = *p.ResumedAt
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			paused += end.Sub(start)
+		}
+	}
+	return paused
+}
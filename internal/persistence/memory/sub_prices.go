@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// priceAt возвращает цену подписки row, действующую на дату date.
+// Без записей в истории цен используется row.Price.
+func (r *SubRepository) priceAt(row *models.Subscription, date time.Time) int64 {
+	price := row.Price
+	for _, change := range r.prices[row.Id] {
+		if change.EffectiveFrom.After(date) {
+			break
+		}
+		price = change.Price
+	}
+	return price
+}
+
+// upsertPrice записывает цену, действующую с даты effectiveFrom; цена на ту же дату заменяется.
+// Возвращает копии заменённой записи (nil, если её не было) и новой.
+func (r *SubRepository) upsertPrice(id uuid.UUID, price int64, effectiveFrom time.Time) (*models.PriceChange, *models.PriceChange) {
+	change := &models.PriceChange{
+		SubscriptionId: id,
+		Price:          price,
+		EffectiveFrom:  dateOf(effectiveFrom),
+		CreatedAt:      dbTime(time.Now()),
+	}
+
+	prices := r.prices[id]
+	i, found := slices.BinarySearchFunc(prices, change.EffectiveFrom, func(c *models.PriceChange, date time.Time) int {
+		return c.EffectiveFrom.Compare(date)
+	})
+	var replaced *models.PriceChange
+	if found {
+		replaced = prices[i]
+		prices[i] = change
+	} else {
+		prices = slices.Insert(prices, i, change)
+	}
+	r.prices[id] = prices
+
+	result := *change
+	return replaced, &result
+}
+
+// SchedulePrice --- SCHEDULE PRICE ---
+// Сохраняет изменение цены и записывает его в журнал аудита.
+func (r *SubRepository) SchedulePrice(ctx context.Context, change *models.PriceChange) (*models.PriceChange, error) {
+	defer r.lock(ctx)()
+
+	current, err := r.lockSub("schedule price", change.SubscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("schedule price", current, nil); err != nil {
+		return nil, err
+	}
+	if change.Price <= 0 {
+		return nil, fmt.Errorf("schedule price: %w: price must be positive", models.ErrValidation)
+	}
+
+	replaced, result := r.upsertPrice(change.SubscriptionId, change.Price, change.EffectiveFrom)
+	r.recordPriceEvent(ctx, change.SubscriptionId, replaced, result)
+	return result, nil
+}
+
+// GetPrices --- PRICES ---
+// Возвращает историю и план цен подписки по возрастанию даты вступления в силу.
+func (r *SubRepository) GetPrices(ctx context.Context, id uuid.UUID) ([]*models.PriceChange, error) {
+	defer r.rlock(ctx)()
+
+	prices := make([]*models.PriceChange, 0, len(r.prices[id]))
+	for _, change := range r.prices[id] {
+		c := *change
+		prices = append(prices, &c)
+	}
+	return prices, nil
+}
@@ -0,0 +1,501 @@
+// Package memory — хранилище подписок в памяти процесса с той же семантикой,
+// что и у адаптеров PostgreSQL из пакета persistence. Используется в тестах
+// и для локального запуска без базы данных (STORAGE=memory).
+package memory
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// SubRepository хранит подписки, историю цен, паузы, списания и журнал аудита.
+// Каждый метод выполняется под одной блокировкой, что соответствует транзакции
+// адаптера PostgreSQL. Наружу отдаются только копии записей.
+type SubRepository struct {
+	mu sync.RWMutex
+	// txMu удерживается единицей работы (TxManager) на всё её время;
+	// методы вне единицы работы захватывают его на чтение
+	txMu sync.RWMutex
+
+	// Записи подписок; Price — цена последнего изменения (s.price)
+	subs    map[uuid.UUID]*models.Subscription
+	prices  map[uuid.UUID][]*models.PriceChange
+	pauses  map[uuid.UUID][]*pause
+	charges map[uuid.UUID][]*models.Charge
+	events  []*models.SubscriptionEvent
+
+	chargeSeq int64
+	eventSeq  int64
+}
+
+var _ core_interfaces.ISubRepository = (*SubRepository)(nil)
+
+func NewSubRepository() *SubRepository {
+	return &SubRepository{
+		subs:    make(map[uuid.UUID]*models.Subscription),
+		prices:  make(map[uuid.UUID][]*models.PriceChange),
+		pauses:  make(map[uuid.UUID][]*pause),
+		charges: make(map[uuid.UUID][]*models.Charge),
+	}
+}
+
+// Ограничения таблицы subscriptions (см. migrations)
+const maxServiceNameLength = 100
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Create --- INSERT ---
+func (r *SubRepository) Create(ctx context.Context, sub *models.Subscription) (*models.Subscription, error) {
+	row := cloneSub(sub)
+	normalizeTimes(row)
+	if err := checkConstraints(row); err != nil {
+		return nil, fmt.Errorf("insert subscription: %w", err)
+	}
+
+	defer r.lock(ctx)()
+
+	if _, ok := r.subs[row.Id]; ok {
+		return nil, fmt.Errorf("insert subscription: %w: subscription %s already exists", models.ErrConflict, row.Id)
+	}
+	r.subs[row.Id] = row
+	result := r.view(row)
+
+	// Начальная цена действует с даты начала подписки
+	r.upsertPrice(row.Id, row.Price, row.StartDate)
+	r.recordEvent(ctx, models.OperationCreate, row.Id, nil, result)
+	return result, nil
+}
+
+// Update --- UPDATE ---
+// Обновление выполняется, только если версия совпадает с sub.Version;
+// иначе возвращается models.ErrPreconditionFailed.
+func (r *SubRepository) Update(ctx context.Context, sub *models.Subscription) (*models.Subscription, error) {
+	sub.UpdatedAt = time.Now()
+
+	defer r.lock(ctx)()
+
+	const op = "update subscription"
+	before, err := r.lockSub(op, sub.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(op, before, &sub.Version); err != nil {
+		return nil, err
+	}
+
+	next := cloneSub(r.subs[sub.Id])
+	next.ServiceName = sub.ServiceName
+	next.Price = sub.Price
+	next.Currency = sub.Currency
+	next.BillingInterval = sub.BillingInterval
+	next.IntervalDays = clonePtr(sub.IntervalDays)
+	next.StartDate = sub.StartDate
+	next.EndDate = clonePtr(sub.EndDate)
+	next.UpdatedAt = sub.UpdatedAt
+	next.Version++
+	normalizeTimes(next)
+	if err := checkConstraints(next); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Новая цена действует с сегодняшнего дня (или с начала подписки, если она ещё не началась)
+	if sub.Price != before.Price {
+		effectiveFrom := time.Now()
+		if sub.StartDate.After(effectiveFrom) {
+			effectiveFrom = sub.StartDate
+		}
+		r.upsertPrice(sub.Id, sub.Price, effectiveFrom)
+	}
+
+	r.subs[sub.Id] = next
+	result := r.view(next)
+	r.recordEvent(ctx, models.OperationUpdate, sub.Id, before, result)
+	return result, nil
+}
+
+// Delete --- SOFT DELETE ---
+// Подписка переносится в корзину (deleted_at), версия увеличивается.
+func (r *SubRepository) Delete(ctx context.Context, id uuid.UUID, version *int64) error {
+	defer r.lock(ctx)()
+
+	const op = "delete subscription"
+	before, err := r.lockSub(op, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(op, before, version); err != nil {
+		return err
+	}
+
+	row := r.subs[id]
+	deletedAt := dbTime(time.Now())
+	row.DeletedAt = &deletedAt
+	row.Version++
+	r.recordEvent(ctx, models.OperationDelete, id, before, r.view(row))
+	return nil
+}
+
+// Restore --- RESTORE ---
+// Возвращает подписку из корзины. Для неудалённой подписки — ErrConflict.
+func (r *SubRepository) Restore(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	defer r.lock(ctx)()
+
+	before, err := r.lockSub("restore subscription", id)
+	if err != nil {
+		return nil, err
+	}
+	if !before.IsDeleted() {
+		return nil, fmt.Errorf("restore subscription: %w: subscription is not deleted", models.ErrConflict)
+	}
+
+	row := r.subs[id]
+	row.DeletedAt = nil
+	row.UpdatedAt = dbTime(time.Now())
+	row.Version++
+	result := r.view(row)
+	r.recordEvent(ctx, models.OperationRestore, id, before, result)
+	return result, nil
+}
+
+// Purge --- PURGE ---
+// Окончательно удаляет подписки, находящиеся в корзине с момента раньше before,
+// вместе с ценами и паузами. Списания и журнал аудита сохраняются.
+func (r *SubRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	defer r.lock(ctx)()
+
+	var purged []*models.Subscription
+	for _, row := range r.subs {
+		if row.DeletedAt != nil && row.DeletedAt.Before(before) {
+			purged = append(purged, r.view(row))
+		}
+	}
+	slices.SortFunc(purged, compareCreated)
+
+	for _, sub := range purged {
+		delete(r.subs, sub.Id)
+		delete(r.prices, sub.Id)
+		delete(r.pauses, sub.Id)
+		r.recordEvent(ctx, models.OperationPurge, sub.Id, sub, nil)
+	}
+	return int64(len(purged)), nil
+}
+
+// lockSub возвращает подписку в том виде, в каком её читает адаптер PostgreSQL.
+// Вызывается под блокировкой на запись.
+func (r *SubRepository) lockSub(op string, id uuid.UUID) (*models.Subscription, error) {
+	row, ok := r.subs[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, models.ErrNotFound)
+	}
+	return r.view(row), nil
+}
+
+// checkVersion проверяет, что подписку можно изменять:
+// удалённая считается отсутствующей (ErrNotFound), другая версия — ErrPreconditionFailed.
+func checkVersion(op string, current *models.Subscription, version *int64) error {
+	if current.IsDeleted() {
+		return fmt.Errorf("%s: %w", op, models.ErrNotFound)
+	}
+	if version != nil && current.Version != *version {
+		return fmt.Errorf("%s: %w: version mismatch", op, models.ErrPreconditionFailed)
+	}
+	return nil
+}
+
+// GetById --- GET BY ID ---
+// Возвращает подписку, в том числе удалённую: решение о её видимости принимает сервис.
+func (r *SubRepository) GetById(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	defer r.rlock(ctx)()
+
+	row, ok := r.subs[id]
+	if !ok {
+		return nil, fmt.Errorf("get by id: %w", models.ErrNotFound)
+	}
+	return r.view(row), nil
+}
+
+// GetAll --- GET ALL ---
+func (r *SubRepository) GetAll(ctx context.Context, filter *filters.SubFilter, page, pageSize int64) ([]*models.Subscription, error) {
+	defer r.rlock(ctx)()
+
+	subs := r.selectSubs(filter)
+	sort := filter.Sort
+	if len(sort) == 0 {
+		sort = []filters.SortField{{Field: "created_at", Desc: true}}
+	}
+	slices.SortFunc(subs, func(a, b *models.Subscription) int {
+		return compareSubs(a, b, sort)
+	})
+	return paginate(subs, page, pageSize), nil
+}
+
+// GetAllByCursor --- GET ALL (keyset) ---
+// Возвращает до limit подписок после (или перед) курсором и признак того,
+// что в этом направлении есть ещё записи. Без курсора — первая страница.
+func (r *SubRepository) GetAllByCursor(ctx context.Context, filter *filters.SubFilter, cursor *filters.Cursor, limit int64) ([]*models.Subscription, bool, error) {
+	defer r.rlock(ctx)()
+
+	subs := r.selectSubs(filter)
+	backward := cursor != nil && cursor.Backward
+	if cursor != nil {
+		at := &models.Subscription{Id: cursor.Id, CreatedAt: cursor.CreatedAt}
+		subs = slices.DeleteFunc(subs, func(sub *models.Subscription) bool {
+			c := compareCreated(sub, at)
+			return backward && c <= 0 || !backward && c >= 0
+		})
+	}
+	if backward {
+		slices.SortFunc(subs, compareCreated)
+	} else {
+		slices.SortFunc(subs, func(a, b *models.Subscription) int { return compareCreated(b, a) })
+	}
+
+	hasMore := int64(len(subs)) > limit
+	if hasMore {
+		subs = subs[:max(limit, 0)]
+	}
+	if backward {
+		slices.Reverse(subs)
+	}
+	return subs, hasMore, nil
+}
+
+// Count --- COUNT (Filter) ---
+func (r *SubRepository) Count(ctx context.Context, filter *filters.SubFilter) (int64, error) {
+	defer r.rlock(ctx)()
+
+	return int64(len(r.selectSubs(filter))), nil
+}
+
+// selectSubs возвращает подписки, подходящие под фильтр, в произвольном порядке.
+func (r *SubRepository) selectSubs(filter *filters.SubFilter) []*models.Subscription {
+	now := time.Now()
+	var subs []*models.Subscription
+	for _, row := range r.subs {
+		sub := r.view(row)
+		if matchSub(sub, filter, now) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// matchSub повторяет условия applySubFilter адаптера PostgreSQL.
+// Цена sub — действующая на сегодня.
+func matchSub(sub *models.Subscription, filter *filters.SubFilter, now time.Time) bool {
+	switch {
+	case filter.OnlyDeleted && !sub.IsDeleted():
+		return false
+	case !filter.OnlyDeleted && !filter.IncludeDeleted && sub.IsDeleted():
+		return false
+	}
+	if filter.UserID != nil && sub.UserId != *filter.UserID {
+		return false
+	}
+	if filter.ServiceName != nil && *filter.ServiceName != "" && sub.ServiceName != *filter.ServiceName {
+		return false
+	}
+	if filter.ServiceNamePrefix != nil && *filter.ServiceNamePrefix != "" &&
+		!strings.HasPrefix(strings.ToLower(sub.ServiceName), strings.ToLower(*filter.ServiceNamePrefix)) {
+		return false
+	}
+	if filter.State != nil && sub.State != *filter.State {
+		return false
+	}
+	if filter.TrialEndsBefore != nil &&
+		(sub.State != models.StateTrial || sub.TrialEnd == nil || sub.TrialEnd.After(*filter.TrialEndsBefore)) {
+		return false
+	}
+	if filter.Status != nil {
+		switch *filter.Status {
+		case filters.StatusActive:
+			if sub.EndDate != nil && sub.EndDate.Before(now) {
+				return false
+			}
+		case filters.StatusExpired:
+			if sub.EndDate == nil || !sub.EndDate.Before(now) {
+				return false
+			}
+		}
+	}
+	if filter.MinPrice != nil && sub.Price < *filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice != nil && sub.Price > *filter.MaxPrice {
+		return false
+	}
+	// Подписка пересекается с периодом [from, to]
+	if filter.From != nil && !filter.From.IsZero() && sub.EndDate != nil && sub.EndDate.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !filter.To.IsZero() && sub.StartDate.After(*filter.To) {
+		return false
+	}
+	return true
+}
+
+// compareSubs сравнивает подписки по полям сортировки; при равенстве — по id
+// в направлении последнего поля, как orderBy адаптера PostgreSQL.
+func compareSubs(a, b *models.Subscription, sort []filters.SortField) int {
+	for _, field := range sort {
+		c := compareField(a, b, field.Field)
+		if field.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	c := bytes.Compare(a.Id[:], b.Id[:])
+	if sort[len(sort)-1].Desc {
+		return -c
+	}
+	return c
+}
+
+func compareField(a, b *models.Subscription, field string) int {
+	switch field {
+	case "service_name":
+		return strings.Compare(a.ServiceName, b.ServiceName)
+	case "price":
+		return cmp.Compare(a.Price, b.Price)
+	case "start_date":
+		return a.StartDate.Compare(b.StartDate)
+	case "end_date":
+		return compareNullable(a.EndDate, b.EndDate)
+	case "trial_end":
+		return compareNullable(a.TrialEnd, b.TrialEnd)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case "deleted_at":
+		return compareNullable(a.DeletedAt, b.DeletedAt)
+	}
+	return 0
+}
+
+// compareNullable считает NULL больше любого момента, как PostgreSQL:
+// по возрастанию пустые значения идут последними, по убыванию — первыми.
+func compareNullable(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return a.Compare(*b)
+}
+
+// compareCreated сравнивает подписки по ключу keyset-пагинации (created_at, id).
+func compareCreated(a, b *models.Subscription) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.Id[:], b.Id[:])
+}
+
+// paginate возвращает страницу items так же, как LIMIT pageSize OFFSET (page-1)*pageSize.
+func paginate[T any](items []T, page, pageSize int64) []T {
+	offset := (page - 1) * pageSize
+	if offset < 0 || pageSize <= 0 || offset >= int64(len(items)) {
+		return nil
+	}
+	return items[offset:min(offset+pageSize, int64(len(items)))]
+}
+
+// view возвращает копию записи с ценой, действующей на сегодня, — так подписку
+// читает адаптер PostgreSQL (см. selectSubColumns).
+func (r *SubRepository) view(row *models.Subscription) *models.Subscription {
+	sub := cloneSub(row)
+	sub.Price = r.priceAt(row, sessionDate(time.Now()))
+	return sub
+}
+
+// checkConstraints повторяет ограничения таблицы subscriptions.
+func checkConstraints(sub *models.Subscription) error {
+	switch {
+	case utf8.RuneCountInString(sub.ServiceName) > maxServiceNameLength:
+		return fmt.Errorf("%w: service_name is longer than %d characters", models.ErrValidation, maxServiceNameLength)
+	case sub.Price <= 0:
+		return fmt.Errorf("%w: price must be positive", models.ErrValidation)
+	case !currencyPattern.MatchString(sub.Currency):
+		return fmt.Errorf("%w: invalid currency %q", models.ErrValidation, sub.Currency)
+	case !sub.BillingInterval.IsValid():
+		return fmt.Errorf("%w: invalid billing_interval %q", models.ErrValidation, sub.BillingInterval)
+	case sub.BillingInterval == models.BillingCustom && (sub.IntervalDays == nil || *sub.IntervalDays <= 0),
+		sub.BillingInterval != models.BillingCustom && sub.IntervalDays != nil:
+		return fmt.Errorf("%w: interval_days does not match billing_interval", models.ErrValidation)
+	case !sub.State.IsValid():
+		return fmt.Errorf("%w: invalid state %q", models.ErrValidation, sub.State)
+	case sub.TrialEnd != nil && sub.TrialEnd.Before(sub.StartDate):
+		return fmt.Errorf("%w: trial_end is before start_date", models.ErrValidation)
+	}
+	return nil
+}
+
+func cloneSub(sub *models.Subscription) *models.Subscription {
+	c := *sub
+	c.TrialEnd = clonePtr(sub.TrialEnd)
+	c.IntervalDays = clonePtr(sub.IntervalDays)
+	c.EndDate = clonePtr(sub.EndDate)
+	c.DeletedAt = clonePtr(sub.DeletedAt)
+	return &c
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// normalizeTimes приводит моменты подписки к точности TIMESTAMPTZ.
+func normalizeTimes(sub *models.Subscription) {
+	sub.StartDate = dbTime(sub.StartDate)
+	sub.CreatedAt = dbTime(sub.CreatedAt)
+	sub.UpdatedAt = dbTime(sub.UpdatedAt)
+	sub.EndDate = dbTimePtr(sub.EndDate)
+	sub.TrialEnd = dbTimePtr(sub.TrialEnd)
+	sub.DeletedAt = dbTimePtr(sub.DeletedAt)
+}
+
+// dbTime отбрасывает доли времени меньше микросекунды, как при записи в TIMESTAMPTZ.
+func dbTime(t time.Time) time.Time {
+	return t.Truncate(time.Microsecond)
+}
+
+func dbTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := dbTime(*t)
+	return &v
+}
+
+// dateOf — значение DATE, в которое pgx переводит параметр t: календарная дата
+// в собственном часовом поясе t.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// sessionDate — значение t::date, вычисленное базой в часовом поясе сессии (UTC).
+func sessionDate(t time.Time) time.Time {
+	return dateOf(t.UTC())
+}
@@ -0,0 +1,21 @@
+package memory_test
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/persistence/memory"
+	"SubscriptionService/internal/persistence/subrepotest"
+	"testing"
+)
+
+func TestSubRepositoryConformance(t *testing.T) {
+	subrepotest.Run(t, func(*testing.T) core_interfaces.ISubRepository {
+		return memory.NewSubRepository()
+	})
+}
+
+func TestSubRepositoryUnitOfWork(t *testing.T) {
+	subrepotest.RunUnitOfWork(t, func(*testing.T) (core_interfaces.ISubRepository, core_interfaces.ITxManager) {
+		repo := memory.NewSubRepository()
+		return repo, memory.NewTxManager(repo)
+	})
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"maps"
+
+	"github.com/google/uuid"
+)
+
+// txKey — ключ контекста единицы работы; значение — хранилище, в котором она открыта.
+type txKey struct{}
+
+// TxManager — единица работы для хранилища в памяти. Пока она выполняется,
+// методы хранилища вне её ждут завершения, поэтому не видят её неподтверждённых
+// изменений. При ошибке fn хранилище возвращается к снимку, сделанному в начале.
+// Вложенная единица работы откатывает только свои изменения, как точка сохранения.
+type TxManager struct {
+	repo *SubRepository
+}
+
+var _ core_interfaces.ITxManager = (*TxManager)(nil)
+
+func NewTxManager(repo *SubRepository) *TxManager {
+	return &TxManager{repo: repo}
+}
+
+// WithinTx --- UNIT OF WORK ---
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	r := m.repo
+	if !r.inTx(ctx) {
+		r.txMu.Lock()
+		defer r.txMu.Unlock()
+		ctx = context.WithValue(ctx, txKey{}, r)
+	}
+
+	r.mu.RLock()
+	saved := r.save()
+	r.mu.RUnlock()
+
+	if err := fn(ctx); err != nil {
+		r.mu.Lock()
+		r.restore(saved)
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (r *SubRepository) inTx(ctx context.Context) bool {
+	owner, _ := ctx.Value(txKey{}).(*SubRepository)
+	return owner == r
+}
+
+// lock захватывает хранилище на запись и возвращает функцию освобождения.
+// Вне единицы работы метод сначала ждёт завершения открытой единицы работы.
+func (r *SubRepository) lock(ctx context.Context) func() {
+	if r.inTx(ctx) {
+		r.mu.Lock()
+		return r.mu.Unlock
+	}
+	r.txMu.RLock()
+	r.mu.Lock()
+	return func() {
+		r.mu.Unlock()
+		r.txMu.RUnlock()
+	}
+}
+
+// rlock захватывает хранилище на чтение и возвращает функцию освобождения.
+func (r *SubRepository) rlock(ctx context.Context) func() {
+	if r.inTx(ctx) {
+		r.mu.RLock()
+		return r.mu.RUnlock
+	}
+	r.txMu.RLock()
+	r.mu.RLock()
+	return func() {
+		r.mu.RUnlock()
+		r.txMu.RUnlock()
+	}
+}
+
+// savepoint — копия состояния хранилища для отката единицы работы.
+type savepoint struct {
+	subs      map[uuid.UUID]*models.Subscription
+	prices    map[uuid.UUID][]*models.PriceChange
+	pauses    map[uuid.UUID][]*pause
+	charges   map[uuid.UUID][]*models.Charge
+	events    []*models.SubscriptionEvent
+	chargeSeq int64
+	eventSeq  int64
+}
+
+// save копирует состояние хранилища. Записи, которые методы меняют на месте,
+// копируются по значению. Вызывается под r.mu.
+func (r *SubRepository) save() savepoint {
+	subs := make(map[uuid.UUID]*models.Subscription, len(r.subs))
+	for id, row := range r.subs {
+		subs[id] = cloneSub(row)
+	}
+	return savepoint{
+		subs:      subs,
+		prices:    cloneRows(r.prices),
+		pauses:    cloneRows(r.pauses),
+		charges:   cloneRows(r.charges),
+		events:    append([]*models.SubscriptionEvent(nil), r.events...),
+		chargeSeq: r.chargeSeq,
+		eventSeq:  r.eventSeq,
+	}
+}
+
+// restore возвращает хранилище к точке сохранения s. Вызывается под r.mu.
+func (r *SubRepository) restore(s savepoint) {
+	r.subs = s.subs
+	r.prices = s.prices
+	r.pauses = s.pauses
+	r.charges = s.charges
+	r.events = s.events
+	r.chargeSeq = s.chargeSeq
+	r.eventSeq = s.eventSeq
+}
+
+func cloneRows[T any](rows map[uuid.UUID][]*T) map[uuid.UUID][]*T {
+	c := maps.Clone(rows)
+	for id, list := range c {
+		copied := make([]*T, len(list))
+		for i, row := range list {
+			v := *row
+			copied[i] = &v
+		}
+		c[id] = copied
+	}
+	return c
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnsupported возвращают хранилища, которых нет в режиме STORAGE=memory и с SQLite:
+// напоминания и вебхуки работают только с PostgreSQL.
+var ErrUnsupported = fmt.Errorf("%w: not supported without PostgreSQL storage", models.ErrUnavailable)
+
+// ReminderRepository — заглушка хранилища напоминаний для режима STORAGE=memory и SQLite.
+type ReminderRepository struct{}
+
+var _ core_interfaces.IReminderRepository = ReminderRepository{}
+
+func (ReminderRepository) GetUpcoming(context.Context, uuid.UUID, time.Time, time.Time) ([]*models.Reminder, error) {
+	return nil, ErrUnsupported
+}
+
+func (ReminderRepository) Enqueue(context.Context, time.Time, time.Time) (int64, error) {
+	return 0, ErrUnsupported
+}
+
+func (ReminderRepository) Claim(context.Context, time.Time, int, time.Duration) ([]*models.Reminder, error) {
+	return nil, ErrUnsupported
+}
+
+func (ReminderRepository) MarkSent(context.Context, int64, time.Time) error {
+	return ErrUnsupported
+}
+
+func (ReminderRepository) MarkFailed(context.Context, int64, string, time.Time) error {
+	return ErrUnsupported
+}
+
+// WebhookRepository — заглушка хранилища вебхуков для режима STORAGE=memory и SQLite.
+type WebhookRepository struct{}
+
+var _ core_interfaces.IWebhookRepository = WebhookRepository{}
+
+func (WebhookRepository) CreateEndpoint(context.Context, *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	return nil, ErrUnsupported
+}
+
+func (WebhookRepository) UpdateEndpoint(context.Context, *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	return nil, ErrUnsupported
+}
+
+func (WebhookRepository) DeleteEndpoint(context.Context, uuid.UUID) error {
+	return ErrUnsupported
+}
+
+func (WebhookRepository) GetEndpoint(context.Context, uuid.UUID) (*models.WebhookEndpoint, error) {
+	return nil, ErrUnsupported
+}
+
+func (WebhookRepository) GetEndpoints(context.Context) ([]*models.WebhookEndpoint, error) {
+	return nil, ErrUnsupported
+}
+
+func (WebhookRepository) GetDeliveries(context.Context, *filters.DeliveryFilter, int64, int64) ([]*models.WebhookDelivery, error) {
+	return nil, ErrUnsupported
+}
+
+func (WebhookRepository) CountDeliveries(context.Context, *filters.DeliveryFilter) (int64, error) {
+	return 0, ErrUnsupported
+}
+
+func (WebhookRepository) Redeliver(context.Context, int64, time.Time) (*models.WebhookDelivery, error) {
+	return nil, ErrUnsupported
+}
+
+func (WebhookRepository) Claim(context.Context, time.Time, int, time.Duration) ([]*models.WebhookDelivery, error) {
+	return nil, ErrUnsupported
+}
+
+func (WebhookRepository) MarkDelivered(context.Context, int64, int, time.Time) error {
+	return ErrUnsupported
+}
+
+func (WebhookRepository) MarkFailed(context.Context, int64, *int, string, *time.Time) error {
+	return ErrUnsupported
+}
+
+// ExchangeRates — провайдер без курсов для режима STORAGE=memory и SQLite без EXCHANGE_RATES_FILE:
+// суммы в одной валюте считаются, пересчёт между валютами недоступен.
+type ExchangeRates struct{}
+
+var _ core_interfaces.IExchangeRateProvider = ExchangeRates{}
+
+func (ExchangeRates) GetRate(_ context.Context, base, quote string, _ time.Time) (*models.ExchangeRate, error) {
+	return nil, fmt.Errorf("%w: %s/%s", models.ErrExchangeRateNotFound, base, quote)
+}
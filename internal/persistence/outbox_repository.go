@@ -0,0 +1,125 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OutboxRepository — изменения подписок, ожидающие публикации в брокер сообщений.
+type OutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+var _ core_interfaces.IOutboxRepository = (*OutboxRepository)(nil)
+
+func NewOutboxRepository(db *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+const outboxTableName = "event_outbox"
+
+// Ключ advisory-блокировки, под которой сообщения передаёт только один экземпляр сервиса
+const outboxRelayLockKey = 0x6f7574626f78
+
+var outboxColumns = []string{"id", "event_id", "subscription_id", "operation", "data", "occurred_at"}
+
+// enqueueOutbox записывает событие журнала аудита для публикации в брокер.
+// Выполняется в транзакции изменения подписки. payload — событие в JSON.
+func enqueueOutbox(ctx context.Context, tx pgx.Tx, event *models.SubscriptionEvent, payload []byte) error {
+	sqlStr, args, err := enqueueOutboxQuery(event, payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, sqlStr, args...)
+	return err
+}
+
+// enqueueOutboxQuery строит запрос enqueueOutbox.
+func enqueueOutboxQuery(event *models.SubscriptionEvent, payload []byte) (string, []any, error) {
+	sqlStr, args, err := psql.Insert(outboxTableName).
+		Columns("event_id", "subscription_id", "operation", "data", "occurred_at").
+		Values(uuid.New(), event.SubscriptionId, event.Operation, string(payload), event.OccurredAt).
+		ToSql()
+	if err != nil {
+		return "", nil, fmt.Errorf("build enqueue outbox query: %w", err)
+	}
+	return sqlStr, args, nil
+}
+
+// Relay --- RELAY ---
+// Сообщения читаются и удаляются в одной транзакции под advisory-блокировкой: пока один
+// экземпляр передаёт сообщения, остальные пропускают проход, поэтому события одной подписки
+// публикуются строго в порядке записи. Если транзакция не завершилась после публикации,
+// сообщения будут опубликованы повторно (at-least-once).
+func (r *OutboxRepository) Relay(ctx context.Context, limit int, publish func(msg *models.OutboxMessage) error) (int, error) {
+	sqlStr, args, err := psql.Select(outboxColumns...).
+		From(outboxTableName).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build outbox query: %w", err)
+	}
+
+	published := 0
+	var publishErr error
+	err = pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		var locked bool
+		if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxRelayLockKey).Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		rows, err := tx.Query(ctx, sqlStr, args...)
+		if err != nil {
+			return err
+		}
+		messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.OutboxMessage, error) {
+			var msg models.OutboxMessage
+			err := row.Scan(&msg.Id, &msg.EventId, &msg.SubscriptionId, &msg.Operation, &msg.Data, &msg.OccurredAt)
+			return &msg, err
+		})
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(messages))
+		for _, msg := range messages {
+			if publishErr = publish(msg); publishErr != nil {
+				break
+			}
+			ids = append(ids, msg.Id)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		deleteSQL, deleteArgs, err := psql.Delete(outboxTableName).
+			Where(squirrel.Eq{"id": ids}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build delete outbox query: %w", err)
+		}
+		if _, err := tx.Exec(ctx, deleteSQL, deleteArgs...); err != nil {
+			return err
+		}
+		published = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, mapError("relay outbox", err)
+	}
+	if publishErr != nil {
+		return published, fmt.Errorf("publish outbox message: %w", publishErr)
+	}
+	return published, nil
+}
@@ -0,0 +1,228 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReminderRepository — предстоящие события подписок и отправленные о них напоминания.
+type ReminderRepository struct {
+	db *pgxpool.Pool
+}
+
+var _ core_interfaces.IReminderRepository = (*ReminderRepository)(nil)
+
+func NewReminderRepository(db *pgxpool.Pool) *ReminderRepository {
+	return &ReminderRepository{db: db}
+}
+
+const remindersTableName = "reminders"
+
+// После стольких неудачных попыток напоминание больше не отправляется
+const maxReminderAttempts = 5
+
+var reminderColumns = []string{"id", "subscription_id", "user_id", "service_name", "kind", "due_at", "amount", "currency", "sent_at", "attempts"}
+
+// subscriptionEvents строит выборку событий подписок s × k: продление активной подписки
+// (начало следующего неоплаченного периода), окончание пробного периода и окончание подписки.
+func subscriptionEvents(columns ...string) squirrel.SelectBuilder {
+	return squirrel.Select(columns...).
+		From(tableName + " s").
+		JoinClause("CROSS JOIN LATERAL (VALUES " +
+			"('renewal', CASE WHEN s.state = 'active' THEN " + nextChargeSQL + " END), " +
+			"('trial_end', CASE WHEN s.state = 'trial' THEN s.trial_end END), " +
+			"('expiry', CASE WHEN s.state IN ('trial', 'active', 'paused') THEN s.end_date END)" +
+			") AS k(kind, due_at)").
+		Where(squirrel.Eq{"s.deleted_at": nil}).
+		// После окончания подписки не будет ни продления, ни оплаты после пробного периода
+		Where("(k.kind = 'expiry' OR s.end_date IS NULL OR k.due_at < s.end_date)")
+}
+
+// upcomingEvents строит выборку событий подписок в интервале (from, until].
+// Колонки — в порядке вставки в reminders.
+func upcomingEvents(from, until time.Time) squirrel.SelectBuilder {
+	return subscriptionEvents(
+		"s.id", "s.user_id", "s.service_name", "k.kind", "k.due_at",
+		priceAtSQL("k.due_at::date"), "s.currency").
+		Where("k.due_at > ?", from).
+		Where("k.due_at <= ?", until)
+}
+
+// stillDueSQL — условие, что событие напоминания rm по-прежнему наступит: подписку
+// не отменили, не приостановили и не удалили, а дата события не изменилась.
+func stillDueSQL() (string, error) {
+	current, _, err := subscriptionEvents("1").
+		Where("s.id = rm.subscription_id AND k.kind = rm.kind AND k.due_at = rm.due_at").
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+	return "EXISTS (" + current + ")", nil
+}
+
+// GetUpcoming --- UPCOMING ---
+func (r *ReminderRepository) GetUpcoming(ctx context.Context, userID uuid.UUID, from, until time.Time) ([]*models.Reminder, error) {
+	sqlStr, args, err := upcomingEvents(from, until).
+		Column("rm.sent_at").
+		LeftJoin(remindersTableName+" rm ON rm.subscription_id = s.id AND rm.kind = k.kind AND rm.due_at = k.due_at").
+		Where(squirrel.Eq{"s.user_id": userID}).
+		OrderBy("k.due_at", "s.id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build upcoming query: %w", err)
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("upcoming query", err)
+	}
+	reminders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.Reminder, error) {
+		var reminder models.Reminder
+		err := row.Scan(&reminder.SubscriptionId, &reminder.UserId, &reminder.ServiceName, &reminder.Kind,
+			&reminder.DueAt, &reminder.Amount, &reminder.Currency, &reminder.SentAt)
+		return &reminder, err
+	})
+	if err != nil {
+		return nil, mapError("upcoming query", err)
+	}
+	return reminders, nil
+}
+
+// Enqueue --- ENQUEUE ---
+// Уникальность (subscription_id, kind, due_at) гарантирует одну запись на событие.
+func (r *ReminderRepository) Enqueue(ctx context.Context, from, until time.Time) (int64, error) {
+	sqlStr, args, err := psql.Insert(remindersTableName).
+		Columns("subscription_id", "user_id", "service_name", "kind", "due_at", "amount", "currency").
+		Select(upcomingEvents(from, until)).
+		Suffix("ON CONFLICT (subscription_id, kind, due_at) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build enqueue reminders query: %w", err)
+	}
+
+	tag, err := conn(ctx, r.db).Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return 0, mapError("enqueue reminders", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Claim --- CLAIM ---
+// Захваченные напоминания не выдаются другим экземплярам до истечения lease
+// или до MarkSent/MarkFailed. Событие, которое уже наступило, не напоминается.
+// В той же транзакции напоминания о событиях, которые больше не наступят,
+// отмечаются пропущенными и не выдаются.
+func (r *ReminderRepository) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.Reminder, error) {
+	stillDue, err := stillDueSQL()
+	if err != nil {
+		return nil, fmt.Errorf("build still due reminders query: %w", err)
+	}
+	pending := func(due bool) squirrel.SelectBuilder {
+		condition := stillDue
+		if !due {
+			condition = "NOT " + stillDue
+		}
+		return squirrel.Select("rm.id").
+			From(remindersTableName + " rm").
+			Where(squirrel.Eq{"rm.sent_at": nil, "rm.skipped_at": nil}).
+			Where(squirrel.Gt{"rm.due_at": now}).
+			Where(squirrel.Lt{"rm.attempts": maxReminderAttempts}).
+			Where(squirrel.Or{squirrel.Eq{"rm.locked_until": nil}, squirrel.Lt{"rm.locked_until": now}}).
+			Where(condition)
+	}
+
+	staleSQL, staleArgs, err := pending(false).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build stale reminders query: %w", err)
+	}
+	skipSQL, skipArgs, err := psql.Update(remindersTableName).
+		Set("skipped_at", now).
+		Set("locked_until", nil).
+		Where(squirrel.Expr("id IN ("+staleSQL+")", staleArgs...)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build skip reminders query: %w", err)
+	}
+
+	dueSQL, dueArgs, err := pending(true).
+		OrderBy("rm.due_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build pending reminders query: %w", err)
+	}
+	sqlStr, args, err := psql.Update(remindersTableName).
+		Set("locked_until", now.Add(lease)).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Where(squirrel.Expr("id IN ("+dueSQL+")", dueArgs...)).
+		Suffix("RETURNING " + strings.Join(reminderColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build claim reminders query: %w", err)
+	}
+
+	var reminders []*models.Reminder
+	err = pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, skipSQL, skipArgs...); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, sqlStr, args...)
+		if err != nil {
+			return err
+		}
+		reminders, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.Reminder, error) {
+			var reminder models.Reminder
+			err := row.Scan(&reminder.Id, &reminder.SubscriptionId, &reminder.UserId, &reminder.ServiceName,
+				&reminder.Kind, &reminder.DueAt, &reminder.Amount, &reminder.Currency, &reminder.SentAt,
+				&reminder.Attempts)
+			return &reminder, err
+		})
+		return err
+	})
+	if err != nil {
+		return nil, mapError("claim reminders", err)
+	}
+	return reminders, nil
+}
+
+// MarkSent --- SENT ---
+func (r *ReminderRepository) MarkSent(ctx context.Context, id int64, at time.Time) error {
+	return r.update(ctx, "mark reminder sent", psql.Update(remindersTableName).
+		Set("sent_at", at).
+		Set("locked_until", nil).
+		Set("last_error", nil).
+		Where(squirrel.Eq{"id": id}))
+}
+
+// MarkFailed --- FAILED ---
+// Снимает захват и откладывает следующую попытку до retryAt.
+func (r *ReminderRepository) MarkFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	return r.update(ctx, "mark reminder failed", psql.Update(remindersTableName).
+		Set("last_error", reason).
+		Set("locked_until", retryAt).
+		Where(squirrel.Eq{"id": id}))
+}
+
+func (r *ReminderRepository) update(ctx context.Context, op string, query squirrel.UpdateBuilder) error {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("build %s query: %w", op, err)
+	}
+	if _, err := conn(ctx, r.db).Exec(ctx, sqlStr, args...); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// mapError оборачивает ошибку SQLite категорией ошибок домена (models.Err*),
+// сохраняя исходную ошибку для логов. Уже отнесённые к категории ошибки
+// (например, возвращённые из транзакции) не оборачиваются повторно.
+func mapError(op string, err error) error {
+	if err == nil || isDomainError(err) {
+		return err
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, models.ErrNotFound)
+	}
+
+	var liteErr *sqlitedriver.Error
+	if errors.As(err, &liteErr) {
+		switch liteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return fmt.Errorf("%s: %w: %w", op, models.ErrConflict, err)
+		case sqlite3.SQLITE_CONSTRAINT_CHECK, sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+			return fmt.Errorf("%s: %w: %w", op, models.ErrValidation, err)
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_CANTOPEN, sqlite3.SQLITE_FULL:
+			// база заблокирована другой записью дольше busy_timeout, недоступна или переполнена
+			return fmt.Errorf("%s: %w: %w", op, models.ErrUnavailable, err)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, sql.ErrConnDone) {
+		return fmt.Errorf("%s: %w: %w", op, models.ErrUnavailable, err)
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+func isDomainError(err error) bool {
+	for _, category := range []error{
+		models.ErrInvalidArgument, models.ErrValidation, models.ErrNotFound,
+		models.ErrConflict, models.ErrPreconditionFailed, models.ErrUnavailable,
+	} {
+		if errors.Is(err, category) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package sqlite
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"database/sql"
+)
+
+// CreateMany --- BULK INSERT ---
+// Подписки вставляются по одной в общей транзакции: SQLite работает в том же процессе,
+// и стоимость запроса определяется фиксацией транзакции, а не обращением к базе.
+// При любой ошибке не создаётся ни одна подписка.
+func (s *SubRepository) CreateMany(ctx context.Context, subs []*models.Subscription) ([]*models.Subscription, error) {
+	created := make([]*models.Subscription, 0, len(subs))
+	err := inTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, sub := range subs {
+			result, err := insertSub(ctx, tx, sub)
+			if err != nil {
+				return err
+			}
+			created = append(created, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, mapError("bulk insert subscriptions", err)
+	}
+	return created, nil
+}
@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// Таблица списаний по периодам оплаты
+const chargesTableName = "charges"
+
+var chargeColumns = []string{"id", "subscription_id", "period_start", "period_end", "amount", "currency", "created_at"}
+
+// Наибольшее число периодов, списываемых по одной подписке за проход
+// (догоняющие списания после простоя разбиваются на несколько проходов)
+const maxPeriodsPerRun = 100
+
+// nextChargeSQL — начало следующего неоплаченного периода подписки s:
+// конец последнего списания, а без списаний — начало оплаты (после пробного периода).
+// Период, в котором подписка была возобновлена после паузы, начинается с момента возобновления.
+// Пустая строка меньше любого момента и заменяет '-infinity' из PostgreSQL:
+// max() с несколькими аргументами в SQLite возвращает NULL, если NULL хотя бы один из них.
+var nextChargeSQL = "max(COALESCE((SELECT max(c.period_end) FROM " + chargesTableName + " c WHERE c.subscription_id = s.id), " +
+	billingStartSQL + "), " +
+	"COALESCE((SELECT max(ps.resumed_at) FROM " + pausesTableName + " ps WHERE ps.subscription_id = s.id), ''))"
+
+// CreateDueCharges --- RENEW ---
+// Создаёт списания за наступившие периоды оплаты активных подписок, обрабатывая
+// не больше limit подписок. Уникальность (subscription_id, period_start) делает
+// повторный проход безопасным. Возвращает число обработанных подписок и созданных списаний.
+func (s *SubRepository) CreateDueCharges(ctx context.Context, now time.Time, limit int) (int, int64, error) {
+	sqlStr, args, err := sqb.Select(selectSubColumns...).
+		Column(nextChargeSQL+" AS next_charge_at").
+		From(tableName+" s").
+		Where(squirrel.Eq{"s.state": models.StateActive, "s.deleted_at": nil}).
+		Where(nextChargeSQL+" <= ?", formatTime(now)).
+		Where("(s.end_date IS NULL OR " + nextChargeSQL + " < s.end_date)").
+		OrderBy("next_charge_at").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return 0, 0, fmt.Errorf("build due charges query: %w", err)
+	}
+
+	var processed int
+	var created int64
+	err = inTx(ctx, s.db, func(tx *sql.Tx) error {
+		type dueSub struct {
+			sub  *models.Subscription
+			next time.Time
+		}
+		rows, err := tx.QueryContext(ctx, sqlStr, args...)
+		if err != nil {
+			return err
+		}
+		var due []dueSub
+		for rows.Next() {
+			var sub models.Subscription
+			var next time.Time
+			if err := rows.Scan(append(subDest(&sub), timeDest{&next})...); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("scan due charges: %w", err)
+			}
+			due = append(due, dueSub{sub: &sub, next: next})
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, d := range due {
+			n, err := insertCharges(ctx, tx, d.sub, d.next, now)
+			if err != nil {
+				return err
+			}
+			created += n
+		}
+		processed = len(due)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, mapError("create charges", err)
+	}
+	return processed, created, nil
+}
+
+// insertCharges создаёт списания подписки за периоды, начинающиеся с start и не позже now.
+func insertCharges(ctx context.Context, tx *sql.Tx, sub *models.Subscription, start, now time.Time) (int64, error) {
+	var created int64
+	for i := 0; i < maxPeriodsPerRun && !start.After(now); i++ {
+		if sub.EndDate != nil && !start.Before(*sub.EndDate) {
+			break
+		}
+		end := sub.PeriodEnd(start)
+
+		// WHERE в INSERT ... SELECT обязателен: без него SQLite не отличает ON CONFLICT от условия соединения
+		sqlStr, args, err := sqb.Insert(chargesTableName).
+			Columns("subscription_id", "period_start", "period_end", "amount", "currency", "created_at").
+			Select(squirrel.Select("s.id").
+				Column("?", formatTime(start)).
+				Column("?", formatTime(end)).
+				Column(priceAtSQL("?"), formatDate(start)).
+				Column("s.currency").
+				Column("?", formatTime(time.Now())).
+				From(tableName + " s").
+				Where(squirrel.Eq{"s.id": sub.Id})).
+			Suffix("ON CONFLICT (subscription_id, period_start) DO NOTHING").
+			ToSql()
+		if err != nil {
+			return 0, fmt.Errorf("build insert charge query: %w", err)
+		}
+		res, err := tx.ExecContext(ctx, sqlStr, args...)
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		created += n
+		start = end
+	}
+	return created, nil
+}
+
+// GetCharges --- CHARGES ---
+// Возвращает списания подписки от новых периодов к старым.
+func (s *SubRepository) GetCharges(ctx context.Context, id uuid.UUID, page, pageSize int64) ([]*models.Charge, error) {
+	sqlStr, args, err := sqb.Select(chargeColumns...).
+		From(chargesTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("period_start DESC").
+		Limit(uint64(pageSize)).
+		Offset(uint64((page - 1) * pageSize)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build charges query: %w", err)
+	}
+
+	rows, err := conn(ctx, s.db).QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("charges query", err)
+	}
+	defer rows.Close()
+
+	charges := []*models.Charge{}
+	for rows.Next() {
+		var charge models.Charge
+		err := rows.Scan(&charge.Id, &charge.SubscriptionId, timeDest{&charge.PeriodStart}, timeDest{&charge.PeriodEnd},
+			&charge.Amount, &charge.Currency, timeDest{&charge.CreatedAt})
+		if err != nil {
+			return nil, fmt.Errorf("scan charges: %w", err)
+		}
+		charges = append(charges, &charge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError("charges query", err)
+	}
+	return charges, nil
+}
+
+// CountCharges --- CHARGES COUNT ---
+func (s *SubRepository) CountCharges(ctx context.Context, id uuid.UUID) (int64, error) {
+	sqlStr, args, err := sqb.Select("COUNT(*)").
+		From(chargesTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build charges count query: %w", err)
+	}
+
+	var total int64
+	if err := conn(ctx, s.db).QueryRowContext(ctx, sqlStr, args...).Scan(&total); err != nil {
+		return 0, mapError("charges count query", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,186 @@
+package sqlite
+
+import (
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// Момент оплаты месяца p.period: его начало или начало оплаты подписки в первом месяце
+const billedAtSQL = "max(p.period, " + billingStartSQL + ")"
+
+// Цена подписки в оплачиваемом месяце p.period, приведённая к одному месяцу
+// (см. models.BillingInterval.Months). Цена месяца — действующая на его начало
+// (или на начало оплаты в первом месяце).
+var monthlyPriceSQL = "(CAST(" + priceAtSQL("substr("+billedAtSQL+", 1, 10)") + ` AS REAL) / CASE s.billing_interval
+	WHEN 'weekly' THEN 7 / 30.4375
+	WHEN 'quarterly' THEN 3
+	WHEN 'yearly' THEN 12
+	WHEN 'custom' THEN s.interval_days / 30.4375
+	ELSE 1 END)`
+
+// Конец оплачиваемого месяца p.period
+const monthEndSQL = "(date(p.period, '+1 month') || ' 00:00:00.000000')"
+
+// Начало оплачиваемой части месяца p.period: окончание пробного периода, если он закончился в этом месяце
+const billableFromSQL = "max(p.period, COALESCE(s.trial_end, p.period))"
+
+// Сколько дней оплачиваемой части месяца p.period подписка s провела на паузе
+const pausedDaysSQL = "COALESCE((SELECT SUM(max(0, " +
+	"julianday(min(" + monthEndSQL + ", COALESCE(ps.resumed_at, " + monthEndSQL + "))) - julianday(max(" + billableFromSQL + ", ps.paused_at)))) " +
+	"FROM " + pausesTableName + " ps WHERE ps.subscription_id = s.id), 0)"
+
+// Оплачиваемая доля месяца p.period: от 0 (весь месяц в пробном периоде или на паузе) до 1
+const billedShareSQL = "((julianday(" + monthEndSQL + ") - julianday(" + billableFromSQL + ") - " + pausedDaysSQL + ") / " +
+	"(julianday(" + monthEndSQL + ") - julianday(p.period)))"
+
+// monthStartSQL — начало месяца момента expr (моменты хранятся в UTC, как date_trunc в сессии PostgreSQL).
+func monthStartSQL(expr string) string {
+	return "substr(" + expr + ", 1, 7) || '-01 00:00:00.000000'"
+}
+
+// SumSubscriptionsCost --- SUM (Filter) ---
+// Каждая подписка тарифицируется один раз за каждый календарный месяц,
+// пересекающийся с периодом [from, to], по месячному эквиваленту цены,
+// действовавшей в этом месяце (см. subscription_prices).
+// Оплата начинается после пробного периода (trial_end), месяц его окончания оплачивается
+// с этого момента. Время приостановки не оплачивается: из месяца вычитается
+// его доля, пришедшаяся на паузы.
+// Бессрочные подписки ограничиваются to, а если to не задан — текущим моментом.
+// Суммы возвращаются отдельно по каждой валюте подписок.
+func (s *SubRepository) SumSubscriptionsCost(ctx context.Context, filter *filters.SubFilter) ([]*models.CostSummary, error) {
+	groups, err := s.sumCost(ctx, filter, "''")
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*models.CostSummary, 0, len(groups))
+	for _, group := range groups {
+		summaries = append(summaries, &group.CostSummary)
+	}
+	return summaries, nil
+}
+
+// SumSubscriptionsCostGrouped --- SUM (Filter, Group) ---
+// Разбивка SumSubscriptionsCost по группам: суммы групп складываются в общий итог.
+func (s *SubRepository) SumSubscriptionsCostGrouped(ctx context.Context, filter *filters.SubFilter, groupBy filters.CostGroupBy) ([]*models.CostGroup, error) {
+	var keyExpr string
+	switch groupBy {
+	case filters.GroupByServiceName:
+		keyExpr = "s.service_name"
+	case filters.GroupByUserID:
+		keyExpr = "s.user_id"
+	case filters.GroupByMonth:
+		keyExpr = "substr(p.period, 1, 7)"
+	case filters.GroupByYear:
+		keyExpr = "substr(p.period, 1, 4)"
+	default:
+		return nil, fmt.Errorf("unsupported cost grouping %q", groupBy)
+	}
+
+	return s.sumCost(ctx, filter, keyExpr)
+}
+
+// billedPeriods строит рекурсивный CTE p(subscription_id, period, last_period) —
+// оплачиваемые месяцы подписок, подходящих под фильтр (аналог generate_series в PostgreSQL).
+func billedPeriods(filter *filters.SubFilter) (string, []any, error) {
+	to := formatTime(time.Now())
+	if filter.To != nil && !filter.To.IsZero() {
+		to = formatTime(*filter.To)
+	}
+	// max() с несколькими аргументами в SQLite возвращает NULL, если NULL хотя бы один из них,
+	// поэтому без from первый месяц — просто начало оплаты
+	first := billingStartSQL
+	var firstArgs []any
+	if filter.From != nil && !filter.From.IsZero() {
+		first = "max(" + billingStartSQL + ", ?)"
+		firstArgs = []any{formatTime(*filter.From)}
+	}
+
+	bounds := squirrel.Select("s.id").
+		Column(monthStartSQL(first)+" AS period", firstArgs...).
+		Column(monthStartSQL("min(COALESCE(s.end_date, ?), ?)")+" AS last_period", to, to).
+		From(tableName+" s").
+		Where(billingStartSQL+" <= ?", to).
+		// Подписка, закончившаяся в пробный период, не оплачивается
+		Where("(s.end_date IS NULL OR s.end_date >= " + billingStartSQL + ")")
+
+	boundsSQL, args, err := applySubFilter(bounds, filter).ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	cte := "WITH RECURSIVE bounds(subscription_id, period, last_period) AS (" + boundsSQL + "), " +
+		"p(subscription_id, period, last_period) AS (" +
+		"SELECT subscription_id, period, last_period FROM bounds WHERE period <= last_period " +
+		"UNION ALL " +
+		"SELECT subscription_id, date(period, '+1 month') || ' 00:00:00.000000', last_period FROM p WHERE period < last_period)"
+	return cte, args, nil
+}
+
+// sumCost агрегирует стоимость по ключу keyExpr и валюте.
+func (s *SubRepository) sumCost(ctx context.Context, filter *filters.SubFilter, keyExpr string) ([]*models.CostGroup, error) {
+	cte, cteArgs, err := billedPeriods(filter)
+	if err != nil {
+		return nil, fmt.Errorf("build sum query: %w", err)
+	}
+
+	billed := squirrel.Select("s.id", keyExpr+" AS key", "s.currency", monthlyPriceSQL+" AS monthly", billedShareSQL+" AS share").
+		From("p").
+		Join(tableName + " s ON s.id = p.subscription_id")
+
+	// Сначала агрегат по подписке внутри группы, затем по группе;
+	// месяц, целиком проведённый на паузе, не оплачивается
+	perSub := squirrel.Select(
+		"b.key",
+		"b.currency",
+		"SUM(b.monthly * b.share) AS cost",
+		"COUNT(*) AS months",
+		"MAX(b.monthly) AS monthly").
+		FromSelect(billed, "b").
+		Where("b.share > 0").
+		GroupBy("b.id", "b.key")
+
+	query := sqb.Select(
+		"t.key",
+		"t.currency",
+		"CAST(ROUND(SUM(t.cost)) AS INTEGER)",
+		"CAST(SUM(t.cost) AS REAL)",
+		"SUM(t.months)",
+		"CAST(SUM(t.monthly) AS REAL)",
+		"COUNT(*)").
+		PrefixExpr(squirrel.Expr(cte, cteArgs...)).
+		FromSelect(perSub, "t").
+		GroupBy("t.key", "t.currency").
+		OrderBy("t.key", "t.currency")
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sum query: %w", err)
+	}
+
+	rows, err := conn(ctx, s.db).QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("execute sum query", err)
+	}
+	defer rows.Close()
+
+	var groups []*models.CostGroup
+	for rows.Next() {
+		var group models.CostGroup
+		err = rows.Scan(&group.Key, &group.Currency, &group.TotalCost, &group.Amount, &group.BillableMonths,
+			&group.MonthlyRate, &group.SubscriptionCount)
+		if err != nil {
+			return nil, fmt.Errorf("scan sum: %w", err)
+		}
+		groups = append(groups, &group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError("execute sum query", err)
+	}
+	return groups, nil
+}
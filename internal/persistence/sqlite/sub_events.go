@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// Таблица журнала аудита
+const eventsTableName = "subscription_events"
+
+var eventColumns = []string{
+	"id", "subscription_id", "operation", "actor", "request_id", "before", "after", "occurred_at",
+}
+
+// recordEvent добавляет запись в журнал аудита в транзакции изменения подписки.
+// Инициатор и идентификатор запроса берутся из контекста (models.AuditInfoFrom).
+func recordEvent(ctx context.Context, tx *sql.Tx, op models.Operation, id uuid.UUID, before, after *models.Subscription) error {
+	beforeJSON, err := snapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := snapshot(after)
+	if err != nil {
+		return err
+	}
+	return insertEvent(ctx, tx, op, id, beforeJSON, afterJSON)
+}
+
+// recordPriceEvent добавляет в журнал аудита изменение плана цен:
+// before — заменённая цена на ту же дату (если была), after — новая.
+func recordPriceEvent(ctx context.Context, tx *sql.Tx, id uuid.UUID, before, after *models.PriceChange) error {
+	var beforeJSON []byte
+	if before != nil {
+		data, err := json.Marshal(before)
+		if err != nil {
+			return fmt.Errorf("marshal price snapshot: %w", err)
+		}
+		beforeJSON = data
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return fmt.Errorf("marshal price snapshot: %w", err)
+	}
+	return insertEvent(ctx, tx, models.OperationSchedulePrice, id, beforeJSON, afterJSON)
+}
+
+// insertEvent пишет событие в журнал. Доставки вебхуков и публикации в брокер
+// в режиме SQLite нет, поэтому событие никуда не ставится в очередь.
+func insertEvent(ctx context.Context, tx *sql.Tx, op models.Operation, id uuid.UUID, beforeJSON, afterJSON []byte) error {
+	info := models.AuditInfoFrom(ctx)
+	sqlStr, args, err := sqb.Insert(eventsTableName).
+		Columns("subscription_id", "operation", "actor", "request_id", "before", "after", "occurred_at").
+		Values(id, op, info.Actor, info.RequestId, jsonText(beforeJSON), jsonText(afterJSON), formatTime(time.Now())).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build insert event query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return fmt.Errorf("record %s event: %w", op, err)
+	}
+	return nil
+}
+
+// snapshot сериализует состояние подписки для журнала; nil — отсутствие состояния.
+func snapshot(sub *models.Subscription) ([]byte, error) {
+	if sub == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return nil, fmt.Errorf("marshal subscription snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// GetHistory --- HISTORY ---
+// Возвращает события подписки от новых к старым.
+func (s *SubRepository) GetHistory(ctx context.Context, id uuid.UUID, page, pageSize int64) ([]*models.SubscriptionEvent, error) {
+	sqlStr, args, err := sqb.Select(eventColumns...).
+		From(eventsTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("id DESC").
+		Limit(uint64(pageSize)).
+		Offset(uint64((page - 1) * pageSize)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build history query: %w", err)
+	}
+
+	rows, err := conn(ctx, s.db).QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("history query", err)
+	}
+	defer rows.Close()
+
+	events := []*models.SubscriptionEvent{}
+	for rows.Next() {
+		var event models.SubscriptionEvent
+		err := rows.Scan(&event.Id, &event.SubscriptionId, &event.Operation, &event.Actor, &event.RequestId,
+			(*[]byte)(&event.Before), (*[]byte)(&event.After), timeDest{&event.OccurredAt})
+		if err != nil {
+			return nil, fmt.Errorf("scan history: %w", err)
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError("history query", err)
+	}
+	return events, nil
+}
+
+// CountHistory --- HISTORY COUNT ---
+func (s *SubRepository) CountHistory(ctx context.Context, id uuid.UUID) (int64, error) {
+	sqlStr, args, err := sqb.Select("COUNT(*)").
+		From(eventsTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build history count query: %w", err)
+	}
+
+	var total int64
+	if err := conn(ctx, s.db).QueryRowContext(ctx, sqlStr, args...).Scan(&total); err != nil {
+		return 0, mapError("history count query", err)
+	}
+	return total, nil
+}
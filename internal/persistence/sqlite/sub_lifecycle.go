@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// Таблица интервалов приостановки подписок
+const pausesTableName = "subscription_pauses"
+
+// ChangeState --- CHANGE STATE ---
+// Выполняет действие op над подпиской. Недопустимый переход — models.ErrInvalidTransition.
+// Если version задан, переход выполняется только при совпадении версии.
+func (s *SubRepository) ChangeState(ctx context.Context, id uuid.UUID, op models.Operation, version *int64, at time.Time) (*models.Subscription, error) {
+	var result *models.Subscription
+	err := inTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := getSub(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("change state", before, version); err != nil {
+			return err
+		}
+
+		result, err = transition(ctx, tx, before, op, at)
+		return err
+	})
+	if err != nil {
+		return nil, mapError("change state", err)
+	}
+	return result, nil
+}
+
+// ExpireDue --- EXPIRE ---
+// Переводит в expired все подписки, дата окончания которых раньше now, и возвращает их.
+func (s *SubRepository) ExpireDue(ctx context.Context, now time.Time) ([]*models.Subscription, error) {
+	due := squirrel.And{
+		squirrel.Eq{"s.state": []models.SubscriptionState{models.StateTrial, models.StateActive, models.StatePaused}},
+		squirrel.Lt{"s.end_date": formatTime(now)},
+	}
+	changes, err := s.transitionDue(ctx, models.OperationExpire, due, now)
+	if err != nil {
+		return nil, mapError("expire subscriptions", err)
+	}
+	expired := make([]*models.Subscription, len(changes))
+	for i, change := range changes {
+		expired[i] = change.After
+	}
+	return expired, nil
+}
+
+// ConvertDueTrials --- CONVERT TRIALS ---
+// Переводит в active пробные подписки, пробный период которых закончился к now.
+func (s *SubRepository) ConvertDueTrials(ctx context.Context, now time.Time) ([]models.StateChange, error) {
+	due := squirrel.And{
+		squirrel.Eq{"s.state": models.StateTrial},
+		squirrel.LtOrEq{"s.trial_end": formatTime(now)},
+	}
+	converted, err := s.transitionDue(ctx, models.OperationActivate, due, now)
+	if err != nil {
+		return nil, mapError("convert trials", err)
+	}
+	return converted, nil
+}
+
+// transitionDue выполняет действие op над всеми неудалёнными подписками, подходящими под due.
+// Возвращает подписки до и после перехода.
+func (s *SubRepository) transitionDue(ctx context.Context, op models.Operation, due squirrel.Sqlizer, now time.Time) ([]models.StateChange, error) {
+	sqlStr, args, err := sqb.Select(selectSubColumns...).
+		From(tableName + " s").
+		Where(squirrel.Eq{"s.deleted_at": nil}).
+		Where(due).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build due query: %w", err)
+	}
+
+	var result []models.StateChange
+	err = inTx(ctx, s.db, func(tx *sql.Tx) error {
+		subs, err := querySubs(ctx, tx, sqlStr, args)
+		if err != nil {
+			return err
+		}
+
+		result = make([]models.StateChange, 0, len(subs))
+		for _, sub := range subs {
+			after, err := transition(ctx, tx, sub, op, now)
+			if err != nil {
+				return err
+			}
+			result = append(result, models.StateChange{Before: sub, After: after})
+		}
+		return nil
+	})
+	return result, err
+}
+
+// transition сохраняет переход подписки, выполненный действием op,
+// ведёт интервалы приостановки и пишет событие в журнал аудита.
+func transition(ctx context.Context, tx *sql.Tx, before *models.Subscription, op models.Operation, at time.Time) (*models.Subscription, error) {
+	next := *before
+	if err := next.Transition(op, at); err != nil {
+		return nil, err
+	}
+
+	sqlStr, args, err := sqb.Update(tableName).
+		Set("state", next.State).
+		Set("end_date", formatTimePtr(next.EndDate)).
+		Set("trial_end", formatTimePtr(next.TrialEnd)).
+		Set("updated_at", formatTime(next.UpdatedAt)).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": before.Id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build change state query: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return nil, err
+	}
+	after, err := getSub(ctx, tx, before.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if before.State == models.StatePaused {
+		if err := closePause(ctx, tx, before.Id, at); err != nil {
+			return nil, err
+		}
+	}
+	if after.State == models.StatePaused {
+		if err := openPause(ctx, tx, before.Id, at); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordEvent(ctx, tx, op, before.Id, before, after); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+func openPause(ctx context.Context, tx *sql.Tx, id uuid.UUID, at time.Time) error {
+	sqlStr, args, err := sqb.Insert(pausesTableName).
+		Columns("subscription_id", "paused_at").
+		Values(id, formatTime(at)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build pause query: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, sqlStr, args...)
+	return err
+}
+
+func closePause(ctx context.Context, tx *sql.Tx, id uuid.UUID, at time.Time) error {
+	sqlStr, args, err := sqb.Update(pausesTableName).
+		Set("resumed_at", formatTime(at)).
+		Where(squirrel.Eq{"subscription_id": id, "resumed_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build resume query: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, sqlStr, args...)
+	return err
+}
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// Таблица истории цен
+const pricesTableName = "subscription_prices"
+
+var priceColumns = []string{"subscription_id", "price", "effective_from", "created_at"}
+
+// priceAtSQL — цена подписки s, действующая на дату dateExpr (строка 'YYYY-MM-DD').
+// Без записей в subscription_prices используется s.price.
+func priceAtSQL(dateExpr string) string {
+	return "COALESCE((SELECT sp.price FROM " + pricesTableName + " sp " +
+		"WHERE sp.subscription_id = s.id AND sp.effective_from <= " + dateExpr + " " +
+		"ORDER BY sp.effective_from DESC LIMIT 1), s.price)"
+}
+
+func scanPrice(r row) (*models.PriceChange, error) {
+	var change models.PriceChange
+	err := r.Scan(&change.SubscriptionId, &change.Price, dateDest{&change.EffectiveFrom}, timeDest{&change.CreatedAt})
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// upsertPrice записывает цену, действующую с effectiveFrom; цена на ту же дату заменяется.
+// Возвращает заменённую запись (nil, если её не было) и новую.
+func upsertPrice(ctx context.Context, tx *sql.Tx, id uuid.UUID, price int64, effectiveFrom time.Time) (*models.PriceChange, *models.PriceChange, error) {
+	selectSQL, selectArgs, err := sqb.Select(priceColumns...).
+		From(pricesTableName).
+		Where(squirrel.Eq{"subscription_id": id, "effective_from": formatDate(effectiveFrom)}).
+		ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build select price query: %w", err)
+	}
+	replaced, err := scanPrice(tx.QueryRowContext(ctx, selectSQL, selectArgs...))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, err
+	}
+
+	sqlStr, args, err := sqb.Insert(pricesTableName).
+		Columns(priceColumns...).
+		Values(id, price, formatDate(effectiveFrom), formatTime(time.Now())).
+		Suffix("ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = excluded.price, created_at = excluded.created_at").
+		ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build upsert price query: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return nil, nil, err
+	}
+
+	change, err := scanPrice(tx.QueryRowContext(ctx, selectSQL, selectArgs...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return replaced, change, nil
+}
+
+// SchedulePrice --- SCHEDULE PRICE ---
+// Сохраняет изменение цены; запись о нём попадает в журнал аудита той же транзакцией.
+func (s *SubRepository) SchedulePrice(ctx context.Context, change *models.PriceChange) (*models.PriceChange, error) {
+	var result *models.PriceChange
+	err := inTx(ctx, s.db, func(tx *sql.Tx) error {
+		current, err := getSub(ctx, tx, change.SubscriptionId)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("schedule price", current, nil); err != nil {
+			return err
+		}
+
+		var replaced *models.PriceChange
+		replaced, result, err = upsertPrice(ctx, tx, change.SubscriptionId, change.Price, change.EffectiveFrom)
+		if err != nil {
+			return err
+		}
+		return recordPriceEvent(ctx, tx, change.SubscriptionId, replaced, result)
+	})
+	if err != nil {
+		return nil, mapError("schedule price", err)
+	}
+	return result, nil
+}
+
+// GetPrices --- PRICES ---
+// Возвращает историю и план цен подписки по возрастанию даты вступления в силу.
+func (s *SubRepository) GetPrices(ctx context.Context, id uuid.UUID) ([]*models.PriceChange, error) {
+	sqlStr, args, err := sqb.Select(priceColumns...).
+		From(pricesTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("effective_from").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build prices query: %w", err)
+	}
+
+	rows, err := conn(ctx, s.db).QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("prices query", err)
+	}
+	defer rows.Close()
+
+	prices := []*models.PriceChange{}
+	for rows.Next() {
+		change, err := scanPrice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan prices: %w", err)
+		}
+		prices = append(prices, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError("prices query", err)
+	}
+	return prices, nil
+}
@@ -0,0 +1,559 @@
+// Package sqlite — хранилище подписок в SQLite (драйвер modernc.org/sqlite без cgo).
+// Поведение совпадает с адаптером PostgreSQL (см. subrepotest); схема — в migrations/sqlite.
+// Напоминания, вебхуки и публикация в брокер в этом режиме не поддерживаются.
+package sqlite
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	sqlitedriver "modernc.org/sqlite"
+)
+
+type SubRepository struct {
+	db *sql.DB
+}
+
+var _ core_interfaces.ISubRepository = (*SubRepository)(nil)
+
+func NewSubRepository(db *sql.DB) *SubRepository {
+	return &SubRepository{db: db}
+}
+
+// Таблица
+const tableName = "subscriptions"
+
+// Колонки подписки в порядке сканирования (см. scanSub)
+var subColumns = []string{
+	"id", "service_name", "price", "currency", "user_id", "billing_interval", "interval_days",
+	"start_date", "end_date", "created_at", "updated_at", "version",
+	"deleted_at", "state", "trial_end",
+}
+
+// Цена, действующая на сегодня (date('now') в SQLite — дата UTC, как CURRENT_DATE в сессии PostgreSQL)
+var currentPriceSQL = priceAtSQL("date('now')")
+
+// Колонки для чтения подписки из subscriptions s: цена берётся действующая
+var selectSubColumns = slices.Concat(
+	prefixed(subColumns[:2]), []string{currentPriceSQL + " AS price"}, prefixed(subColumns[3:]))
+
+// Начало оплаты подписки s: дни пробного периода не оплачиваются
+const billingStartSQL = "COALESCE(s.trial_end, s.start_date)"
+
+// Функция SQL, переводящая строку в нижний регистр с учётом Unicode:
+// встроенные lower и LIKE в SQLite работают без учёта регистра только для ASCII
+const lowerFunc = "unicode_lower"
+
+var sqb = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question)
+
+func init() {
+	sqlitedriver.MustRegisterDeterministicScalarFunction(lowerFunc, 1,
+		func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+			if s, ok := args[0].(string); ok {
+				return strings.ToLower(s), nil
+			}
+			return args[0], nil
+		})
+}
+
+func prefixed(columns []string) []string {
+	result := make([]string, len(columns))
+	for i, column := range columns {
+		result[i] = "s." + column
+	}
+	return result
+}
+
+// subDest возвращает приёмники для колонок selectSubColumns.
+func subDest(sub *models.Subscription) []any {
+	return []any{&sub.Id, &sub.ServiceName, &sub.Price, &sub.Currency, &sub.UserId, &sub.BillingInterval, &sub.IntervalDays,
+		timeDest{&sub.StartDate}, nullTimeDest{&sub.EndDate}, timeDest{&sub.CreatedAt}, timeDest{&sub.UpdatedAt}, &sub.Version,
+		nullTimeDest{&sub.DeletedAt}, &sub.State, nullTimeDest{&sub.TrialEnd}}
+}
+
+// row — строка результата *sql.Row или *sql.Rows.
+type row interface {
+	Scan(dest ...any) error
+}
+
+func scanSub(r row) (*models.Subscription, error) {
+	var sub models.Subscription
+	if err := r.Scan(subDest(&sub)...); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// inTx выполняет fn в транзакции: ошибка fn откатывает транзакцию, иначе она фиксируется.
+// Транзакции открываются как BEGIN IMMEDIATE (см. db.NewSQLite): запись в базу
+// выполняется по очереди, что заменяет блокировку строк SELECT ... FOR UPDATE.
+// Внутри единицы работы (TxManager) fn выполняется в точке сохранения её транзакции.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	return withinTx(ctx, db, func(_ context.Context, tx *sql.Tx) error {
+		return fn(tx)
+	})
+}
+
+// Create --- INSERT ---
+// Создание и запись в журнал аудита выполняются в одной транзакции.
+func (s *SubRepository) Create(ctx context.Context, sub *models.Subscription) (*models.Subscription, error) {
+	var result *models.Subscription
+	err := inTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		result, err = insertSub(ctx, tx, sub)
+		return err
+	})
+	if err != nil {
+		return nil, mapError("insert subscription", err)
+	}
+
+	return result, nil
+}
+
+// insertSub вставляет подписку с начальной ценой и записью в журнале аудита.
+func insertSub(ctx context.Context, tx *sql.Tx, sub *models.Subscription) (*models.Subscription, error) {
+	sqlStr, args, err := sqb.Insert(tableName).
+		Columns(subColumns...).
+		Values(sub.Id, sub.ServiceName, sub.Price, sub.Currency, sub.UserId, sub.BillingInterval, sub.IntervalDays,
+			formatTime(sub.StartDate), formatTimePtr(sub.EndDate), formatTime(sub.CreatedAt), formatTime(sub.UpdatedAt), sub.Version,
+			formatTimePtr(sub.DeletedAt), sub.State, formatTimePtr(sub.TrialEnd)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build insert query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return nil, err
+	}
+	result, err := getSub(ctx, tx, sub.Id)
+	if err != nil {
+		return nil, err
+	}
+	// Начальная цена действует с даты начала подписки
+	if _, _, err := upsertPrice(ctx, tx, result.Id, result.Price, result.StartDate); err != nil {
+		return nil, err
+	}
+	if err := recordEvent(ctx, tx, models.OperationCreate, result.Id, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Update --- UPDATE ---
+// Обновление выполняется, только если версия в базе совпадает с sub.Version;
+// иначе возвращается models.ErrPreconditionFailed.
+func (s *SubRepository) Update(ctx context.Context, sub *models.Subscription) (*models.Subscription, error) {
+	sub.UpdatedAt = time.Now()
+
+	query := sqb.Update(tableName).
+		Set("service_name", sub.ServiceName).
+		Set("price", sub.Price).
+		Set("currency", sub.Currency).
+		Set("billing_interval", sub.BillingInterval).
+		Set("interval_days", sub.IntervalDays).
+		Set("start_date", formatTime(sub.StartDate)).
+		Set("end_date", formatTimePtr(sub.EndDate)).
+		Set("updated_at", formatTime(sub.UpdatedAt)).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": sub.Id, "version": sub.Version, "deleted_at": nil})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build update query: %w", err)
+	}
+
+	var result *models.Subscription
+	err = inTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := getSub(ctx, tx, sub.Id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("update subscription", before, &sub.Version); err != nil {
+			return err
+		}
+
+		// Новая цена действует с сегодняшнего дня (или с начала подписки, если она ещё не началась),
+		// прошлые месяцы считаются по прежней цене
+		if sub.Price != before.Price {
+			effectiveFrom := time.Now()
+			if sub.StartDate.After(effectiveFrom) {
+				effectiveFrom = sub.StartDate
+			}
+			if _, _, err := upsertPrice(ctx, tx, sub.Id, sub.Price, effectiveFrom); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return err
+		}
+		result, err = getSub(ctx, tx, sub.Id)
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, models.OperationUpdate, sub.Id, before, result)
+	})
+	if err != nil {
+		return nil, mapError("update subscription", err)
+	}
+
+	return result, nil
+}
+
+// Delete --- SOFT DELETE ---
+// Подписка переносится в корзину (deleted_at), версия увеличивается.
+// Если version задан, удаление выполняется только при совпадении версии.
+func (s *SubRepository) Delete(ctx context.Context, id uuid.UUID, version *int64) error {
+	query := sqb.Update(tableName).
+		Set("deleted_at", formatTime(time.Now())).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id, "deleted_at": nil})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("build delete query: %w", err)
+	}
+
+	err = inTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := getSub(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("delete subscription", before, version); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return err
+		}
+		after, err := getSub(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, models.OperationDelete, id, before, after)
+	})
+	if err != nil {
+		return mapError("delete subscription", err)
+	}
+	return nil
+}
+
+// Restore --- RESTORE ---
+// Возвращает подписку из корзины. Для неудалённой подписки — ErrConflict.
+func (s *SubRepository) Restore(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	query := sqb.Update(tableName).
+		Set("deleted_at", nil).
+		Set("updated_at", formatTime(time.Now())).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build restore query: %w", err)
+	}
+
+	var result *models.Subscription
+	err = inTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := getSub(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if !before.IsDeleted() {
+			return fmt.Errorf("restore subscription: %w: subscription is not deleted", models.ErrConflict)
+		}
+
+		if _, err := tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return err
+		}
+		result, err = getSub(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, models.OperationRestore, id, before, result)
+	})
+	if err != nil {
+		return nil, mapError("restore subscription", err)
+	}
+	return result, nil
+}
+
+// Purge --- PURGE ---
+// Окончательно удаляет подписки, находящиеся в корзине с момента раньше before.
+// История изменений сохраняется, для каждой подписки добавляется событие purge.
+func (s *SubRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	due := squirrel.Lt{"deleted_at": formatTime(before)}
+	selectSQL, selectArgs, err := sqb.Select(selectSubColumns...).
+		From(tableName + " s").
+		Where(due).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build purge select query: %w", err)
+	}
+	deleteSQL, deleteArgs, err := sqb.Delete(tableName).Where(due).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build purge query: %w", err)
+	}
+
+	var purged int64
+	err = inTx(ctx, s.db, func(tx *sql.Tx) error {
+		subs, err := querySubs(ctx, tx, selectSQL, selectArgs)
+		if err != nil {
+			return err
+		}
+		// Цены и интервалы приостановки удаляются каскадно
+		if _, err := tx.ExecContext(ctx, deleteSQL, deleteArgs...); err != nil {
+			return err
+		}
+
+		for _, sub := range subs {
+			if err := recordEvent(ctx, tx, models.OperationPurge, sub.Id, sub, nil); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(subs))
+		return nil
+	})
+	if err != nil {
+		return 0, mapError("purge subscriptions", err)
+	}
+	return purged, nil
+}
+
+// getSub читает подписку в транзакции.
+func getSub(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*models.Subscription, error) {
+	sqlStr, args, err := sqb.Select(selectSubColumns...).
+		From(tableName + " s").
+		Where(squirrel.Eq{"s.id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build select by id query: %w", err)
+	}
+	return scanSub(tx.QueryRowContext(ctx, sqlStr, args...))
+}
+
+// checkVersion проверяет, что подписку можно изменять:
+// удалённая считается отсутствующей (ErrNotFound), другая версия — ErrPreconditionFailed.
+func checkVersion(op string, current *models.Subscription, version *int64) error {
+	if current.IsDeleted() {
+		return fmt.Errorf("%s: %w", op, models.ErrNotFound)
+	}
+	if version != nil && current.Version != *version {
+		return fmt.Errorf("%s: %w: version mismatch", op, models.ErrPreconditionFailed)
+	}
+	return nil
+}
+
+// GetById --- GET BY ID ---
+// Возвращает подписку, в том числе удалённую: решение о её видимости принимает сервис.
+func (s *SubRepository) GetById(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	query := sqb.Select(selectSubColumns...).
+		From(tableName + " s").
+		Where(squirrel.Eq{"s.id": id})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build select by id query: %w", err)
+	}
+
+	sub, err := scanSub(conn(ctx, s.db).QueryRowContext(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, mapError("get by id", err)
+	}
+	return sub, nil
+}
+
+// GetAll --- GET ALL ---
+func (s *SubRepository) GetAll(ctx context.Context, filter *filters.SubFilter, page, pageSize int64) ([]*models.Subscription, error) {
+	offset := (page - 1) * pageSize
+
+	query := applySubFilter(sqb.Select(selectSubColumns...).From(tableName+" s"), filter).
+		OrderBy(orderBy(filter.Sort)...).
+		Limit(uint64(pageSize)).
+		Offset(uint64(offset))
+
+	return s.selectSubs(ctx, query)
+}
+
+// GetAllByCursor --- GET ALL (keyset) ---
+// Возвращает до limit подписок после (или перед) курсором и признак того,
+// что в этом направлении есть ещё записи. Без курсора — первая страница.
+func (s *SubRepository) GetAllByCursor(ctx context.Context, filter *filters.SubFilter, cursor *filters.Cursor, limit int64) ([]*models.Subscription, bool, error) {
+	query := applySubFilter(sqb.Select(selectSubColumns...).From(tableName+" s"), filter).
+		Limit(uint64(limit + 1))
+
+	backward := cursor != nil && cursor.Backward
+	switch {
+	case cursor == nil:
+		query = query.OrderBy("s.created_at DESC", "s.id DESC")
+	case backward:
+		query = query.Where("(s.created_at, s.id) > (?, ?)", formatTime(cursor.CreatedAt), cursor.Id).
+			OrderBy("s.created_at ASC", "s.id ASC")
+	default:
+		query = query.Where("(s.created_at, s.id) < (?, ?)", formatTime(cursor.CreatedAt), cursor.Id).
+			OrderBy("s.created_at DESC", "s.id DESC")
+	}
+
+	subs, err := s.selectSubs(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := int64(len(subs)) > limit
+	if hasMore {
+		subs = subs[:limit]
+	}
+	if backward {
+		slices.Reverse(subs)
+	}
+	return subs, hasMore, nil
+}
+
+// Count --- COUNT (Filter) ---
+func (s *SubRepository) Count(ctx context.Context, filter *filters.SubFilter) (int64, error) {
+	countQuery := applySubFilter(sqb.Select("COUNT(*)").From(tableName+" s"), filter)
+	countSQL, countArgs, err := countQuery.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build count query: %w", err)
+	}
+
+	var totalCount int64
+	err = conn(ctx, s.db).QueryRowContext(ctx, countSQL, countArgs...).Scan(&totalCount)
+	if err != nil {
+		return 0, mapError("count query", err)
+	}
+	return totalCount, nil
+}
+
+func (s *SubRepository) selectSubs(ctx context.Context, query squirrel.SelectBuilder) ([]*models.Subscription, error) {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get all query: %w", err)
+	}
+
+	subs, err := querySubs(ctx, conn(ctx, s.db), sqlStr, args)
+	if err != nil {
+		return nil, mapError("get all query", err)
+	}
+	return subs, nil
+}
+
+// querySubs выполняет выборку колонок selectSubColumns.
+func querySubs(ctx context.Context, q dbtx, sqlStr string, args []any) ([]*models.Subscription, error) {
+	rows, err := q.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*models.Subscription
+	for rows.Next() {
+		sub, err := scanSub(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan subscriptions: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// applySubFilter добавляет условия фильтра к выборке из subscriptions s.
+func applySubFilter(query squirrel.SelectBuilder, filter *filters.SubFilter) squirrel.SelectBuilder {
+	switch {
+	case filter.OnlyDeleted:
+		query = query.Where(squirrel.NotEq{"s.deleted_at": nil})
+	case !filter.IncludeDeleted:
+		query = query.Where(squirrel.Eq{"s.deleted_at": nil})
+	}
+	if filter.UserID != nil {
+		query = query.Where(squirrel.Eq{"s.user_id": *filter.UserID})
+	}
+	if filter.ServiceName != nil && *filter.ServiceName != "" {
+		query = query.Where(squirrel.Eq{"s.service_name": *filter.ServiceName})
+	}
+	if filter.ServiceNamePrefix != nil && *filter.ServiceNamePrefix != "" {
+		query = query.Where(lowerFunc+"(s.service_name) LIKE ? ESCAPE '\\'",
+			strings.ToLower(likeEscaper.Replace(*filter.ServiceNamePrefix))+"%")
+	}
+	if filter.State != nil {
+		query = query.Where(squirrel.Eq{"s.state": *filter.State})
+	}
+	if filter.TrialEndsBefore != nil {
+		query = query.Where(squirrel.Eq{"s.state": models.StateTrial}).
+			Where(squirrel.LtOrEq{"s.trial_end": formatTime(*filter.TrialEndsBefore)})
+	}
+	if filter.Status != nil {
+		now := formatTime(time.Now())
+		switch *filter.Status {
+		case filters.StatusActive:
+			query = query.Where("(s.end_date IS NULL OR s.end_date >= ?)", now)
+		case filters.StatusExpired:
+			query = query.Where("s.end_date < ?", now)
+		}
+	}
+	if filter.MinPrice != nil {
+		query = query.Where(currentPriceSQL+" >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where(currentPriceSQL+" <= ?", *filter.MaxPrice)
+	}
+	// Подписка пересекается с периодом [from, to]
+	if filter.From != nil && !filter.From.IsZero() {
+		query = query.Where(squirrel.Or{
+			squirrel.Eq{"s.end_date": nil},
+			squirrel.GtOrEq{"s.end_date": formatTime(*filter.From)},
+		})
+	}
+	if filter.To != nil && !filter.To.IsZero() {
+		query = query.Where(squirrel.LtOrEq{"s.start_date": formatTime(*filter.To)})
+	}
+	return query
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// orderBy переводит поля сортировки в ORDER BY; id добавляется для стабильного порядка.
+func orderBy(sort []filters.SortField) []string {
+	if len(sort) == 0 {
+		sort = []filters.SortField{{Field: "created_at", Desc: true}}
+	}
+
+	clauses := make([]string, 0, len(sort)+1)
+	for _, field := range sort {
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+		// Бессрочные подписки считаются самыми поздними
+		nulls := ""
+		if field.Field == "end_date" || field.Field == "trial_end" {
+			nulls = " NULLS LAST"
+			if field.Desc {
+				nulls = " NULLS FIRST"
+			}
+		}
+		column := "s." + field.Field
+		if field.Field == "price" {
+			column = currentPriceSQL
+		}
+		clauses = append(clauses, column+" "+direction+nulls)
+	}
+
+	last := sort[len(sort)-1]
+	if last.Desc {
+		return append(clauses, "s.id DESC")
+	}
+	return append(clauses, "s.id ASC")
+}
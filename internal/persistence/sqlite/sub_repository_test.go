@@ -0,0 +1,45 @@
+package sqlite_test
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/persistence/sqlite"
+	"SubscriptionService/internal/persistence/subrepotest"
+	"SubscriptionService/pkg/db"
+	"SubscriptionService/pkg/migrate"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// Каждая проверка выполняется на новой базе во временном каталоге.
+func TestSubRepositoryConformance(t *testing.T) {
+	logger := zerolog.Nop()
+
+	// Миграции ищутся относительно корня репозитория
+	t.Chdir("../../..")
+
+	// newDB создаёт новую базу во временном каталоге
+	newDB := func(t *testing.T) *sql.DB {
+		url := "sqlite://" + filepath.Join(t.TempDir(), "subscriptions.db")
+		if err := migrate.Up(url, &logger); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+		conn, err := db.NewSQLite(context.Background(), url, &logger)
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	}
+
+	subrepotest.Run(t, func(t *testing.T) core_interfaces.ISubRepository {
+		return sqlite.NewSubRepository(newDB(t))
+	})
+	subrepotest.RunUnitOfWork(t, func(t *testing.T) (core_interfaces.ISubRepository, core_interfaces.ITxManager) {
+		conn := newDB(t)
+		return sqlite.NewSubRepository(conn), sqlite.NewTxManager(conn)
+	})
+}
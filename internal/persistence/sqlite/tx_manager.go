@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+// TxManager открывает транзакции SQLite и передаёт их репозиториям через context.Context.
+// Пишущие транзакции SQLite выполняются по очереди (BEGIN IMMEDIATE), поэтому
+// конфликтов сериализации нет и повторять транзакции не нужно.
+type TxManager struct {
+	db *sql.DB
+}
+
+var _ core_interfaces.ITxManager = (*TxManager)(nil)
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+type txKey struct{}
+
+// txState — транзакция единицы работы и глубина вложенных точек сохранения.
+type txState struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// dbtx — операции, общие для *sql.DB и *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn возвращает транзакцию единицы работы из ctx, а без неё — базу.
+func conn(ctx context.Context, db *sql.DB) dbtx {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	return db
+}
+
+// WithinTx --- UNIT OF WORK ---
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := withinTx(ctx, m.db, func(ctx context.Context, _ *sql.Tx) error {
+		return fn(ctx)
+	})
+	return mapError("transaction", err)
+}
+
+// withinTx выполняет fn в точке сохранения транзакции единицы работы из ctx,
+// а без неё — в новой транзакции. Ошибка fn откатывает её изменения.
+func withinTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if outer, ok := ctx.Value(txKey{}).(*txState); ok {
+		state := &txState{tx: outer.tx, depth: outer.depth + 1}
+		name := "sp_" + strconv.Itoa(state.depth)
+		if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+			return err
+		}
+		if err := fn(context.WithValue(ctx, txKey{}, state), state.tx); err != nil {
+			// Откат выполняется и после отмены ctx, иначе внешняя транзакция останется с изменениями fn
+			rollbackCtx := context.WithoutCancel(ctx)
+			_, _ = state.tx.ExecContext(rollbackCtx, "ROLLBACK TO "+name)
+			_, _ = state.tx.ExecContext(rollbackCtx, "RELEASE "+name)
+			return err
+		}
+		_, err := state.tx.ExecContext(ctx, "RELEASE "+name)
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: tx}), tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
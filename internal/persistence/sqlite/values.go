@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"fmt"
+	"time"
+)
+
+// Моменты хранятся в TEXT в UTC с микросекундами (как timestamptz в PostgreSQL),
+// даты — в TEXT без времени. Строки фиксированной длины сравниваются в SQL
+// в хронологическом порядке.
+const (
+	timeLayout = "2006-01-02 15:04:05.000000"
+	dateLayout = "2006-01-02"
+)
+
+// formatTime переводит момент в формат хранения.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// formatTimePtr переводит необязательный момент в формат хранения; nil — NULL.
+func formatTimePtr(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// formatDate переводит дату в формат хранения. Как и pgx для колонок DATE,
+// берёт год, месяц и день в часовом поясе самого t.
+func formatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// timeDest — приёмник для колонки-момента.
+type timeDest struct{ dest *time.Time }
+
+func (d timeDest) Scan(src any) error {
+	t, err := parseTime(src)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("scan time: unexpected NULL")
+	}
+	*d.dest = *t
+	return nil
+}
+
+// nullTimeDest — приёмник для необязательной колонки-момента.
+type nullTimeDest struct{ dest **time.Time }
+
+func (d nullTimeDest) Scan(src any) error {
+	t, err := parseTime(src)
+	if err != nil {
+		return err
+	}
+	*d.dest = t
+	return nil
+}
+
+// dateDest — приёмник для колонки-даты; дата возвращается полночью UTC, как из pgx.
+type dateDest struct{ dest *time.Time }
+
+func (d dateDest) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("scan date: unsupported type %T", src)
+	}
+	t, err := time.ParseInLocation(dateLayout, s, time.UTC)
+	if err != nil {
+		return fmt.Errorf("scan date: %w", err)
+	}
+	*d.dest = t
+	return nil
+}
+
+// parseTime читает момент в формате хранения и возвращает его в местном часовом поясе,
+// как pgx для timestamptz.
+func parseTime(src any) (*time.Time, error) {
+	switch v := src.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		t, err := time.ParseInLocation(timeLayout, v, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("scan time: %w", err)
+		}
+		t = t.Local()
+		return &t, nil
+	default:
+		return nil, fmt.Errorf("scan time: unsupported type %T", src)
+	}
+}
+
+// jsonText сохраняет JSON как TEXT; пустой снимок — NULL.
+func jsonText(data []byte) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
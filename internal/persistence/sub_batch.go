@@ -0,0 +1,125 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateMany --- BULK INSERT ---
+// Подписки и их начальные цены загружаются командой COPY, записи журнала аудита
+// и их доставки отправляются пакетами запросов (pgx.Batch) — число обращений к базе
+// не зависит от количества подписок. При любой ошибке не создаётся ни одна подписка.
+func (s *SubRepository) CreateMany(ctx context.Context, subs []*models.Subscription) ([]*models.Subscription, error) {
+	if len(subs) == 0 {
+		return []*models.Subscription{}, nil
+	}
+
+	subRows := make([][]any, len(subs))
+	priceRows := make([][]any, len(subs))
+	ids := make([]uuid.UUID, len(subs))
+	for i, sub := range subs {
+		subRows[i] = []any{sub.Id, sub.ServiceName, sub.Price, sub.Currency, sub.UserId, sub.BillingInterval, sub.IntervalDays,
+			sub.StartDate, sub.EndDate, sub.CreatedAt, sub.UpdatedAt, sub.Version,
+			sub.DeletedAt, sub.State, sub.TrialEnd}
+		// Начальная цена действует с даты начала подписки
+		priceRows[i] = []any{sub.Id, sub.Price, sub.StartDate}
+		ids[i] = sub.Id
+	}
+
+	sqlStr, args, err := psql.Select(selectSubColumns...).
+		From(tableName+" s").
+		Where("s.id = ANY(?)", ids).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build select created query: %w", err)
+	}
+
+	var created []*models.Subscription
+	err = pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{tableName}, subColumns, pgx.CopyFromRows(subRows)); err != nil {
+			return err
+		}
+		priceCols := []string{"subscription_id", "price", "effective_from"}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{pricesTableName}, priceCols, pgx.CopyFromRows(priceRows)); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, sqlStr, args...)
+		if err != nil {
+			return err
+		}
+		found, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.Subscription, error) {
+			return scanSub(row)
+		})
+		if err != nil {
+			return err
+		}
+
+		// Результат возвращается в порядке subs
+		index := make(map[uuid.UUID]*models.Subscription, len(found))
+		for _, sub := range found {
+			index[sub.Id] = sub
+		}
+		created = make([]*models.Subscription, len(subs))
+		for i, id := range ids {
+			created[i] = index[id]
+		}
+		return recordCreateEvents(ctx, tx, created)
+	})
+	if err != nil {
+		return nil, mapError("bulk insert subscriptions", err)
+	}
+	return created, nil
+}
+
+// recordCreateEvents — recordEvent для созданных подписок: первый пакет записывает события
+// в журнал, второй ставит их в очереди доставки вебхуков и публикации в брокер.
+func recordCreateEvents(ctx context.Context, tx pgx.Tx, subs []*models.Subscription) error {
+	events := make([]models.SubscriptionEvent, len(subs))
+	batch := &pgx.Batch{}
+	for i, sub := range subs {
+		afterJSON, err := snapshot(sub)
+		if err != nil {
+			return err
+		}
+		sqlStr, args, err := insertEventQuery(ctx, models.OperationCreate, sub.Id, nil, afterJSON)
+		if err != nil {
+			return err
+		}
+		event := &events[i]
+		batch.Queue(sqlStr, args...).QueryRow(func(row pgx.Row) error {
+			return scanEvent(row, event)
+		})
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("record %s events: %w", models.OperationCreate, err)
+	}
+
+	batch = &pgx.Batch{}
+	for i := range events {
+		payload, err := json.Marshal(&events[i])
+		if err != nil {
+			return fmt.Errorf("marshal event payload: %w", err)
+		}
+		sqlStr, args, err := enqueueDeliveriesQuery(&events[i], payload)
+		if err != nil {
+			return err
+		}
+		batch.Queue(sqlStr, args...)
+
+		sqlStr, args, err = enqueueOutboxQuery(&events[i], payload)
+		if err != nil {
+			return err
+		}
+		batch.Queue(sqlStr, args...)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("enqueue %s events: %w", models.OperationCreate, err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Таблица списаний по периодам оплаты
+const chargesTableName = "charges"
+
+var chargeColumns = []string{"id", "subscription_id", "period_start", "period_end", "amount", "currency", "created_at"}
+
+// Наибольшее число периодов, списываемых по одной подписке за проход
+// (догоняющие списания после простоя разбиваются на несколько проходов)
+const maxPeriodsPerRun = 100
+
+// nextChargeSQL — начало следующего неоплаченного периода подписки s:
+// конец последнего списания, а без списаний — начало оплаты (после пробного периода).
+// Период, в котором подписка была возобновлена после паузы, начинается с момента возобновления.
+var nextChargeSQL = "GREATEST(COALESCE((SELECT max(c.period_end) FROM " + chargesTableName + " c WHERE c.subscription_id = s.id), " +
+	billingStartSQL + "), " +
+	"COALESCE((SELECT max(ps.resumed_at) FROM " + pausesTableName + " ps WHERE ps.subscription_id = s.id), '-infinity'))"
+
+// CreateDueCharges --- RENEW ---
+// Создаёт списания за наступившие периоды оплаты активных подписок, обрабатывая
+// не больше limit подписок. Подписки блокируются с SKIP LOCKED, поэтому несколько
+// реплик делят работу, а уникальность (subscription_id, period_start) делает
+// повторный проход безопасным. Возвращает число обработанных подписок и созданных списаний.
+func (s *SubRepository) CreateDueCharges(ctx context.Context, now time.Time, limit int) (int, int64, error) {
+	sqlStr, args, err := psql.Select(selectSubColumns...).
+		Column(nextChargeSQL+" AS next_charge_at").
+		From(tableName+" s").
+		Where(squirrel.Eq{"s.state": models.StateActive, "s.deleted_at": nil}).
+		Where(nextChargeSQL+" <= ?", now).
+		Where("(s.end_date IS NULL OR " + nextChargeSQL + " < s.end_date)").
+		OrderBy("next_charge_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE OF s SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return 0, 0, fmt.Errorf("build due charges query: %w", err)
+	}
+
+	var processed int
+	var created int64
+	err = pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		type dueSub struct {
+			sub  *models.Subscription
+			next time.Time
+		}
+		rows, err := tx.Query(ctx, sqlStr, args...)
+		if err != nil {
+			return err
+		}
+		due, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (dueSub, error) {
+			var sub models.Subscription
+			var next time.Time
+			err := row.Scan(append(subDest(&sub), &next)...)
+			return dueSub{sub: &sub, next: next}, err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, d := range due {
+			n, err := insertCharges(ctx, tx, d.sub, d.next, now)
+			if err != nil {
+				return err
+			}
+			created += n
+		}
+		processed = len(due)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, mapError("create charges", err)
+	}
+	return processed, created, nil
+}
+
+// insertCharges создаёт списания подписки за периоды, начинающиеся с start и не позже now.
+func insertCharges(ctx context.Context, tx pgx.Tx, sub *models.Subscription, start, now time.Time) (int64, error) {
+	var created int64
+	for i := 0; i < maxPeriodsPerRun && !start.After(now); i++ {
+		if sub.EndDate != nil && !start.Before(*sub.EndDate) {
+			break
+		}
+		end := sub.PeriodEnd(start)
+
+		sqlStr, args, err := psql.Insert(chargesTableName).
+			Columns("subscription_id", "period_start", "period_end", "amount", "currency").
+			Select(squirrel.Select("s.id").
+				Column("?::timestamptz", start).
+				Column("?::timestamptz", end).
+				Column(priceAtSQL("?::date"), start).
+				Column("s.currency").
+				From(tableName + " s").
+				Where(squirrel.Eq{"s.id": sub.Id})).
+			Suffix("ON CONFLICT (subscription_id, period_start) DO NOTHING").
+			ToSql()
+		if err != nil {
+			return 0, fmt.Errorf("build insert charge query: %w", err)
+		}
+		tag, err := tx.Exec(ctx, sqlStr, args...)
+		if err != nil {
+			return 0, err
+		}
+		created += tag.RowsAffected()
+		start = end
+	}
+	return created, nil
+}
+
+// GetCharges --- CHARGES ---
+// Возвращает списания подписки от новых периодов к старым.
+func (s *SubRepository) GetCharges(ctx context.Context, id uuid.UUID, page, pageSize int64) ([]*models.Charge, error) {
+	sqlStr, args, err := psql.Select(chargeColumns...).
+		From(chargesTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("period_start DESC").
+		Limit(uint64(pageSize)).
+		Offset(uint64((page - 1) * pageSize)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build charges query: %w", err)
+	}
+
+	rows, err := conn(ctx, s.db).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("charges query", err)
+	}
+
+	charges, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.Charge, error) {
+		var charge models.Charge
+		err := row.Scan(&charge.Id, &charge.SubscriptionId, &charge.PeriodStart, &charge.PeriodEnd,
+			&charge.Amount, &charge.Currency, &charge.CreatedAt)
+		return &charge, err
+	})
+	if err != nil {
+		return nil, mapError("charges query", err)
+	}
+	return charges, nil
+}
+
+// CountCharges --- CHARGES COUNT ---
+func (s *SubRepository) CountCharges(ctx context.Context, id uuid.UUID) (int64, error) {
+	sqlStr, args, err := psql.Select("COUNT(*)").
+		From(chargesTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build charges count query: %w", err)
+	}
+
+	var total int64
+	if err := conn(ctx, s.db).QueryRow(ctx, sqlStr, args...).Scan(&total); err != nil {
+		return 0, mapError("charges count query", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,156 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Таблица журнала аудита
+const eventsTableName = "subscription_events"
+
+var eventColumns = []string{
+	"id", "subscription_id", "operation", "actor", "request_id", "before", "after", "occurred_at",
+}
+
+// recordEvent добавляет запись в журнал аудита в транзакции изменения подписки.
+// Инициатор и идентификатор запроса берутся из контекста (models.AuditInfoFrom).
+func recordEvent(ctx context.Context, tx pgx.Tx, op models.Operation, id uuid.UUID, before, after *models.Subscription) error {
+	beforeJSON, err := snapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := snapshot(after)
+	if err != nil {
+		return err
+	}
+	return insertEvent(ctx, tx, op, id, beforeJSON, afterJSON)
+}
+
+// recordPriceEvent добавляет в журнал аудита изменение плана цен:
+// before — заменённая цена на ту же дату (если была), after — новая.
+func recordPriceEvent(ctx context.Context, tx pgx.Tx, id uuid.UUID, before, after *models.PriceChange) error {
+	var beforeJSON []byte
+	if before != nil {
+		data, err := json.Marshal(before)
+		if err != nil {
+			return fmt.Errorf("marshal price snapshot: %w", err)
+		}
+		beforeJSON = data
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return fmt.Errorf("marshal price snapshot: %w", err)
+	}
+	return insertEvent(ctx, tx, models.OperationSchedulePrice, id, beforeJSON, afterJSON)
+}
+
+func insertEvent(ctx context.Context, tx pgx.Tx, op models.Operation, id uuid.UUID, beforeJSON, afterJSON []byte) error {
+	sqlStr, args, err := insertEventQuery(ctx, op, id, beforeJSON, afterJSON)
+	if err != nil {
+		return err
+	}
+
+	var event models.SubscriptionEvent
+	if err := scanEvent(tx.QueryRow(ctx, sqlStr, args...), &event); err != nil {
+		return fmt.Errorf("record %s event: %w", op, err)
+	}
+
+	// Событие доставляется получателям вебхуков и в брокер сообщений в той же транзакции
+	payload, err := json.Marshal(&event)
+	if err != nil {
+		return fmt.Errorf("marshal event payload: %w", err)
+	}
+	if err := enqueueDeliveries(ctx, tx, &event, payload); err != nil {
+		return fmt.Errorf("enqueue %s event deliveries: %w", op, err)
+	}
+	if err := enqueueOutbox(ctx, tx, &event, payload); err != nil {
+		return fmt.Errorf("enqueue %s event for publishing: %w", op, err)
+	}
+	return nil
+}
+
+// insertEventQuery строит запрос записи события в журнал; результат читает scanEvent.
+func insertEventQuery(ctx context.Context, op models.Operation, id uuid.UUID, beforeJSON, afterJSON []byte) (string, []any, error) {
+	info := models.AuditInfoFrom(ctx)
+	sqlStr, args, err := psql.Insert(eventsTableName).
+		Columns("subscription_id", "operation", "actor", "request_id", "before", "after").
+		Values(id, op, info.Actor, info.RequestId, beforeJSON, afterJSON).
+		Suffix("RETURNING " + strings.Join(eventColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return "", nil, fmt.Errorf("build insert event query: %w", err)
+	}
+	return sqlStr, args, nil
+}
+
+func scanEvent(row pgx.Row, event *models.SubscriptionEvent) error {
+	return row.Scan(&event.Id, &event.SubscriptionId, &event.Operation, &event.Actor,
+		&event.RequestId, &event.Before, &event.After, &event.OccurredAt)
+}
+
+// snapshot сериализует состояние подписки для журнала; nil — отсутствие состояния.
+func snapshot(sub *models.Subscription) ([]byte, error) {
+	if sub == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return nil, fmt.Errorf("marshal subscription snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// GetHistory --- HISTORY ---
+// Возвращает события подписки от новых к старым.
+func (s *SubRepository) GetHistory(ctx context.Context, id uuid.UUID, page, pageSize int64) ([]*models.SubscriptionEvent, error) {
+	sqlStr, args, err := psql.Select(eventColumns...).
+		From(eventsTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("id DESC").
+		Limit(uint64(pageSize)).
+		Offset(uint64((page - 1) * pageSize)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build history query: %w", err)
+	}
+
+	rows, err := conn(ctx, s.db).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("history query", err)
+	}
+
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.SubscriptionEvent, error) {
+		var event models.SubscriptionEvent
+		err := row.Scan(&event.Id, &event.SubscriptionId, &event.Operation, &event.Actor, &event.RequestId,
+			&event.Before, &event.After, &event.OccurredAt)
+		return &event, err
+	})
+	if err != nil {
+		return nil, mapError("history query", err)
+	}
+	return events, nil
+}
+
+// CountHistory --- HISTORY COUNT ---
+func (s *SubRepository) CountHistory(ctx context.Context, id uuid.UUID) (int64, error) {
+	sqlStr, args, err := psql.Select("COUNT(*)").
+		From(eventsTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build history count query: %w", err)
+	}
+
+	var total int64
+	if err := conn(ctx, s.db).QueryRow(ctx, sqlStr, args...).Scan(&total); err != nil {
+		return 0, mapError("history count query", err)
+	}
+	return total, nil
+}
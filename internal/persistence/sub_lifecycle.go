@@ -0,0 +1,176 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Таблица интервалов приостановки подписок
+const pausesTableName = "subscription_pauses"
+
+// ChangeState --- CHANGE STATE ---
+// Выполняет действие op над подпиской. Недопустимый переход — models.ErrInvalidTransition.
+// Если version задан, переход выполняется только при совпадении версии.
+func (s *SubRepository) ChangeState(ctx context.Context, id uuid.UUID, op models.Operation, version *int64, at time.Time) (*models.Subscription, error) {
+	var result *models.Subscription
+	err := pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		before, err := lockSub(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("change state", before, version); err != nil {
+			return err
+		}
+
+		result, err = transition(ctx, tx, before, op, at)
+		return err
+	})
+	if err != nil {
+		return nil, mapError("change state", err)
+	}
+	return result, nil
+}
+
+// ExpireDue --- EXPIRE ---
+// Переводит в expired все подписки, дата окончания которых раньше now, и возвращает их.
+func (s *SubRepository) ExpireDue(ctx context.Context, now time.Time) ([]*models.Subscription, error) {
+	due := squirrel.And{
+		squirrel.Eq{"s.state": []models.SubscriptionState{models.StateTrial, models.StateActive, models.StatePaused}},
+		squirrel.Lt{"s.end_date": now},
+	}
+	changes, err := s.transitionDue(ctx, models.OperationExpire, due, now)
+	if err != nil {
+		return nil, mapError("expire subscriptions", err)
+	}
+	expired := make([]*models.Subscription, len(changes))
+	for i, change := range changes {
+		expired[i] = change.After
+	}
+	return expired, nil
+}
+
+// ConvertDueTrials --- CONVERT TRIALS ---
+// Переводит в active пробные подписки, пробный период которых закончился к now.
+func (s *SubRepository) ConvertDueTrials(ctx context.Context, now time.Time) ([]models.StateChange, error) {
+	due := squirrel.And{
+		squirrel.Eq{"s.state": models.StateTrial},
+		squirrel.LtOrEq{"s.trial_end": now},
+	}
+	converted, err := s.transitionDue(ctx, models.OperationActivate, due, now)
+	if err != nil {
+		return nil, mapError("convert trials", err)
+	}
+	return converted, nil
+}
+
+// transitionDue выполняет действие op над всеми неудалёнными подписками, подходящими под due.
+// Строки, заблокированные другими транзакциями, пропускаются до следующего прохода.
+// Возвращает подписки до и после перехода.
+func (s *SubRepository) transitionDue(ctx context.Context, op models.Operation, due squirrel.Sqlizer, now time.Time) ([]models.StateChange, error) {
+	sqlStr, args, err := psql.Select(selectSubColumns...).
+		From(tableName + " s").
+		Where(squirrel.Eq{"s.deleted_at": nil}).
+		Where(due).
+		Suffix("FOR UPDATE OF s SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build due query: %w", err)
+	}
+
+	var result []models.StateChange
+	err = pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sqlStr, args...)
+		if err != nil {
+			return err
+		}
+		subs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.Subscription, error) {
+			return scanSub(row)
+		})
+		if err != nil {
+			return err
+		}
+
+		result = make([]models.StateChange, 0, len(subs))
+		for _, sub := range subs {
+			after, err := transition(ctx, tx, sub, op, now)
+			if err != nil {
+				return err
+			}
+			result = append(result, models.StateChange{Before: sub, After: after})
+		}
+		return nil
+	})
+	return result, err
+}
+
+// transition сохраняет переход заблокированной подписки, выполненный действием op,
+// ведёт интервалы приостановки и пишет событие в журнал аудита.
+func transition(ctx context.Context, tx pgx.Tx, before *models.Subscription, op models.Operation, at time.Time) (*models.Subscription, error) {
+	next := *before
+	if err := next.Transition(op, at); err != nil {
+		return nil, err
+	}
+
+	sqlStr, args, err := psql.Update(aliasedTable).
+		Set("state", next.State).
+		Set("end_date", next.EndDate).
+		Set("trial_end", next.TrialEnd).
+		Set("updated_at", next.UpdatedAt).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": before.Id}).
+		Suffix(returningSub).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build change state query: %w", err)
+	}
+	after, err := scanSub(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	if before.State == models.StatePaused {
+		if err := closePause(ctx, tx, before.Id, at); err != nil {
+			return nil, err
+		}
+	}
+	if after.State == models.StatePaused {
+		if err := openPause(ctx, tx, before.Id, at); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordEvent(ctx, tx, op, before.Id, before, after); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+func openPause(ctx context.Context, tx pgx.Tx, id uuid.UUID, at time.Time) error {
+	sqlStr, args, err := psql.Insert(pausesTableName).
+		Columns("subscription_id", "paused_at").
+		Values(id, at).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build pause query: %w", err)
+	}
+	_, err = tx.Exec(ctx, sqlStr, args...)
+	return err
+}
+
+func closePause(ctx context.Context, tx pgx.Tx, id uuid.UUID, at time.Time) error {
+	sqlStr, args, err := psql.Update(pausesTableName).
+		Set("resumed_at", at).
+		Where(squirrel.Eq{"subscription_id": id, "resumed_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build resume query: %w", err)
+	}
+	_, err = tx.Exec(ctx, sqlStr, args...)
+	return err
+}
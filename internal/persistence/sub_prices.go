@@ -0,0 +1,117 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Таблица истории цен
+const pricesTableName = "subscription_prices"
+
+var priceColumns = []string{"subscription_id", "price", "effective_from", "created_at"}
+
+// priceAtSQL — цена подписки s, действующая на дату dateExpr.
+// Без записей в subscription_prices используется s.price.
+func priceAtSQL(dateExpr string) string {
+	return "COALESCE((SELECT sp.price FROM " + pricesTableName + " sp " +
+		"WHERE sp.subscription_id = s.id AND sp.effective_from <= " + dateExpr + " " +
+		"ORDER BY sp.effective_from DESC LIMIT 1), s.price)"
+}
+
+func scanPrice(row pgx.Row) (*models.PriceChange, error) {
+	var change models.PriceChange
+	if err := row.Scan(&change.SubscriptionId, &change.Price, &change.EffectiveFrom, &change.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// upsertPrice записывает цену, действующую с effectiveFrom; цена на ту же дату заменяется.
+// Возвращает заменённую запись (nil, если её не было) и новую.
+func upsertPrice(ctx context.Context, tx pgx.Tx, id uuid.UUID, price int64, effectiveFrom time.Time) (*models.PriceChange, *models.PriceChange, error) {
+	sqlStr, args, err := psql.Select(priceColumns...).
+		From(pricesTableName).
+		Where(squirrel.Eq{"subscription_id": id, "effective_from": effectiveFrom}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build select price query: %w", err)
+	}
+	replaced, err := scanPrice(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, err
+	}
+
+	sqlStr, args, err = psql.Insert(pricesTableName).
+		Columns("subscription_id", "price", "effective_from").
+		Values(id, price, effectiveFrom).
+		Suffix("ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price, created_at = now() " +
+			"RETURNING subscription_id, price, effective_from, created_at").
+		ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build upsert price query: %w", err)
+	}
+	change, err := scanPrice(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return replaced, change, nil
+}
+
+// SchedulePrice --- SCHEDULE PRICE ---
+// Сохраняет изменение цены; запись о нём попадает в журнал аудита той же транзакцией.
+func (s *SubRepository) SchedulePrice(ctx context.Context, change *models.PriceChange) (*models.PriceChange, error) {
+	var result *models.PriceChange
+	err := pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		current, err := lockSub(ctx, tx, change.SubscriptionId)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("schedule price", current, nil); err != nil {
+			return err
+		}
+
+		var replaced *models.PriceChange
+		replaced, result, err = upsertPrice(ctx, tx, change.SubscriptionId, change.Price, change.EffectiveFrom)
+		if err != nil {
+			return err
+		}
+		return recordPriceEvent(ctx, tx, change.SubscriptionId, replaced, result)
+	})
+	if err != nil {
+		return nil, mapError("schedule price", err)
+	}
+	return result, nil
+}
+
+// GetPrices --- PRICES ---
+// Возвращает историю и план цен подписки по возрастанию даты вступления в силу.
+func (s *SubRepository) GetPrices(ctx context.Context, id uuid.UUID) ([]*models.PriceChange, error) {
+	sqlStr, args, err := psql.Select(priceColumns...).
+		From(pricesTableName).
+		Where(squirrel.Eq{"subscription_id": id}).
+		OrderBy("effective_from").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build prices query: %w", err)
+	}
+
+	rows, err := conn(ctx, s.db).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("prices query", err)
+	}
+	prices, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.PriceChange, error) {
+		return scanPrice(row)
+	})
+	if err != nil {
+		return nil, mapError("prices query", err)
+	}
+	return prices, nil
+}
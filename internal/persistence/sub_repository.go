@@ -5,9 +5,9 @@ import (
 	"SubscriptionService/internal/core/models"
 	"SubscriptionService/internal/core/ports/filters"
 	"context"
-	"errors"
 	"fmt"
-	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -29,84 +29,288 @@ func NewSubRepository(db *pgxpool.Pool) *SubRepository {
 // Таблица
 const tableName = "subscriptions"
 
+// Колонки подписки в порядке сканирования (см. scanSub)
+var subColumns = []string{
+	"id", "service_name", "price", "currency", "user_id", "billing_interval", "interval_days",
+	"start_date", "end_date", "created_at", "updated_at", "version",
+	"deleted_at", "state", "trial_end",
+}
+
+// Цена, действующая на сегодня (см. subscription_prices); s.price — цена последнего изменения
+var currentPriceSQL = priceAtSQL("CURRENT_DATE")
+
+// Колонки для чтения подписки из subscriptions s: цена берётся действующая
+var selectSubColumns = slices.Concat(subColumns[:2], []string{currentPriceSQL + " AS price"}, subColumns[3:])
+
+var returningSub = "RETURNING " + strings.Join(selectSubColumns, ", ")
+
+// Начало оплаты подписки s: дни пробного периода не оплачиваются
+const billingStartSQL = "COALESCE(s.trial_end, s.start_date)"
+
+// Таблица с псевдонимом s для INSERT/UPDATE/DELETE ... RETURNING
+const aliasedTable = tableName + " AS s"
+
+// Цена подписки в оплачиваемом месяце p.period, приведённая к одному месяцу
+// (см. models.BillingInterval.Months). sp — действующая в этом месяце цена (см. billedPeriods).
+const monthlyPriceSQL = `(COALESCE(sp.price, s.price)::numeric / CASE s.billing_interval
+	WHEN 'weekly' THEN 7 / 30.4375
+	WHEN 'quarterly' THEN 3
+	WHEN 'yearly' THEN 12
+	WHEN 'custom' THEN s.interval_days / 30.4375
+	ELSE 1 END)`
+
 var psql = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
+// subDest возвращает приёмники для колонок selectSubColumns.
+func subDest(sub *models.Subscription) []any {
+	return []any{&sub.Id, &sub.ServiceName, &sub.Price, &sub.Currency, &sub.UserId, &sub.BillingInterval, &sub.IntervalDays,
+		&sub.StartDate, &sub.EndDate, &sub.CreatedAt, &sub.UpdatedAt, &sub.Version,
+		&sub.DeletedAt, &sub.State, &sub.TrialEnd}
+}
+
+func scanSub(row pgx.Row) (*models.Subscription, error) {
+	var sub models.Subscription
+	if err := row.Scan(subDest(&sub)...); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Create --- INSERT ---
+// Создание и запись в журнал аудита выполняются в одной транзакции.
 func (s *SubRepository) Create(ctx context.Context, sub *models.Subscription) (*models.Subscription, error) {
-	query := psql.Insert(tableName).
-		Columns("id", "service_name", "price", "user_id", "start_date", "end_date", "created_at", "updated_at").
-		Values(sub.Id, sub.ServiceName, sub.Price, sub.UserId, sub.StartDate, sub.EndDate, sub.CreatedAt, sub.UpdatedAt).
-		Suffix("RETURNING id, service_name, price, user_id, start_date, end_date, created_at, updated_at")
+	query := psql.Insert(aliasedTable).
+		Columns(subColumns...).
+		Values(sub.Id, sub.ServiceName, sub.Price, sub.Currency, sub.UserId, sub.BillingInterval, sub.IntervalDays,
+			sub.StartDate, sub.EndDate, sub.CreatedAt, sub.UpdatedAt, sub.Version,
+			sub.DeletedAt, sub.State, sub.TrialEnd).
+		Suffix(returningSub)
 
 	sqlStr, args, err := query.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build insert query: %w", err)
 	}
 
-	row := s.db.QueryRow(ctx, sqlStr, args...)
-	var result models.Subscription
-	err = row.Scan(&result.Id, &result.ServiceName, &result.Price, &result.UserId,
-		&result.StartDate, &result.EndDate, &result.CreatedAt, &result.UpdatedAt)
+	var result *models.Subscription
+	err = pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		result, err = scanSub(tx.QueryRow(ctx, sqlStr, args...))
+		if err != nil {
+			return err
+		}
+		// Начальная цена действует с даты начала подписки
+		if _, _, err := upsertPrice(ctx, tx, result.Id, result.Price, result.StartDate); err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, models.OperationCreate, result.Id, nil, result)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("insert subscription: %w", err)
+		return nil, mapError("insert subscription", err)
 	}
 
-	return &result, nil
+	return result, nil
 }
 
 // Update --- UPDATE ---
+// Обновление выполняется, только если версия в базе совпадает с sub.Version;
+// иначе возвращается models.ErrPreconditionFailed.
 func (s *SubRepository) Update(ctx context.Context, sub *models.Subscription) (*models.Subscription, error) {
 	sub.UpdatedAt = time.Now()
 
-	query := psql.Update(tableName).
+	query := psql.Update(aliasedTable).
 		Set("service_name", sub.ServiceName).
 		Set("price", sub.Price).
+		Set("currency", sub.Currency).
+		Set("billing_interval", sub.BillingInterval).
+		Set("interval_days", sub.IntervalDays).
 		Set("start_date", sub.StartDate).
 		Set("end_date", sub.EndDate).
 		Set("updated_at", sub.UpdatedAt).
-		Where(squirrel.Eq{"id": sub.Id}).
-		Suffix("RETURNING id, service_name, price, user_id, start_date, end_date, created_at, updated_at")
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": sub.Id, "version": sub.Version, "deleted_at": nil}).
+		Suffix(returningSub)
 
 	sqlStr, args, err := query.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build update query: %w", err)
 	}
 
-	row := s.db.QueryRow(ctx, sqlStr, args...)
-	var result models.Subscription
-	err = row.Scan(&result.Id, &result.ServiceName, &result.Price, &result.UserId,
-		&result.StartDate, &result.EndDate, &result.CreatedAt, &result.UpdatedAt)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
+	var result *models.Subscription
+	err = pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		before, err := lockSub(ctx, tx, sub.Id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("update subscription", before, &sub.Version); err != nil {
+			return err
 		}
-		return nil, fmt.Errorf("update subscription: %w", err)
+
+		// Новая цена действует с сегодняшнего дня (или с начала подписки, если она ещё не началась),
+		// прошлые месяцы считаются по прежней цене
+		if sub.Price != before.Price {
+			effectiveFrom := time.Now()
+			if sub.StartDate.After(effectiveFrom) {
+				effectiveFrom = sub.StartDate
+			}
+			if _, _, err := upsertPrice(ctx, tx, sub.Id, sub.Price, effectiveFrom); err != nil {
+				return err
+			}
+		}
+
+		result, err = scanSub(tx.QueryRow(ctx, sqlStr, args...))
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, models.OperationUpdate, sub.Id, before, result)
+	})
+	if err != nil {
+		return nil, mapError("update subscription", err)
 	}
 
-	return &result, nil
+	return result, nil
 }
 
-// Delete --- DELETE ---
-func (s *SubRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := psql.Delete(tableName).Where(squirrel.Eq{"id": id})
+// Delete --- SOFT DELETE ---
+// Подписка переносится в корзину (deleted_at), версия увеличивается.
+// Если version задан, удаление выполняется только при совпадении версии.
+func (s *SubRepository) Delete(ctx context.Context, id uuid.UUID, version *int64) error {
+	query := psql.Update(aliasedTable).
+		Set("deleted_at", squirrel.Expr("now()")).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id, "deleted_at": nil}).
+		Suffix(returningSub)
+
 	sqlStr, args, err := query.ToSql()
 	if err != nil {
 		return fmt.Errorf("build delete query: %w", err)
 	}
 
-	cmd, err := s.db.Exec(ctx, sqlStr, args...)
+	err = pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		before, err := lockSub(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion("delete subscription", before, version); err != nil {
+			return err
+		}
+
+		after, err := scanSub(tx.QueryRow(ctx, sqlStr, args...))
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, models.OperationDelete, id, before, after)
+	})
+	if err != nil {
+		return mapError("delete subscription", err)
+	}
+	return nil
+}
+
+// Restore --- RESTORE ---
+// Возвращает подписку из корзины. Для неудалённой подписки — ErrConflict.
+func (s *SubRepository) Restore(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	query := psql.Update(aliasedTable).
+		Set("deleted_at", nil).
+		Set("updated_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Suffix(returningSub)
+
+	sqlStr, args, err := query.ToSql()
 	if err != nil {
-		return fmt.Errorf("delete subscription: %w", err)
+		return nil, fmt.Errorf("build restore query: %w", err)
 	}
-	if cmd.RowsAffected() == 0 {
-		return pgx.ErrNoRows
+
+	var result *models.Subscription
+	err = pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		before, err := lockSub(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if !before.IsDeleted() {
+			return fmt.Errorf("restore subscription: %w: subscription is not deleted", models.ErrConflict)
+		}
+
+		result, err = scanSub(tx.QueryRow(ctx, sqlStr, args...))
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, models.OperationRestore, id, before, result)
+	})
+	if err != nil {
+		return nil, mapError("restore subscription", err)
+	}
+	return result, nil
+}
+
+// Purge --- PURGE ---
+// Окончательно удаляет подписки, находящиеся в корзине с момента раньше before.
+// История изменений сохраняется, для каждой подписки добавляется событие purge.
+func (s *SubRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	query := psql.Delete(aliasedTable).
+		Where(squirrel.Lt{"deleted_at": before}).
+		Suffix(returningSub)
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build purge query: %w", err)
+	}
+
+	var purged int64
+	err = pgx.BeginFunc(ctx, conn(ctx, s.db), func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sqlStr, args...)
+		if err != nil {
+			return err
+		}
+		subs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.Subscription, error) {
+			return scanSub(row)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, sub := range subs {
+			if err := recordEvent(ctx, tx, models.OperationPurge, sub.Id, sub, nil); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(subs))
+		return nil
+	})
+	if err != nil {
+		return 0, mapError("purge subscriptions", err)
+	}
+	return purged, nil
+}
+
+// lockSub читает подписку с блокировкой строки до конца транзакции.
+func lockSub(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*models.Subscription, error) {
+	sqlStr, args, err := psql.Select(selectSubColumns...).
+		From(tableName + " s").
+		Where(squirrel.Eq{"id": id}).
+		Suffix("FOR UPDATE OF s").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build lock query: %w", err)
+	}
+	return scanSub(tx.QueryRow(ctx, sqlStr, args...))
+}
+
+// checkVersion проверяет, что заблокированную подписку можно изменять:
+// удалённая считается отсутствующей (ErrNotFound), другая версия — ErrPreconditionFailed.
+func checkVersion(op string, current *models.Subscription, version *int64) error {
+	if current.IsDeleted() {
+		return fmt.Errorf("%s: %w", op, models.ErrNotFound)
+	}
+	if version != nil && current.Version != *version {
+		return fmt.Errorf("%s: %w: version mismatch", op, models.ErrPreconditionFailed)
 	}
 	return nil
 }
 
 // GetById --- GET BY ID ---
+// Возвращает подписку, в том числе удалённую: решение о её видимости принимает сервис.
 func (s *SubRepository) GetById(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
-	query := psql.Select("id", "service_name", "price", "user_id", "start_date", "end_date", "created_at", "updated_at").
-		From(tableName).
+	query := psql.Select(selectSubColumns...).
+		From(tableName + " s").
 		Where(squirrel.Eq{"id": id})
 
 	sqlStr, args, err := query.ToSql()
@@ -114,95 +318,334 @@ func (s *SubRepository) GetById(ctx context.Context, id uuid.UUID) (*models.Subs
 		return nil, fmt.Errorf("build select by id query: %w", err)
 	}
 
-	row := s.db.QueryRow(ctx, sqlStr, args...)
-	var sub models.Subscription
-	err = row.Scan(&sub.Id, &sub.ServiceName, &sub.Price, &sub.UserId,
-		&sub.StartDate, &sub.EndDate, &sub.CreatedAt, &sub.UpdatedAt)
+	sub, err := scanSub(conn(ctx, s.db).QueryRow(ctx, sqlStr, args...))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("get by id: %w", err)
+		return nil, mapError("get by id", err)
 	}
-	return &sub, nil
+	return sub, nil
 }
 
 // GetAll --- GET ALL ---
-func (s *SubRepository) GetAll(ctx context.Context, page, pageSize int64) ([]*models.Subscription, int64, int64, error) {
+func (s *SubRepository) GetAll(ctx context.Context, filter *filters.SubFilter, page, pageSize int64) ([]*models.Subscription, error) {
 	offset := (page - 1) * pageSize
 
-	// Общее количество
-	countQuery := psql.Select("COUNT(*)").From(tableName)
+	query := applySubFilter(psql.Select(selectSubColumns...).From(tableName+" s"), filter).
+		OrderBy(orderBy(filter.Sort)...).
+		Limit(uint64(pageSize)).
+		Offset(uint64(offset))
+
+	return s.selectSubs(ctx, query)
+}
+
+// GetAllByCursor --- GET ALL (keyset) ---
+// Возвращает до limit подписок после (или перед) курсором и признак того,
+// что в этом направлении есть ещё записи. Без курсора — первая страница.
+func (s *SubRepository) GetAllByCursor(ctx context.Context, filter *filters.SubFilter, cursor *filters.Cursor, limit int64) ([]*models.Subscription, bool, error) {
+	query := applySubFilter(psql.Select(selectSubColumns...).From(tableName+" s"), filter).
+		Limit(uint64(limit + 1))
+
+	backward := cursor != nil && cursor.Backward
+	switch {
+	case cursor == nil:
+		query = query.OrderBy("s.created_at DESC", "s.id DESC")
+	case backward:
+		query = query.Where("(s.created_at, s.id) > (?, ?)", cursor.CreatedAt, cursor.Id).
+			OrderBy("s.created_at ASC", "s.id ASC")
+	default:
+		query = query.Where("(s.created_at, s.id) < (?, ?)", cursor.CreatedAt, cursor.Id).
+			OrderBy("s.created_at DESC", "s.id DESC")
+	}
+
+	subs, err := s.selectSubs(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := int64(len(subs)) > limit
+	if hasMore {
+		subs = subs[:limit]
+	}
+	if backward {
+		slices.Reverse(subs)
+	}
+	return subs, hasMore, nil
+}
+
+// Count --- COUNT (Filter) ---
+func (s *SubRepository) Count(ctx context.Context, filter *filters.SubFilter) (int64, error) {
+	countQuery := applySubFilter(psql.Select("COUNT(*)").From(tableName+" s"), filter)
 	countSQL, countArgs, err := countQuery.ToSql()
 	if err != nil {
-		return nil, 0, 0, fmt.Errorf("build count query: %w", err)
+		return 0, fmt.Errorf("build count query: %w", err)
 	}
 
 	var totalCount int64
-	err = s.db.QueryRow(ctx, countSQL, countArgs...).Scan(&totalCount)
+	err = conn(ctx, s.db).QueryRow(ctx, countSQL, countArgs...).Scan(&totalCount)
 	if err != nil {
-		return nil, 0, 0, fmt.Errorf("count query: %w", err)
+		return 0, mapError("count query", err)
 	}
+	return totalCount, nil
+}
 
-	totalPages := int64(math.Ceil(float64(totalCount) / float64(pageSize)))
-
-	query := psql.Select("id", "service_name", "price", "user_id", "start_date", "end_date", "created_at", "updated_at").
-		From(tableName).
-		OrderBy("created_at DESC").
-		Limit(uint64(pageSize)).
-		Offset(uint64(offset))
-
+func (s *SubRepository) selectSubs(ctx context.Context, query squirrel.SelectBuilder) ([]*models.Subscription, error) {
 	sqlStr, args, err := query.ToSql()
 	if err != nil {
-		return nil, 0, 0, fmt.Errorf("build get all query: %w", err)
+		return nil, fmt.Errorf("build get all query: %w", err)
 	}
 
-	rows, err := s.db.Query(ctx, sqlStr, args...)
+	rows, err := conn(ctx, s.db).Query(ctx, sqlStr, args...)
 	if err != nil {
-		return nil, 0, 0, fmt.Errorf("get all query: %w", err)
+		return nil, mapError("get all query", err)
 	}
 	defer rows.Close()
 
 	var subs []*models.Subscription
 	for rows.Next() {
-		var sub models.Subscription
-		err = rows.Scan(&sub.Id, &sub.ServiceName, &sub.Price, &sub.UserId,
-			&sub.StartDate, &sub.EndDate, &sub.CreatedAt, &sub.UpdatedAt)
+		sub, err := scanSub(rows)
 		if err != nil {
-			return nil, 0, 0, fmt.Errorf("scan subscriptions: %w", err)
+			return nil, fmt.Errorf("scan subscriptions: %w", err)
 		}
-		subs = append(subs, &sub)
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError("get all query", err)
 	}
 
-	return subs, totalCount, totalPages, nil
+	return subs, nil
 }
 
-// SumSubscriptionsCost --- SUM (Filter) ---
-func (s *SubRepository) SumSubscriptionsCost(ctx context.Context, filter *filters.SubFilter) (int64, error) {
-	query := psql.Select("COALESCE(SUM(price), 0)").From(tableName)
-
+// applySubFilter добавляет условия фильтра к выборке из subscriptions s.
+func applySubFilter(query squirrel.SelectBuilder, filter *filters.SubFilter) squirrel.SelectBuilder {
+	switch {
+	case filter.OnlyDeleted:
+		query = query.Where(squirrel.NotEq{"s.deleted_at": nil})
+	case !filter.IncludeDeleted:
+		query = query.Where(squirrel.Eq{"s.deleted_at": nil})
+	}
 	if filter.UserID != nil {
-		query = query.Where(squirrel.Eq{"user_id": *filter.UserID})
+		query = query.Where(squirrel.Eq{"s.user_id": *filter.UserID})
 	}
 	if filter.ServiceName != nil && *filter.ServiceName != "" {
-		query = query.Where(squirrel.Eq{"service_name": *filter.ServiceName})
+		query = query.Where(squirrel.Eq{"s.service_name": *filter.ServiceName})
+	}
+	if filter.ServiceNamePrefix != nil && *filter.ServiceNamePrefix != "" {
+		query = query.Where(squirrel.ILike{"s.service_name": likeEscaper.Replace(*filter.ServiceNamePrefix) + "%"})
+	}
+	if filter.State != nil {
+		query = query.Where(squirrel.Eq{"s.state": *filter.State})
+	}
+	if filter.TrialEndsBefore != nil {
+		query = query.Where(squirrel.Eq{"s.state": models.StateTrial}).
+			Where(squirrel.LtOrEq{"s.trial_end": *filter.TrialEndsBefore})
+	}
+	if filter.Status != nil {
+		switch *filter.Status {
+		case filters.StatusActive:
+			query = query.Where("(s.end_date IS NULL OR s.end_date >= now())")
+		case filters.StatusExpired:
+			query = query.Where("s.end_date < now()")
+		}
 	}
+	if filter.MinPrice != nil {
+		query = query.Where(currentPriceSQL+" >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where(currentPriceSQL+" <= ?", *filter.MaxPrice)
+	}
+	// Подписка пересекается с периодом [from, to]
 	if filter.From != nil && !filter.From.IsZero() {
-		query = query.Where(squirrel.GtOrEq{"start_date": *filter.From})
+		query = query.Where(squirrel.Or{
+			squirrel.Eq{"s.end_date": nil},
+			squirrel.GtOrEq{"s.end_date": *filter.From},
+		})
 	}
 	if filter.To != nil && !filter.To.IsZero() {
-		query = query.Where(squirrel.LtOrEq{"end_date": *filter.To})
+		query = query.Where(squirrel.LtOrEq{"s.start_date": *filter.To})
 	}
+	return query
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// orderBy переводит поля сортировки в ORDER BY; id добавляется для стабильного порядка.
+func orderBy(sort []filters.SortField) []string {
+	if len(sort) == 0 {
+		sort = []filters.SortField{{Field: "created_at", Desc: true}}
+	}
+
+	clauses := make([]string, 0, len(sort)+1)
+	for _, field := range sort {
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+		// Бессрочные подписки считаются самыми поздними
+		nulls := ""
+		if field.Field == "end_date" || field.Field == "trial_end" {
+			nulls = " NULLS LAST"
+			if field.Desc {
+				nulls = " NULLS FIRST"
+			}
+		}
+		column := "s." + field.Field
+		if field.Field == "price" {
+			column = currentPriceSQL
+		}
+		clauses = append(clauses, column+" "+direction+nulls)
+	}
+
+	last := sort[len(sort)-1]
+	if last.Desc {
+		return append(clauses, "s.id DESC")
+	}
+	return append(clauses, "s.id ASC")
+}
+
+// SumSubscriptionsCost --- SUM (Filter) ---
+// Каждая подписка тарифицируется один раз за каждый календарный месяц,
+// пересекающийся с периодом [from, to], по месячному эквиваленту цены,
+// действовавшей в этом месяце (см. subscription_prices).
+// Оплата начинается после пробного периода (trial_end), месяц его окончания оплачивается
+// с этого момента. Время приостановки не оплачивается: из месяца вычитается
+// его доля, пришедшаяся на паузы.
+// Бессрочные подписки ограничиваются to, а если to не задан — текущим моментом.
+// Суммы возвращаются отдельно по каждой валюте подписок.
+func (s *SubRepository) SumSubscriptionsCost(ctx context.Context, filter *filters.SubFilter) ([]*models.CostSummary, error) {
+	groups, err := s.sumCost(ctx, filter, "''")
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*models.CostSummary, 0, len(groups))
+	for _, group := range groups {
+		summaries = append(summaries, &group.CostSummary)
+	}
+	return summaries, nil
+}
+
+// SumSubscriptionsCostGrouped --- SUM (Filter, Group) ---
+// Разбивка SumSubscriptionsCost по группам: суммы групп складываются в общий итог.
+func (s *SubRepository) SumSubscriptionsCostGrouped(ctx context.Context, filter *filters.SubFilter, groupBy filters.CostGroupBy) ([]*models.CostGroup, error) {
+	var keyExpr string
+	switch groupBy {
+	case filters.GroupByServiceName:
+		keyExpr = "s.service_name"
+	case filters.GroupByUserID:
+		keyExpr = "s.user_id::text"
+	case filters.GroupByMonth:
+		keyExpr = "to_char(p.period, 'YYYY-MM')"
+	case filters.GroupByYear:
+		keyExpr = "to_char(p.period, 'YYYY')"
+	default:
+		return nil, fmt.Errorf("unsupported cost grouping %q", groupBy)
+	}
+
+	return s.sumCost(ctx, filter, keyExpr)
+}
+
+// Момент оплаты месяца p.period: его начало или начало оплаты подписки в первом месяце
+const billedAtSQL = "GREATEST(p.period, " + billingStartSQL + ")"
+
+// Конец оплачиваемого месяца p.period
+const monthEndSQL = "(p.period + interval '1 month')"
+
+// Начало оплачиваемой части месяца p.period: окончание пробного периода, если он закончился в этом месяце
+const billableFromSQL = "GREATEST(p.period, COALESCE(s.trial_end, p.period))"
+
+// Сколько секунд оплачиваемой части месяца p.period подписка s провела на паузе
+const pausedSecondsSQL = "COALESCE((SELECT SUM(EXTRACT(EPOCH FROM GREATEST(interval '0', " +
+	"LEAST(" + monthEndSQL + ", COALESCE(ps.resumed_at, " + monthEndSQL + ")) - GREATEST(" + billableFromSQL + ", ps.paused_at)))) " +
+	"FROM " + pausesTableName + " ps WHERE ps.subscription_id = s.id), 0)"
+
+// Оплачиваемая доля месяца p.period: от 0 (весь месяц в пробном периоде или на паузе) до 1
+const billedShareSQL = "((EXTRACT(EPOCH FROM " + monthEndSQL + " - " + billableFromSQL + ") - " + pausedSecondsSQL + ") / " +
+	"EXTRACT(EPOCH FROM " + monthEndSQL + " - p.period))"
+
+// billedPeriods строит выборку «подписка × оплачиваемый месяц» (s × p) по фильтру.
+func billedPeriods(filter *filters.SubFilter) squirrel.SelectBuilder {
+	to := time.Now()
+	if filter.To != nil && !filter.To.IsZero() {
+		to = *filter.To
+	}
+	var from *time.Time
+	if filter.From != nil && !filter.From.IsZero() {
+		from = filter.From
+	}
+
+	query := squirrel.Select().
+		From(tableName+" s").
+		JoinClause("CROSS JOIN LATERAL generate_series("+
+			"date_trunc('month', GREATEST("+billingStartSQL+", ?::timestamptz)), "+
+			"date_trunc('month', LEAST(COALESCE(s.end_date, ?::timestamptz), ?::timestamptz)), "+
+			"interval '1 month') AS p(period)", from, to, to).
+		// Цена месяца — действующая на его начало (или на начало оплаты в первом месяце)
+		JoinClause("LEFT JOIN LATERAL (SELECT sp.price FROM "+pricesTableName+" sp "+
+			"WHERE sp.subscription_id = s.id AND sp.effective_from <= "+billedAtSQL+"::date "+
+			"ORDER BY sp.effective_from DESC LIMIT 1) AS sp ON true").
+		Where(billingStartSQL+" <= ?", to).
+		// Подписка, закончившаяся в пробный период, не оплачивается
+		Where("(s.end_date IS NULL OR s.end_date >= " + billingStartSQL + ")")
+
+	return applySubFilter(query, filter)
+}
+
+// sumCost агрегирует стоимость по ключу keyExpr и валюте.
+func (s *SubRepository) sumCost(ctx context.Context, filter *filters.SubFilter, keyExpr string) ([]*models.CostGroup, error) {
+	billed := billedPeriods(filter).
+		Columns(
+			"s.id",
+			keyExpr+" AS key",
+			"s.currency",
+			monthlyPriceSQL+" AS monthly",
+			billedShareSQL+" AS share")
+
+	// Сначала агрегат по подписке внутри группы, затем по группе;
+	// месяц, целиком проведённый на паузе, не оплачивается
+	perSub := squirrel.Select(
+		"b.key",
+		"b.currency",
+		"SUM(b.monthly * b.share) AS cost",
+		"COUNT(*) AS months",
+		"MAX(b.monthly) AS monthly").
+		FromSelect(billed, "b").
+		Where("b.share > 0").
+		GroupBy("b.id", "b.key")
+
+	query := psql.Select(
+		"t.key",
+		"t.currency",
+		"ROUND(SUM(t.cost))::bigint",
+		"SUM(t.cost)::float8",
+		"SUM(t.months)::bigint",
+		"SUM(t.monthly)::float8",
+		"COUNT(*)").
+		FromSelect(perSub, "t").
+		GroupBy("t.key", "t.currency").
+		OrderBy("t.key", "t.currency")
 
 	sqlStr, args, err := query.ToSql()
 	if err != nil {
-		return 0, fmt.Errorf("build sum query: %w", err)
+		return nil, fmt.Errorf("build sum query: %w", err)
 	}
 
-	var total int64
-	err = s.db.QueryRow(ctx, sqlStr, args...).Scan(&total)
+	rows, err := conn(ctx, s.db).Query(ctx, sqlStr, args...)
 	if err != nil {
-		return 0, fmt.Errorf("execute sum query: %w", err)
+		return nil, mapError("execute sum query", err)
+	}
+	defer rows.Close()
+
+	var groups []*models.CostGroup
+	for rows.Next() {
+		var group models.CostGroup
+		err = rows.Scan(&group.Key, &group.Currency, &group.TotalCost, &group.Amount, &group.BillableMonths,
+			&group.MonthlyRate, &group.SubscriptionCount)
+		if err != nil {
+			return nil, fmt.Errorf("scan sum: %w", err)
+		}
+		groups = append(groups, &group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError("execute sum query", err)
 	}
-	return total, nil
+	return groups, nil
 }
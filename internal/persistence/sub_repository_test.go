@@ -0,0 +1,55 @@
+package persistence_test
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/persistence"
+	"SubscriptionService/internal/persistence/subrepotest"
+	"SubscriptionService/pkg/db"
+	"SubscriptionService/pkg/migrate"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// Проверки выполняются на отдельной базе из TEST_DB_URL: таблицы очищаются перед каждой.
+func TestSubRepositoryConformance(t *testing.T) {
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+	ctx := context.Background()
+	logger := zerolog.Nop()
+
+	// Миграции ищутся относительно корня репозитория
+	t.Chdir("../..")
+	if err := migrate.Up(url, &logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	pool, err := db.NewPGXPool(ctx, url, &logger)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	truncate := func(t *testing.T) {
+		_, err := pool.Exec(ctx, "TRUNCATE subscriptions, charges, subscription_events, event_outbox, webhook_deliveries CASCADE")
+		if err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+	}
+	txManager, err := persistence.NewTxManager(pool, "serializable", 3)
+	if err != nil {
+		t.Fatalf("tx manager: %v", err)
+	}
+
+	subrepotest.Run(t, func(t *testing.T) core_interfaces.ISubRepository {
+		truncate(t)
+		return persistence.NewSubRepository(pool)
+	})
+	subrepotest.RunUnitOfWork(t, func(t *testing.T) (core_interfaces.ISubRepository, core_interfaces.ITxManager) {
+		truncate(t)
+		return persistence.NewSubRepository(pool), txManager
+	})
+}
@@ -0,0 +1,892 @@
+// Package subrepotest — набор проверок, которые должен проходить любой адаптер
+// core_interfaces.ISubRepository. Адаптеры PostgreSQL, SQLite и memory запускают его
+// из своих тестов, поэтому поведение хранилищ не расходится.
+package subrepotest
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewRepo возвращает пустое хранилище для одной проверки.
+type NewRepo func(t *testing.T) core_interfaces.ISubRepository
+
+// Run выполняет все проверки над хранилищами, созданными newRepo.
+func Run(t *testing.T, newRepo NewRepo) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo core_interfaces.ISubRepository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"CreateMany", testCreateMany},
+		{"Update", testUpdate},
+		{"DeleteAndRestore", testDeleteAndRestore},
+		{"Purge", testPurge},
+		{"Pagination", testPagination},
+		{"Sort", testSort},
+		{"Filters", testFilters},
+		{"Cursor", testCursor},
+		{"Prices", testPrices},
+		{"Cost", testCost},
+		{"CostProratesTrialEnd", testCostProratesTrialEnd},
+		{"CostProratesPauses", testCostProratesPauses},
+		{"ChangeState", testChangeState},
+		{"ExpireAndConvert", testExpireAndConvert},
+		{"Charges", testCharges},
+		{"History", testHistory},
+		{"ConcurrentUpdates", testConcurrentUpdates},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+var ctx = context.Background()
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// newSub создаёт месячную подписку в RUB; opts изменяют её перед сохранением.
+func newSub(t *testing.T, repo core_interfaces.ISubRepository, service string, price int64, start time.Time,
+	opts ...func(sub *models.Subscription)) *models.Subscription {
+	t.Helper()
+	sub, err := models.NewSubscription(service, price, "", uuid.New(), "", nil, "", nil, start, nil)
+	if err != nil {
+		t.Fatalf("new subscription: %v", err)
+	}
+	for _, opt := range opts {
+		opt(sub)
+	}
+	created, err := repo.Create(ctx, sub)
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	return created
+}
+
+func withEnd(end time.Time) func(*models.Subscription) {
+	return func(sub *models.Subscription) { sub.EndDate = &end }
+}
+
+func withTrial(trialEnd time.Time) func(*models.Subscription) {
+	return func(sub *models.Subscription) {
+		sub.State = models.StateTrial
+		sub.TrialEnd = &trialEnd
+	}
+}
+
+func withCreatedAt(at time.Time) func(*models.Subscription) {
+	return func(sub *models.Subscription) {
+		sub.CreatedAt = at
+		sub.UpdatedAt = at
+	}
+}
+
+func withUser(userID uuid.UUID) func(*models.Subscription) {
+	return func(sub *models.Subscription) { sub.UserId = userID }
+}
+
+func withInterval(interval models.BillingInterval) func(*models.Subscription) {
+	return func(sub *models.Subscription) { sub.BillingInterval = interval }
+}
+
+func withCurrency(currency string) func(*models.Subscription) {
+	return func(sub *models.Subscription) { sub.Currency = currency }
+}
+
+// newSeries создаёт n подписок, созданных с интервалом в минуту; последняя — самая новая.
+func newSeries(t *testing.T, repo core_interfaces.ISubRepository, n int) []*models.Subscription {
+	t.Helper()
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	subs := make([]*models.Subscription, n)
+	for i := range subs {
+		subs[i] = newSub(t, repo, "service", 100, date(2024, 1, 1), withCreatedAt(base.Add(time.Duration(i)*time.Minute)))
+	}
+	return subs
+}
+
+func ids(subs []*models.Subscription) []uuid.UUID {
+	result := make([]uuid.UUID, len(subs))
+	for i, sub := range subs {
+		result[i] = sub.Id
+	}
+	return result
+}
+
+func expectErr(t *testing.T, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Fatalf("expected %v, got %v", target, err)
+	}
+}
+
+func expectIds(t *testing.T, got []*models.Subscription, want ...*models.Subscription) {
+	t.Helper()
+	if !slices.Equal(ids(got), ids(want)) {
+		t.Fatalf("expected subscriptions %v, got %v", ids(want), ids(got))
+	}
+}
+
+// expectSet сравнивает подписки без учёта порядка.
+func expectSet(t *testing.T, got []*models.Subscription, want ...*models.Subscription) {
+	t.Helper()
+	gotIds, wantIds := ids(got), ids(want)
+	compare := func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) }
+	slices.SortFunc(gotIds, compare)
+	slices.SortFunc(wantIds, compare)
+	if !slices.Equal(gotIds, wantIds) {
+		t.Fatalf("expected subscriptions %v, got %v", wantIds, gotIds)
+	}
+}
+
+func get(t *testing.T, repo core_interfaces.ISubRepository, id uuid.UUID) *models.Subscription {
+	t.Helper()
+	sub, err := repo.GetById(ctx, id)
+	if err != nil {
+		t.Fatalf("get subscription: %v", err)
+	}
+	return sub
+}
+
+func testCreateAndGet(t *testing.T, repo core_interfaces.ISubRepository) {
+	start := date(2024, 1, 15)
+	created := newSub(t, repo, "Netflix", 499, start)
+	if created.Version != 1 || created.Price != 499 || created.State != models.StateActive {
+		t.Fatalf("unexpected created subscription: %+v", created)
+	}
+
+	got := get(t, repo, created.Id)
+	if got.ServiceName != "Netflix" || got.Price != 499 || got.Currency != models.DefaultCurrency ||
+		got.UserId != created.UserId || !got.StartDate.Equal(start) || got.EndDate != nil ||
+		!got.CreatedAt.Equal(created.CreatedAt) || got.Version != 1 || got.DeletedAt != nil {
+		t.Fatalf("unexpected subscription: %+v", got)
+	}
+
+	prices, err := repo.GetPrices(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("get prices: %v", err)
+	}
+	if len(prices) != 1 || prices[0].Price != 499 || !prices[0].EffectiveFrom.Equal(start) {
+		t.Fatalf("expected initial price from start date, got %+v", prices)
+	}
+
+	_, err = repo.Create(ctx, got)
+	expectErr(t, err, models.ErrConflict)
+
+	_, err = repo.GetById(ctx, uuid.New())
+	expectErr(t, err, models.ErrNotFound)
+
+	long, err := models.NewSubscription(strings.Repeat("x", 101), 100, "", uuid.New(), "", nil, "", nil, start, nil)
+	if err != nil {
+		t.Fatalf("new subscription: %v", err)
+	}
+	_, err = repo.Create(ctx, long)
+	expectErr(t, err, models.ErrValidation)
+}
+
+func testCreateMany(t *testing.T, repo core_interfaces.ISubRepository) {
+	subs := make([]*models.Subscription, 3)
+	for i := range subs {
+		sub, err := models.NewSubscription("service", int64(100*(i+1)), "", uuid.New(), "", nil, "", nil, date(2024, 1, 1), nil)
+		if err != nil {
+			t.Fatalf("new subscription: %v", err)
+		}
+		subs[i] = sub
+	}
+
+	created, err := repo.CreateMany(ctx, subs)
+	if err != nil {
+		t.Fatalf("create many: %v", err)
+	}
+	expectIds(t, created, subs...)
+	for i, sub := range created {
+		if sub.Price != int64(100*(i+1)) || sub.Version != 1 {
+			t.Fatalf("unexpected created subscription: %+v", sub)
+		}
+		prices, err := repo.GetPrices(ctx, sub.Id)
+		if err != nil {
+			t.Fatalf("get prices: %v", err)
+		}
+		if len(prices) != 1 || prices[0].Price != sub.Price {
+			t.Fatalf("expected initial price, got %+v", prices)
+		}
+		events, err := repo.GetHistory(ctx, sub.Id, 1, 10)
+		if err != nil {
+			t.Fatalf("get history: %v", err)
+		}
+		if len(events) != 1 || events[0].Operation != models.OperationCreate {
+			t.Fatalf("expected create event, got %+v", events)
+		}
+	}
+
+	// Одна ошибка отменяет всю вставку
+	fresh, err := models.NewSubscription("fresh", 100, "", uuid.New(), "", nil, "", nil, date(2024, 1, 1), nil)
+	if err != nil {
+		t.Fatalf("new subscription: %v", err)
+	}
+	_, err = repo.CreateMany(ctx, []*models.Subscription{fresh, subs[0]})
+	expectErr(t, err, models.ErrConflict)
+	_, err = repo.GetById(ctx, fresh.Id)
+	expectErr(t, err, models.ErrNotFound)
+
+	created, err = repo.CreateMany(ctx, nil)
+	if err != nil || len(created) != 0 {
+		t.Fatalf("expected no subscriptions, got %v, %v", created, err)
+	}
+}
+
+func testUpdate(t *testing.T, repo core_interfaces.ISubRepository) {
+	created := newSub(t, repo, "Netflix", 100, date(2024, 1, 1))
+
+	update := *created
+	update.ServiceName = "Netflix Premium"
+	update.Price = 200
+	updated, err := repo.Update(ctx, &update)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.Version != 2 || updated.Price != 200 || updated.ServiceName != "Netflix Premium" ||
+		!updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("unexpected updated subscription: %+v", updated)
+	}
+
+	// Прошлые месяцы считаются по прежней цене, новая действует с сегодняшнего дня
+	prices, err := repo.GetPrices(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("get prices: %v", err)
+	}
+	if len(prices) != 2 || prices[0].Price != 100 || prices[1].Price != 200 {
+		t.Fatalf("expected price history [100 200], got %+v", prices)
+	}
+
+	stale := *created
+	_, err = repo.Update(ctx, &stale)
+	expectErr(t, err, models.ErrPreconditionFailed)
+
+	missing := *created
+	missing.Id = uuid.New()
+	_, err = repo.Update(ctx, &missing)
+	expectErr(t, err, models.ErrNotFound)
+
+	if err := repo.Delete(ctx, created.Id, nil); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	deleted := *updated
+	deleted.Version = 3
+	_, err = repo.Update(ctx, &deleted)
+	expectErr(t, err, models.ErrNotFound)
+}
+
+func testDeleteAndRestore(t *testing.T, repo core_interfaces.ISubRepository) {
+	created := newSub(t, repo, "Netflix", 100, date(2024, 1, 1))
+
+	wrong := int64(5)
+	expectErr(t, repo.Delete(ctx, created.Id, &wrong), models.ErrPreconditionFailed)
+	expectErr(t, repo.Delete(ctx, uuid.New(), nil), models.ErrNotFound)
+
+	if err := repo.Delete(ctx, created.Id, &created.Version); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	deleted := get(t, repo, created.Id)
+	if deleted.DeletedAt == nil || deleted.Version != 2 {
+		t.Fatalf("expected deleted subscription with version 2, got %+v", deleted)
+	}
+	expectErr(t, repo.Delete(ctx, created.Id, nil), models.ErrNotFound)
+
+	restored, err := repo.Restore(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Version != 3 {
+		t.Fatalf("expected restored subscription with version 3, got %+v", restored)
+	}
+
+	_, err = repo.Restore(ctx, created.Id)
+	expectErr(t, err, models.ErrConflict)
+	_, err = repo.Restore(ctx, uuid.New())
+	expectErr(t, err, models.ErrNotFound)
+}
+
+func testPurge(t *testing.T, repo core_interfaces.ISubRepository) {
+	purged := newSub(t, repo, "Netflix", 100, date(2024, 1, 1))
+	kept := newSub(t, repo, "Spotify", 100, date(2024, 1, 1))
+	if err := repo.Delete(ctx, purged.Id, nil); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	n, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil || n != 0 {
+		t.Fatalf("expected nothing to purge, got %d, %v", n, err)
+	}
+	n, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 purged subscription, got %d, %v", n, err)
+	}
+
+	_, err = repo.GetById(ctx, purged.Id)
+	expectErr(t, err, models.ErrNotFound)
+	get(t, repo, kept.Id)
+
+	// История переживает окончательное удаление
+	total, err := repo.CountHistory(ctx, purged.Id)
+	if err != nil || total != 3 {
+		t.Fatalf("expected create, delete and purge events, got %d, %v", total, err)
+	}
+}
+
+func testPagination(t *testing.T, repo core_interfaces.ISubRepository) {
+	subs := newSeries(t, repo, 5)
+
+	page := func(n, size int64, filter *filters.SubFilter) []*models.Subscription {
+		t.Helper()
+		result, err := repo.GetAll(ctx, filter, n, size)
+		if err != nil {
+			t.Fatalf("get all: %v", err)
+		}
+		return result
+	}
+	count := func(filter *filters.SubFilter) int64 {
+		t.Helper()
+		total, err := repo.Count(ctx, filter)
+		if err != nil {
+			t.Fatalf("count: %v", err)
+		}
+		return total
+	}
+
+	// По умолчанию — от новых к старым
+	expectIds(t, page(1, 2, &filters.SubFilter{}), subs[4], subs[3])
+	expectIds(t, page(2, 2, &filters.SubFilter{}), subs[2], subs[1])
+	expectIds(t, page(3, 2, &filters.SubFilter{}), subs[0])
+	expectIds(t, page(4, 2, &filters.SubFilter{}))
+	if total := count(&filters.SubFilter{}); total != 5 {
+		t.Fatalf("expected 5 subscriptions, got %d", total)
+	}
+
+	if err := repo.Delete(ctx, subs[2].Id, nil); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	expectIds(t, page(1, 10, &filters.SubFilter{}), subs[4], subs[3], subs[1], subs[0])
+	expectIds(t, page(1, 10, &filters.SubFilter{OnlyDeleted: true}), subs[2])
+	if total := count(&filters.SubFilter{IncludeDeleted: true}); total != 5 {
+		t.Fatalf("expected 5 subscriptions including deleted, got %d", total)
+	}
+	if total := count(&filters.SubFilter{OnlyDeleted: true}); total != 1 {
+		t.Fatalf("expected 1 deleted subscription, got %d", total)
+	}
+}
+
+func testSort(t *testing.T, repo core_interfaces.ISubRepository) {
+	start := date(2024, 1, 1)
+	a := newSub(t, repo, "b", 300, start, withEnd(date(2024, 6, 1)))
+	b := newSub(t, repo, "a", 100, start)
+	c := newSub(t, repo, "b", 200, start, withEnd(date(2024, 3, 1)))
+
+	sorted := func(sort string) []*models.Subscription {
+		t.Helper()
+		fields, err := filters.ParseSort(sort)
+		if err != nil {
+			t.Fatalf("parse sort: %v", err)
+		}
+		result, err := repo.GetAll(ctx, &filters.SubFilter{Sort: fields}, 1, 10)
+		if err != nil {
+			t.Fatalf("get all: %v", err)
+		}
+		return result
+	}
+
+	expectIds(t, sorted("price"), b, c, a)
+	expectIds(t, sorted("-price"), a, c, b)
+	// Бессрочные подписки считаются самыми поздними
+	expectIds(t, sorted("end_date"), c, a, b)
+	expectIds(t, sorted("-end_date"), b, a, c)
+
+	// При равных значениях порядок задаёт id в направлении последнего поля
+	first, second := a, c
+	if bytes.Compare(a.Id[:], c.Id[:]) > 0 {
+		first, second = c, a
+	}
+	expectIds(t, sorted("service_name"), b, first, second)
+	expectIds(t, sorted("-service_name"), second, first, b)
+}
+
+func testFilters(t *testing.T, repo core_interfaces.ISubRepository) {
+	user := uuid.New()
+	start := date(2024, 1, 1)
+	a := newSub(t, repo, "Netflix", 100, start, withUser(user))
+	b := newSub(t, repo, "netflix premium", 500, start, withEnd(date(2024, 6, 1)))
+	c := newSub(t, repo, "Spotify", 300, start, withUser(user), withTrial(date(2030, 1, 10)))
+
+	// Будущая цена не влияет на фильтр по цене
+	if _, err := repo.SchedulePrice(ctx, &models.PriceChange{SubscriptionId: c.Id, Price: 50, EffectiveFrom: date(2099, 1, 1)}); err != nil {
+		t.Fatalf("schedule price: %v", err)
+	}
+
+	ptr := func(s string) *string { return &s }
+	price := func(p int64) *int64 { return &p }
+	at := func(t time.Time) *time.Time { return &t }
+	state := models.StateTrial
+	active, expired := filters.StatusActive, filters.StatusExpired
+
+	cases := []struct {
+		name   string
+		filter filters.SubFilter
+		want   []*models.Subscription
+	}{
+		{"user", filters.SubFilter{UserID: &user}, []*models.Subscription{a, c}},
+		{"service name", filters.SubFilter{ServiceName: ptr("Netflix")}, []*models.Subscription{a}},
+		{"prefix ignores case", filters.SubFilter{ServiceNamePrefix: ptr("NETFLIX")}, []*models.Subscription{a, b}},
+		{"prefix is literal", filters.SubFilter{ServiceNamePrefix: ptr("net_")}, nil},
+		{"state", filters.SubFilter{State: &state}, []*models.Subscription{c}},
+		{"trial ends before", filters.SubFilter{TrialEndsBefore: at(date(2030, 2, 1))}, []*models.Subscription{c}},
+		{"trial ends later", filters.SubFilter{TrialEndsBefore: at(date(2029, 1, 1))}, nil},
+		{"active", filters.SubFilter{Status: &active}, []*models.Subscription{a, c}},
+		{"expired", filters.SubFilter{Status: &expired}, []*models.Subscription{b}},
+		{"min price", filters.SubFilter{MinPrice: price(300)}, []*models.Subscription{b, c}},
+		{"max price", filters.SubFilter{MaxPrice: price(300)}, []*models.Subscription{a, c}},
+		{"from", filters.SubFilter{From: at(date(2024, 7, 1))}, []*models.Subscription{a, c}},
+		{"to before start", filters.SubFilter{To: at(date(2023, 12, 31))}, nil},
+		{"to at start", filters.SubFilter{To: at(start)}, []*models.Subscription{a, b, c}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := repo.GetAll(ctx, &tc.filter, 1, 10)
+			if err != nil {
+				t.Fatalf("get all: %v", err)
+			}
+			expectSet(t, got, tc.want...)
+
+			total, err := repo.Count(ctx, &tc.filter)
+			if err != nil || total != int64(len(tc.want)) {
+				t.Fatalf("expected count %d, got %d, %v", len(tc.want), total, err)
+			}
+		})
+	}
+}
+
+func testCursor(t *testing.T, repo core_interfaces.ISubRepository) {
+	subs := newSeries(t, repo, 5)
+
+	fetch := func(cursor *filters.Cursor, wantMore bool, want ...*models.Subscription) []*models.Subscription {
+		t.Helper()
+		got, hasMore, err := repo.GetAllByCursor(ctx, &filters.SubFilter{}, cursor, 2)
+		if err != nil {
+			t.Fatalf("get by cursor: %v", err)
+		}
+		expectIds(t, got, want...)
+		if hasMore != wantMore {
+			t.Fatalf("expected hasMore %v, got %v", wantMore, hasMore)
+		}
+		return got
+	}
+	after := func(sub *models.Subscription, backward bool) *filters.Cursor {
+		return &filters.Cursor{CreatedAt: sub.CreatedAt, Id: sub.Id, Backward: backward}
+	}
+
+	fetch(nil, true, subs[4], subs[3])
+	fetch(after(subs[3], false), true, subs[2], subs[1])
+	fetch(after(subs[1], false), false, subs[0])
+	fetch(after(subs[2], true), false, subs[4], subs[3])
+	fetch(after(subs[0], true), true, subs[2], subs[1])
+}
+
+func testPrices(t *testing.T, repo core_interfaces.ISubRepository) {
+	sub := newSub(t, repo, "Netflix", 100, date(2024, 1, 1))
+	effective := date(2030, 1, 1)
+
+	schedule := func(price int64) *models.PriceChange {
+		t.Helper()
+		change, err := repo.SchedulePrice(ctx, &models.PriceChange{SubscriptionId: sub.Id, Price: price, EffectiveFrom: effective})
+		if err != nil {
+			t.Fatalf("schedule price: %v", err)
+		}
+		return change
+	}
+	if change := schedule(150); change.Price != 150 || !change.EffectiveFrom.Equal(effective) {
+		t.Fatalf("unexpected price change: %+v", change)
+	}
+	// Цена на ту же дату заменяется
+	schedule(200)
+
+	prices, err := repo.GetPrices(ctx, sub.Id)
+	if err != nil {
+		t.Fatalf("get prices: %v", err)
+	}
+	if len(prices) != 2 || prices[0].Price != 100 || prices[1].Price != 200 || !prices[1].EffectiveFrom.Equal(effective) {
+		t.Fatalf("unexpected prices: %+v", prices)
+	}
+	if got := get(t, repo, sub.Id); got.Price != 100 {
+		t.Fatalf("expected current price 100, got %d", got.Price)
+	}
+
+	events, err := repo.GetHistory(ctx, sub.Id, 1, 1)
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if len(events) != 1 || events[0].Operation != models.OperationSchedulePrice || len(events[0].Before) == 0 {
+		t.Fatalf("expected schedule_price event with replaced price, got %+v", events)
+	}
+
+	_, err = repo.SchedulePrice(ctx, &models.PriceChange{SubscriptionId: uuid.New(), Price: 100, EffectiveFrom: effective})
+	expectErr(t, err, models.ErrNotFound)
+
+	missing, err := repo.GetPrices(ctx, uuid.New())
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("expected no prices, got %v, %v", missing, err)
+	}
+}
+
+type costRow struct {
+	key      string
+	currency string
+	total    int64
+	months   int64
+	count    int64
+}
+
+func expectCost(t *testing.T, got []*models.CostGroup, want ...costRow) {
+	t.Helper()
+	rows := make([]costRow, len(got))
+	for i, group := range got {
+		rows[i] = costRow{group.Key, group.Currency, group.TotalCost, group.BillableMonths, group.SubscriptionCount}
+	}
+	if !slices.Equal(rows, want) {
+		t.Fatalf("expected cost %+v, got %+v", want, rows)
+	}
+}
+
+func testCost(t *testing.T, repo core_interfaces.ISubRepository) {
+	// Январь — март 2024: цена 300, с марта — 600
+	s1 := newSub(t, repo, "Netflix", 300, date(2024, 1, 15))
+	if _, err := repo.SchedulePrice(ctx, &models.PriceChange{SubscriptionId: s1.Id, Price: 600, EffectiveFrom: date(2024, 3, 1)}); err != nil {
+		t.Fatalf("schedule price: %v", err)
+	}
+	// 1200 в год — 100 в месяц
+	newSub(t, repo, "Yearly", 1200, date(2024, 1, 1), withInterval(models.BillingYearly))
+	newSub(t, repo, "Spotify", 10, date(2024, 2, 1), withCurrency("USD"))
+	// Оплата с конца пробного периода 10 февраля: 20 из 29 дней февраля (≈68.97) и март
+	newSub(t, repo, "Trial", 100, date(2024, 1, 1), withTrial(date(2024, 2, 10)))
+	// Закончилась в пробный период — не оплачивается
+	newSub(t, repo, "Ended", 100, date(2024, 1, 1), withTrial(date(2024, 1, 20)), withEnd(date(2024, 1, 10)))
+	// 70 в неделю — 304.375 в месяц
+	newSub(t, repo, "Weekly", 70, date(2024, 3, 1), withInterval(models.BillingWeekly))
+
+	to := date(2024, 3, 31)
+	summaries, err := repo.SumSubscriptionsCost(ctx, &filters.SubFilter{To: &to})
+	if err != nil {
+		t.Fatalf("sum cost: %v", err)
+	}
+	if len(summaries) != 2 ||
+		summaries[0].Currency != "RUB" || summaries[0].TotalCost != 1973 || summaries[0].BillableMonths != 9 ||
+		summaries[1].Currency != "USD" || summaries[1].TotalCost != 20 || summaries[1].BillableMonths != 2 {
+		t.Fatalf("unexpected cost summaries: %+v", summaries)
+	}
+	if rate := summaries[0].MonthlyRate; math.Abs(rate-1104.375) > 1e-6 {
+		t.Fatalf("expected monthly rate 1104.375, got %v", rate)
+	}
+
+	byMonth, err := repo.SumSubscriptionsCostGrouped(ctx, &filters.SubFilter{To: &to}, filters.GroupByMonth)
+	if err != nil {
+		t.Fatalf("sum cost by month: %v", err)
+	}
+	expectCost(t, byMonth,
+		costRow{"2024-01", "RUB", 400, 2, 2},
+		costRow{"2024-02", "RUB", 469, 3, 3},
+		costRow{"2024-02", "USD", 10, 1, 1},
+		costRow{"2024-03", "RUB", 1104, 4, 4},
+		costRow{"2024-03", "USD", 10, 1, 1},
+	)
+
+	from := date(2024, 3, 1)
+	byService, err := repo.SumSubscriptionsCostGrouped(ctx, &filters.SubFilter{From: &from, To: &to}, filters.GroupByServiceName)
+	if err != nil {
+		t.Fatalf("sum cost by service: %v", err)
+	}
+	expectCost(t, byService,
+		costRow{"Netflix", "RUB", 600, 1, 1},
+		costRow{"Spotify", "USD", 10, 1, 1},
+		costRow{"Trial", "RUB", 100, 1, 1},
+		costRow{"Weekly", "RUB", 304, 1, 1},
+		costRow{"Yearly", "RUB", 100, 1, 1},
+	)
+
+	if _, err := repo.SumSubscriptionsCostGrouped(ctx, &filters.SubFilter{}, "unknown"); err == nil {
+		t.Fatal("expected error for unknown grouping")
+	}
+}
+
+func testCostProratesTrialEnd(t *testing.T, repo core_interfaces.ISubRepository) {
+	// Пробный период до 28 февраля 2024: оплачиваются 2 из 29 дней февраля
+	newSub(t, repo, "Netflix", 290, date(2024, 1, 1), withTrial(date(2024, 2, 28)))
+	// Пробный период до 1 марта: февраль не оплачивается, март — целиком
+	newSub(t, repo, "Spotify", 100, date(2024, 2, 1), withTrial(date(2024, 3, 1)))
+
+	to := date(2024, 3, 31)
+	byMonth, err := repo.SumSubscriptionsCostGrouped(ctx, &filters.SubFilter{To: &to}, filters.GroupByMonth)
+	if err != nil {
+		t.Fatalf("sum cost by month: %v", err)
+	}
+	expectCost(t, byMonth,
+		costRow{"2024-02", "RUB", 20, 1, 1},
+		costRow{"2024-03", "RUB", 390, 2, 2},
+	)
+}
+
+func testCostProratesPauses(t *testing.T, repo core_interfaces.ISubRepository) {
+	pause := func(sub *models.Subscription, from, to time.Time) {
+		t.Helper()
+		if _, err := repo.ChangeState(ctx, sub.Id, models.OperationPause, nil, from); err != nil {
+			t.Fatalf("pause: %v", err)
+		}
+		if _, err := repo.ChangeState(ctx, sub.Id, models.OperationResume, nil, to); err != nil {
+			t.Fatalf("resume: %v", err)
+		}
+	}
+
+	// Пауза с 20 января по 15 февраля: оплачиваются 19 из 31 дня января и 15 из 29 дней февраля
+	crossMonth := newSub(t, repo, "Netflix", 310, date(2024, 1, 15))
+	pause(crossMonth, date(2024, 1, 20), date(2024, 2, 15))
+	// Пауза со 2 по 27 апреля: оплачиваются 5 из 30 дней
+	midMonth := newSub(t, repo, "Spotify", 300, date(2024, 4, 1))
+	pause(midMonth, date(2024, 4, 2), date(2024, 4, 27))
+	// Весь май на паузе — месяц не оплачивается
+	wholeMonth := newSub(t, repo, "Yandex", 100, date(2024, 4, 1))
+	pause(wholeMonth, date(2024, 5, 1), date(2024, 6, 1))
+
+	to := date(2024, 6, 30)
+	byService, err := repo.SumSubscriptionsCostGrouped(ctx, &filters.SubFilter{To: &to}, filters.GroupByServiceName)
+	if err != nil {
+		t.Fatalf("sum cost by service: %v", err)
+	}
+	expectCost(t, byService,
+		// 190 + 310 * 15/29 + 4 * 310
+		costRow{"Netflix", "RUB", 1590, 6, 1},
+		// 50 + 2 * 300
+		costRow{"Spotify", "RUB", 650, 3, 1},
+		// апрель и июнь
+		costRow{"Yandex", "RUB", 200, 2, 1},
+	)
+}
+
+func testChangeState(t *testing.T, repo core_interfaces.ISubRepository) {
+	sub := newSub(t, repo, "Netflix", 100, date(2024, 1, 1))
+	now := time.Now()
+
+	paused, err := repo.ChangeState(ctx, sub.Id, models.OperationPause, &sub.Version, now)
+	if err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if paused.State != models.StatePaused || paused.Version != 2 {
+		t.Fatalf("unexpected paused subscription: %+v", paused)
+	}
+
+	_, err = repo.ChangeState(ctx, sub.Id, models.OperationPause, nil, now)
+	expectErr(t, err, models.ErrInvalidTransition)
+	_, err = repo.ChangeState(ctx, sub.Id, models.OperationResume, &sub.Version, now)
+	expectErr(t, err, models.ErrPreconditionFailed)
+	_, err = repo.ChangeState(ctx, uuid.New(), models.OperationResume, nil, now)
+	expectErr(t, err, models.ErrNotFound)
+
+	if _, err := repo.ChangeState(ctx, sub.Id, models.OperationResume, &paused.Version, now); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	cancelled, err := repo.ChangeState(ctx, sub.Id, models.OperationCancel, nil, now)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if cancelled.State != models.StateCancelled || cancelled.EndDate == nil || cancelled.Version != 4 {
+		t.Fatalf("unexpected cancelled subscription: %+v", cancelled)
+	}
+}
+
+func testExpireAndConvert(t *testing.T, repo core_interfaces.ISubRepository) {
+	ended := newSub(t, repo, "Ended", 100, date(2024, 1, 1), withEnd(date(2024, 6, 1)))
+	trial := newSub(t, repo, "Trial", 100, date(2024, 1, 1), withTrial(date(2024, 2, 1)))
+	newSub(t, repo, "Open", 100, date(2024, 1, 1))
+	deleted := newSub(t, repo, "Deleted", 100, date(2024, 1, 1), withEnd(date(2024, 6, 1)))
+	if err := repo.Delete(ctx, deleted.Id, nil); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	now := time.Now()
+	expired, err := repo.ExpireDue(ctx, now)
+	if err != nil {
+		t.Fatalf("expire: %v", err)
+	}
+	expectIds(t, expired, ended)
+	if expired[0].State != models.StateExpired || expired[0].Version != 2 {
+		t.Fatalf("unexpected expired subscription: %+v", expired[0])
+	}
+	if again, err := repo.ExpireDue(ctx, now); err != nil || len(again) != 0 {
+		t.Fatalf("expected nothing to expire, got %v, %v", again, err)
+	}
+
+	converted, err := repo.ConvertDueTrials(ctx, now)
+	if err != nil || len(converted) != 1 {
+		t.Fatalf("expected 1 converted trial, got %d, %v", len(converted), err)
+	}
+	if before, after := converted[0].Before, converted[0].After; before.Id != trial.Id ||
+		before.State != models.StateTrial || after.State != models.StateActive || after.Version != before.Version+1 {
+		t.Fatalf("unexpected trial conversion: %+v -> %+v", before, after)
+	}
+	if got := get(t, repo, trial.Id); got.State != models.StateActive {
+		t.Fatalf("expected active subscription, got %s", got.State)
+	}
+}
+
+func testCharges(t *testing.T, repo core_interfaces.ISubRepository) {
+	first := newSub(t, repo, "Netflix", 100, date(2024, 1, 1))
+	if _, err := repo.SchedulePrice(ctx, &models.PriceChange{SubscriptionId: first.Id, Price: 150, EffectiveFrom: date(2024, 2, 1)}); err != nil {
+		t.Fatalf("schedule price: %v", err)
+	}
+	second := newSub(t, repo, "Spotify", 100, date(2024, 2, 1))
+	newSub(t, repo, "Trial", 100, date(2024, 1, 1), withTrial(date(2030, 1, 1)))
+
+	now := date(2024, 3, 15)
+	renew := func(limit int, wantProcessed int, wantCreated int64) {
+		t.Helper()
+		processed, created, err := repo.CreateDueCharges(ctx, now, limit)
+		if err != nil {
+			t.Fatalf("create charges: %v", err)
+		}
+		if processed != wantProcessed || created != wantCreated {
+			t.Fatalf("expected %d processed and %d created, got %d and %d", wantProcessed, wantCreated, processed, created)
+		}
+	}
+	// Первой обрабатывается подписка с самым ранним неоплаченным периодом
+	renew(1, 1, 3)
+	renew(10, 1, 2)
+	renew(10, 0, 0)
+
+	charges, err := repo.GetCharges(ctx, first.Id, 1, 2)
+	if err != nil {
+		t.Fatalf("get charges: %v", err)
+	}
+	if len(charges) != 2 ||
+		!charges[0].PeriodStart.Equal(date(2024, 3, 1)) || !charges[0].PeriodEnd.Equal(date(2024, 4, 1)) ||
+		charges[0].Amount != 150 || charges[0].Currency != "RUB" ||
+		!charges[1].PeriodStart.Equal(date(2024, 2, 1)) {
+		t.Fatalf("unexpected charges: %+v", charges)
+	}
+	last, err := repo.GetCharges(ctx, first.Id, 2, 2)
+	if err != nil || len(last) != 1 || last[0].Amount != 100 {
+		t.Fatalf("expected January charge at initial price, got %+v, %v", last, err)
+	}
+
+	for id, want := range map[uuid.UUID]int64{first.Id: 3, second.Id: 2, uuid.New(): 0} {
+		total, err := repo.CountCharges(ctx, id)
+		if err != nil || total != want {
+			t.Fatalf("expected %d charges, got %d, %v", want, total, err)
+		}
+	}
+}
+
+func testHistory(t *testing.T, repo core_interfaces.ISubRepository) {
+	auditCtx := models.WithAuditInfo(ctx, models.AuditInfo{Actor: "tester", RequestId: "req-1"})
+	sub, err := models.NewSubscription("Netflix", 100, "", uuid.New(), "", nil, "", nil, date(2024, 1, 1), nil)
+	if err != nil {
+		t.Fatalf("new subscription: %v", err)
+	}
+	created, err := repo.Create(auditCtx, sub)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	update := *created
+	update.ServiceName = "Netflix Premium"
+	if _, err := repo.Update(auditCtx, &update); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.Delete(auditCtx, created.Id, nil); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	total, err := repo.CountHistory(ctx, created.Id)
+	if err != nil || total != 3 {
+		t.Fatalf("expected 3 events, got %d, %v", total, err)
+	}
+
+	events, err := repo.GetHistory(ctx, created.Id, 1, 2)
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if len(events) != 2 || events[0].Operation != models.OperationDelete || events[1].Operation != models.OperationUpdate {
+		t.Fatalf("expected delete and update events, got %+v", events)
+	}
+	if events[0].Actor != "tester" || events[0].RequestId != "req-1" {
+		t.Fatalf("unexpected audit info: %+v", events[0])
+	}
+
+	var before, after models.Subscription
+	if err := json.Unmarshal(events[1].Before, &before); err != nil {
+		t.Fatalf("unmarshal before: %v", err)
+	}
+	if err := json.Unmarshal(events[1].After, &after); err != nil {
+		t.Fatalf("unmarshal after: %v", err)
+	}
+	if before.ServiceName != "Netflix" || after.ServiceName != "Netflix Premium" || after.Version != 2 {
+		t.Fatalf("unexpected update snapshots: %+v -> %+v", before, after)
+	}
+
+	oldest, err := repo.GetHistory(ctx, created.Id, 2, 2)
+	if err != nil || len(oldest) != 1 || oldest[0].Operation != models.OperationCreate || len(oldest[0].Before) != 0 {
+		t.Fatalf("expected create event without before snapshot, got %+v, %v", oldest, err)
+	}
+
+	none, err := repo.GetHistory(ctx, uuid.New(), 1, 10)
+	if err != nil || len(none) != 0 {
+		t.Fatalf("expected empty history, got %v, %v", none, err)
+	}
+}
+
+func testConcurrentUpdates(t *testing.T, repo core_interfaces.ISubRepository) {
+	sub := newSub(t, repo, "Netflix", 100, date(2024, 1, 1))
+
+	const writers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			update := *sub
+			update.Price = int64(200 + i)
+			_, err := repo.Update(ctx, &update)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Из одновременных изменений одной версии проходит только одно
+	var succeeded int
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, models.ErrPreconditionFailed):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one successful update, got %d", succeeded)
+	}
+	if got := get(t, repo, sub.Id); got.Version != 2 {
+		t.Fatalf("expected version 2, got %d", got.Version)
+	}
+}
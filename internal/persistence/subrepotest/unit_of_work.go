@@ -0,0 +1,166 @@
+package subrepotest
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewStore возвращает пустое хранилище и единицу работы над ним для одной проверки.
+type NewStore func(t *testing.T) (core_interfaces.ISubRepository, core_interfaces.ITxManager)
+
+// RunUnitOfWork проверяет, что методы хранилища присоединяются к транзакции
+// core_interfaces.ITxManager.
+func RunUnitOfWork(t *testing.T, newStore NewStore) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo core_interfaces.ISubRepository, tx core_interfaces.ITxManager)
+	}{
+		{"Commit", testTxCommit},
+		{"Rollback", testTxRollback},
+		{"NestedRollback", testTxNestedRollback},
+		{"ReadOwnWrites", testTxReadOwnWrites},
+	}
+	for _, tt := range tests {
+		t.Run("UnitOfWork/"+tt.name, func(t *testing.T) {
+			repo, tx := newStore(t)
+			tt.run(t, repo, tx)
+		})
+	}
+}
+
+var errAbort = errors.New("abort unit of work")
+
+// createIn сохраняет новую месячную подписку в контексте txCtx.
+func createIn(t *testing.T, txCtx context.Context, repo core_interfaces.ISubRepository, service string) *models.Subscription {
+	t.Helper()
+	sub, err := models.NewSubscription(service, 100, "", uuid.New(), "", nil, "", nil, date(2025, time.January, 1), nil)
+	if err != nil {
+		t.Fatalf("new subscription: %v", err)
+	}
+	created, err := repo.Create(txCtx, sub)
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	return created
+}
+
+func expectMissing(t *testing.T, repo core_interfaces.ISubRepository, sub *models.Subscription) {
+	t.Helper()
+	if _, err := repo.GetById(ctx, sub.Id); !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("expected %s to be rolled back, got %v", sub.ServiceName, err)
+	}
+	if total, err := repo.CountHistory(ctx, sub.Id); err != nil || total != 0 {
+		t.Fatalf("expected no history for %s, got %d (%v)", sub.ServiceName, total, err)
+	}
+}
+
+func testTxCommit(t *testing.T, repo core_interfaces.ISubRepository, tx core_interfaces.ITxManager) {
+	var first, second *models.Subscription
+	err := tx.WithinTx(ctx, func(txCtx context.Context) error {
+		first = createIn(t, txCtx, repo, "first")
+		second = createIn(t, txCtx, repo, "second")
+		second.Price = 200
+		updated, err := repo.Update(txCtx, second)
+		second = updated
+		return err
+	})
+	if err != nil {
+		t.Fatalf("within tx: %v", err)
+	}
+
+	subs, err := repo.GetAll(ctx, &filters.SubFilter{}, 1, 10)
+	if err != nil {
+		t.Fatalf("get all: %v", err)
+	}
+	expectSet(t, subs, first, second)
+	got, err := repo.GetById(ctx, second.Id)
+	if err != nil || got.Price != 200 || got.Version != 2 {
+		t.Fatalf("expected committed update, got %+v (%v)", got, err)
+	}
+}
+
+func testTxRollback(t *testing.T, repo core_interfaces.ISubRepository, tx core_interfaces.ITxManager) {
+	kept := newSub(t, repo, "kept", 100, date(2025, time.January, 1))
+
+	var created *models.Subscription
+	err := tx.WithinTx(ctx, func(txCtx context.Context) error {
+		created = createIn(t, txCtx, repo, "created")
+		if err := repo.Delete(txCtx, kept.Id, nil); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+
+	expectMissing(t, repo, created)
+	got, err := repo.GetById(ctx, kept.Id)
+	if err != nil || got.IsDeleted() || got.Version != kept.Version {
+		t.Fatalf("expected delete to be rolled back, got %+v (%v)", got, err)
+	}
+}
+
+func testTxNestedRollback(t *testing.T, repo core_interfaces.ISubRepository, tx core_interfaces.ITxManager) {
+	var outer, inner, after *models.Subscription
+	err := tx.WithinTx(ctx, func(txCtx context.Context) error {
+		outer = createIn(t, txCtx, repo, "outer")
+		err := tx.WithinTx(txCtx, func(nestedCtx context.Context) error {
+			inner = createIn(t, nestedCtx, repo, "inner")
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("expected nested fn error, got %v", err)
+		}
+		// Ошибка метода хранилища откатывает только его изменения, транзакция продолжается
+		if _, err := repo.Restore(txCtx, outer.Id); !errors.Is(err, models.ErrConflict) {
+			t.Fatalf("expected conflict, got %v", err)
+		}
+		after = createIn(t, txCtx, repo, "after")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("within tx: %v", err)
+	}
+
+	expectMissing(t, repo, inner)
+	subs, err := repo.GetAll(ctx, &filters.SubFilter{}, 1, 10)
+	if err != nil {
+		t.Fatalf("get all: %v", err)
+	}
+	expectSet(t, subs, outer, after)
+}
+
+func testTxReadOwnWrites(t *testing.T, repo core_interfaces.ISubRepository, tx core_interfaces.ITxManager) {
+	err := tx.WithinTx(ctx, func(txCtx context.Context) error {
+		created := createIn(t, txCtx, repo, "own")
+		got, err := repo.GetById(txCtx, created.Id)
+		if err != nil {
+			return err
+		}
+		if got.ServiceName != "own" {
+			t.Fatalf("expected own write, got %+v", got)
+		}
+		total, err := repo.Count(txCtx, &filters.SubFilter{})
+		if err != nil {
+			return err
+		}
+		if total != 1 {
+			t.Fatalf("expected 1 subscription in transaction, got %d", total)
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+	if total, err := repo.Count(ctx, &filters.SubFilter{}); err != nil || total != 0 {
+		t.Fatalf("expected rollback, got %d subscriptions (%v)", total, err)
+	}
+}
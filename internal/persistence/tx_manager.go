@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TxManager открывает транзакции pgx и передаёт их репозиториям через context.Context.
+type TxManager struct {
+	db       *pgxpool.Pool
+	isoLevel pgx.TxIsoLevel
+	// maxAttempts — сколько раз выполняется транзакция при конфликтах сериализации
+	maxAttempts int
+}
+
+var _ core_interfaces.ITxManager = (*TxManager)(nil)
+
+// NewTxManager создаёт менеджер транзакций с уровнем изоляции isolation
+// (read_committed, repeatable_read или serializable).
+func NewTxManager(db *pgxpool.Pool, isolation string, maxAttempts int) (*TxManager, error) {
+	var isoLevel pgx.TxIsoLevel
+	switch isolation {
+	case "read_committed":
+		isoLevel = pgx.ReadCommitted
+	case "repeatable_read":
+		isoLevel = pgx.RepeatableRead
+	case "serializable":
+		isoLevel = pgx.Serializable
+	default:
+		return nil, fmt.Errorf("unknown transaction isolation %q", isolation)
+	}
+	return &TxManager{db: db, isoLevel: isoLevel, maxAttempts: max(maxAttempts, 1)}, nil
+}
+
+type txKey struct{}
+
+// dbtx — операции, общие для пула и транзакции pgx.
+type dbtx interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// conn возвращает транзакцию единицы работы из ctx, а без неё — пул.
+// Транзакции репозиториев (pgx.BeginFunc) внутри единицы работы становятся точками сохранения.
+func conn(ctx context.Context, db *pgxpool.Pool) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// WithinTx --- UNIT OF WORK ---
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Вложенная единица работы — точка сохранения внешней транзакции
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		err := pgx.BeginFunc(ctx, outer, func(tx pgx.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+		return mapError("savepoint", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := pgx.BeginTxFunc(ctx, m.db, pgx.TxOptions{IsoLevel: m.isoLevel}, func(tx pgx.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+		if err == nil || !isSerializationFailure(err) || attempt >= m.maxAttempts {
+			return mapError("transaction", err)
+		}
+
+		// Небольшая растущая пауза, чтобы конкурирующие транзакции успели завершиться
+		select {
+		case <-ctx.Done():
+			return mapError("transaction", err)
+		case <-time.After(time.Duration(attempt) * 10 * time.Millisecond):
+		}
+	}
+}
+
+// isSerializationFailure сообщает, что транзакцию можно повторить целиком:
+// serialization_failure или deadlock_detected.
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
@@ -0,0 +1,115 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/pkg/db"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// retryTable — таблица проверки повторов, создаётся и удаляется тестом
+const retryTable = "tx_retry_test"
+
+func newRetryPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+	ctx := context.Background()
+	logger := zerolog.Nop()
+
+	pool, err := db.NewPGXPool(ctx, url, &logger)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if _, err := pool.Exec(ctx, "DROP TABLE IF EXISTS "+retryTable); err != nil {
+		t.Fatalf("drop table: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "CREATE TABLE "+retryTable+" (id INT PRIMARY KEY, value INT NOT NULL)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { _, _ = pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+retryTable) })
+	if _, err := pool.Exec(ctx, "INSERT INTO "+retryTable+" VALUES (1, 0)"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	return pool
+}
+
+// conflictingWrite читает строку в транзакции единицы работы, даёт конкурирующей
+// транзакции изменить её, а затем изменяет строку сам. Пока interfere возвращает true,
+// вторая запись завершается ошибкой сериализации (40001).
+func conflictingWrite(pool *pgxpool.Pool, attempts *int, interfere func(attempt int) bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		*attempts++
+		tx := conn(ctx, pool)
+
+		var value int
+		if err := tx.QueryRow(ctx, "SELECT value FROM "+retryTable+" WHERE id = 1").Scan(&value); err != nil {
+			return err
+		}
+		if interfere(*attempts) {
+			if _, err := pool.Exec(ctx, "UPDATE "+retryTable+" SET value = value + 1 WHERE id = 1"); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, "UPDATE "+retryTable+" SET value = $1 WHERE id = 1", value+10)
+		return err
+	}
+}
+
+func TestTxManagerRetriesSerializationFailure(t *testing.T) {
+	pool := newRetryPool(t)
+	ctx := context.Background()
+	txManager, err := NewTxManager(pool, "serializable", 3)
+	if err != nil {
+		t.Fatalf("tx manager: %v", err)
+	}
+
+	attempts := 0
+	err = txManager.WithinTx(ctx, conflictingWrite(pool, &attempts, func(attempt int) bool {
+		return attempt == 1
+	}))
+	if err != nil {
+		t.Fatalf("within tx: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+
+	// Повтор прочитал значение, записанное конкурирующей транзакцией
+	var value int
+	if err := pool.QueryRow(ctx, "SELECT value FROM "+retryTable+" WHERE id = 1").Scan(&value); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if value != 11 {
+		t.Errorf("value = %d, want 11", value)
+	}
+}
+
+func TestTxManagerGivesUpAfterMaxAttempts(t *testing.T) {
+	pool := newRetryPool(t)
+	ctx := context.Background()
+	txManager, err := NewTxManager(pool, "serializable", 3)
+	if err != nil {
+		t.Fatalf("tx manager: %v", err)
+	}
+
+	attempts := 0
+	err = txManager.WithinTx(ctx, conflictingWrite(pool, &attempts, func(int) bool {
+		return true
+	}))
+	if !errors.Is(err, models.ErrConflict) {
+		t.Fatalf("within tx: got %v, want ErrConflict", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
@@ -0,0 +1,415 @@
+package persistence
+
+import (
+	"SubscriptionService/internal/core/core_interfaces"
+	"SubscriptionService/internal/core/models"
+	"SubscriptionService/internal/core/ports/filters"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WebhookRepository — получатели вебхуков и очередь их доставок.
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+var _ core_interfaces.IWebhookRepository = (*WebhookRepository)(nil)
+
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const (
+	endpointsTableName  = "webhook_endpoints"
+	deliveriesTableName = "webhook_deliveries"
+)
+
+// Секрет не входит в колонки чтения: он возвращается только при создании получателя
+var endpointColumns = []string{"id", "url", "events", "active", "created_at", "updated_at"}
+
+var deliveryColumns = []string{
+	"d.id", "d.endpoint_id", "d.event_id", "d.event_type", "d.subscription_id", "d.payload", "d.status",
+	"d.attempts", "d.next_attempt_at", "d.last_status_code", "d.last_error", "d.created_at", "d.delivered_at",
+}
+
+// enqueueDeliveries ставит событие журнала аудита в очередь доставки всем активным
+// получателям, подписанным на его операцию. Выполняется в транзакции изменения подписки,
+// поэтому событие доставляется тогда и только тогда, когда изменение сохранено.
+// payload — событие в JSON.
+func enqueueDeliveries(ctx context.Context, tx pgx.Tx, event *models.SubscriptionEvent, payload []byte) error {
+	sqlStr, args, err := enqueueDeliveriesQuery(event, payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, sqlStr, args...)
+	return err
+}
+
+// enqueueDeliveriesQuery строит запрос enqueueDeliveries.
+func enqueueDeliveriesQuery(event *models.SubscriptionEvent, payload []byte) (string, []any, error) {
+	sqlStr, args, err := psql.Insert(deliveriesTableName).
+		Columns("endpoint_id", "event_id", "event_type", "subscription_id", "payload").
+		Select(squirrel.Select("e.id").
+			Column("?::bigint", event.Id).
+			Column("?::text", event.Operation).
+			Column("?::uuid", event.SubscriptionId).
+			Column("?::jsonb", string(payload)).
+			From(endpointsTableName+" e").
+			Where("e.active").
+			Where(squirrel.Eq{"e.deleted_at": nil}).
+			Where("(cardinality(e.events) = 0 OR ?::text = ANY(e.events))", event.Operation)).
+		ToSql()
+	if err != nil {
+		return "", nil, fmt.Errorf("build enqueue deliveries query: %w", err)
+	}
+	return sqlStr, args, nil
+}
+
+func scanEndpoint(row pgx.Row) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	var events []string
+	if err := row.Scan(&endpoint.Id, &endpoint.URL, &events, &endpoint.Active,
+		&endpoint.CreatedAt, &endpoint.UpdatedAt); err != nil {
+		return nil, err
+	}
+	endpoint.Events = models.ToOperations(events)
+	return &endpoint, nil
+}
+
+func scanDelivery(row pgx.Row, extra ...any) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	dest := []any{
+		&delivery.Id, &delivery.EndpointId, &delivery.EventId, &delivery.EventType, &delivery.SubscriptionId,
+		&delivery.Payload, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt,
+		&delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func fromOperations(ops []models.Operation) []string {
+	events := make([]string, len(ops))
+	for i, op := range ops {
+		events[i] = string(op)
+	}
+	return events
+}
+
+// CreateEndpoint --- CREATE ENDPOINT ---
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	sqlStr, args, err := psql.Insert(endpointsTableName).
+		Columns("id", "url", "secret", "events", "active", "created_at", "updated_at").
+		Values(endpoint.Id, endpoint.URL, endpoint.Secret, fromOperations(endpoint.Events), endpoint.Active,
+			endpoint.CreatedAt, endpoint.UpdatedAt).
+		Suffix("RETURNING " + strings.Join(endpointColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build create endpoint query: %w", err)
+	}
+
+	created, err := scanEndpoint(conn(ctx, r.db).QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, mapError("create endpoint", err)
+	}
+	created.Secret = endpoint.Secret
+	return created, nil
+}
+
+// UpdateEndpoint --- UPDATE ENDPOINT ---
+// Пустой секрет оставляет прежний.
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	query := psql.Update(endpointsTableName).
+		Set("url", endpoint.URL).
+		Set("events", fromOperations(endpoint.Events)).
+		Set("active", endpoint.Active).
+		Set("updated_at", endpoint.UpdatedAt).
+		Where(squirrel.Eq{"id": endpoint.Id, "deleted_at": nil}).
+		Suffix("RETURNING " + strings.Join(endpointColumns, ", "))
+	if endpoint.Secret != "" {
+		query = query.Set("secret", endpoint.Secret)
+	}
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build update endpoint query: %w", err)
+	}
+
+	updated, err := scanEndpoint(conn(ctx, r.db).QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, mapError("update endpoint", err)
+	}
+	return updated, nil
+}
+
+// DeleteEndpoint --- DELETE ENDPOINT ---
+// Получатель удаляется мягко: его доставки остаются в списке, а ожидающие
+// доставки становятся недоставленными.
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	sqlStr, args, err := psql.Update(endpointsTableName).
+		Set("deleted_at", squirrel.Expr("now()")).
+		Set("active", false).
+		Where(squirrel.Eq{"id": id, "deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build delete endpoint query: %w", err)
+	}
+	deadSQL, deadArgs, err := psql.Update(deliveriesTableName).
+		Set("status", models.DeliveryDead).
+		Set("last_error", "endpoint deleted").
+		Set("locked_until", nil).
+		Where(squirrel.Eq{"endpoint_id": id, "status": models.DeliveryPending}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build delete endpoint query: %w", err)
+	}
+
+	err = pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, sqlStr, args...)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("delete endpoint: %w", models.ErrNotFound)
+		}
+		_, err = tx.Exec(ctx, deadSQL, deadArgs...)
+		return err
+	})
+	if err != nil {
+		return mapError("delete endpoint", err)
+	}
+	return nil
+}
+
+// GetEndpoint --- GET ENDPOINT ---
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (*models.WebhookEndpoint, error) {
+	sqlStr, args, err := psql.Select(endpointColumns...).
+		From(endpointsTableName).
+		Where(squirrel.Eq{"id": id, "deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get endpoint query: %w", err)
+	}
+
+	endpoint, err := scanEndpoint(conn(ctx, r.db).QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, mapError("get endpoint", err)
+	}
+	return endpoint, nil
+}
+
+// GetEndpoints --- GET ENDPOINTS ---
+func (r *WebhookRepository) GetEndpoints(ctx context.Context) ([]*models.WebhookEndpoint, error) {
+	sqlStr, args, err := psql.Select(endpointColumns...).
+		From(endpointsTableName).
+		Where(squirrel.Eq{"deleted_at": nil}).
+		OrderBy("created_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build endpoints query: %w", err)
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("endpoints query", err)
+	}
+	endpoints, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.WebhookEndpoint, error) {
+		return scanEndpoint(row)
+	})
+	if err != nil {
+		return nil, mapError("endpoints query", err)
+	}
+	return endpoints, nil
+}
+
+func applyDeliveryFilter(query squirrel.SelectBuilder, filter *filters.DeliveryFilter) squirrel.SelectBuilder {
+	if filter.EndpointID != nil {
+		query = query.Where(squirrel.Eq{"d.endpoint_id": *filter.EndpointID})
+	}
+	if filter.Status != nil {
+		query = query.Where(squirrel.Eq{"d.status": *filter.Status})
+	}
+	return query
+}
+
+// GetDeliveries --- DELIVERIES ---
+// Возвращает доставки от новых к старым.
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, filter *filters.DeliveryFilter, page, pageSize int64) ([]*models.WebhookDelivery, error) {
+	sqlStr, args, err := applyDeliveryFilter(psql.Select(deliveryColumns...).From(deliveriesTableName+" d"), filter).
+		OrderBy("d.id DESC").
+		Limit(uint64(pageSize)).
+		Offset(uint64((page - 1) * pageSize)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build deliveries query: %w", err)
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("deliveries query", err)
+	}
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.WebhookDelivery, error) {
+		return scanDelivery(row)
+	})
+	if err != nil {
+		return nil, mapError("deliveries query", err)
+	}
+	return deliveries, nil
+}
+
+// CountDeliveries --- DELIVERIES COUNT ---
+func (r *WebhookRepository) CountDeliveries(ctx context.Context, filter *filters.DeliveryFilter) (int64, error) {
+	sqlStr, args, err := applyDeliveryFilter(psql.Select("COUNT(*)").From(deliveriesTableName+" d"), filter).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build deliveries count query: %w", err)
+	}
+
+	var total int64
+	if err := conn(ctx, r.db).QueryRow(ctx, sqlStr, args...).Scan(&total); err != nil {
+		return 0, mapError("deliveries count query", err)
+	}
+	return total, nil
+}
+
+// Redeliver --- REDELIVER ---
+// Повторно доставить можно только недоставленную (dead) доставку — models.ErrDeliveryNotDead —
+// и только существующему получателю — models.ErrEndpointDeleted.
+func (r *WebhookRepository) Redeliver(ctx context.Context, id int64, at time.Time) (*models.WebhookDelivery, error) {
+	lockSQL, lockArgs, err := psql.Select("d.status", "e.deleted_at IS NOT NULL").
+		From(deliveriesTableName + " d").
+		Join(endpointsTableName + " e ON e.id = d.endpoint_id").
+		Where(squirrel.Eq{"d.id": id}).
+		Suffix("FOR UPDATE OF d").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build lock delivery query: %w", err)
+	}
+	sqlStr, args, err := psql.Update(deliveriesTableName+" AS d").
+		Set("status", models.DeliveryPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", at).
+		Set("locked_until", nil).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING " + strings.Join(deliveryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build redeliver query: %w", err)
+	}
+
+	var result *models.WebhookDelivery
+	err = pgx.BeginFunc(ctx, conn(ctx, r.db), func(tx pgx.Tx) error {
+		var status models.DeliveryStatus
+		var endpointDeleted bool
+		if err := tx.QueryRow(ctx, lockSQL, lockArgs...).Scan(&status, &endpointDeleted); err != nil {
+			return err
+		}
+		if status != models.DeliveryDead {
+			return models.ErrDeliveryNotDead
+		}
+		if endpointDeleted {
+			return models.ErrEndpointDeleted
+		}
+		result, err = scanDelivery(tx.QueryRow(ctx, sqlStr, args...))
+		return err
+	})
+	if err != nil {
+		return nil, mapError("redeliver", err)
+	}
+	return result, nil
+}
+
+// Claim --- CLAIM ---
+// Захваченные доставки не выдаются другим экземплярам до истечения lease
+// или до MarkDelivered/MarkFailed. Доставки отправляются в порядке событий.
+func (r *WebhookRepository) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	pending, pendingArgs, err := squirrel.Select("id").
+		From(deliveriesTableName).
+		Where(squirrel.Eq{"status": models.DeliveryPending}).
+		Where(squirrel.LtOrEq{"next_attempt_at": now}).
+		Where(squirrel.Or{squirrel.Eq{"locked_until": nil}, squirrel.Lt{"locked_until": now}}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build pending deliveries query: %w", err)
+	}
+
+	sqlStr, args, err := psql.Update(deliveriesTableName+" AS d").
+		Set("locked_until", now.Add(lease)).
+		Set("attempts", squirrel.Expr("d.attempts + 1")).
+		From(endpointsTableName + " e").
+		Where("e.id = d.endpoint_id").
+		Where(squirrel.Expr("d.id IN ("+pending+")", pendingArgs...)).
+		Suffix("RETURNING " + strings.Join(deliveryColumns, ", ") + ", e.url, e.secret").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build claim deliveries query: %w", err)
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, mapError("claim deliveries", err)
+	}
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.WebhookDelivery, error) {
+		var url, secret string
+		delivery, err := scanDelivery(row, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		delivery.URL, delivery.Secret = url, secret
+		return delivery, nil
+	})
+	if err != nil {
+		return nil, mapError("claim deliveries", err)
+	}
+	return deliveries, nil
+}
+
+// MarkDelivered --- DELIVERED ---
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int, at time.Time) error {
+	return r.update(ctx, "mark delivery delivered", psql.Update(deliveriesTableName).
+		Set("status", models.DeliveryDelivered).
+		Set("delivered_at", at).
+		Set("last_status_code", statusCode).
+		Set("last_error", nil).
+		Set("locked_until", nil).
+		Where(squirrel.Eq{"id": id}))
+}
+
+// MarkFailed --- FAILED ---
+// Снимает захват и назначает следующую попытку на retryAt; без retryAt доставка
+// попадает в список недоставленных.
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id int64, statusCode *int, reason string, retryAt *time.Time) error {
+	query := psql.Update(deliveriesTableName).
+		Set("last_status_code", statusCode).
+		Set("last_error", reason).
+		Set("locked_until", nil).
+		Where(squirrel.Eq{"id": id})
+	if retryAt != nil {
+		query = query.Set("next_attempt_at", *retryAt)
+	} else {
+		query = query.Set("status", models.DeliveryDead)
+	}
+	return r.update(ctx, "mark delivery failed", query)
+}
+
+func (r *WebhookRepository) update(ctx context.Context, op string, query squirrel.UpdateBuilder) error {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("build %s query: %w", op, err)
+	}
+	if _, err := conn(ctx, r.db).Exec(ctx, sqlStr, args...); err != nil {
+		return mapError(op, err)
+	}
+	return nil
+}
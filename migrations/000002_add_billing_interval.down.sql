@@ -0,0 +1,5 @@
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS chk_subscriptions_interval_days,
+    DROP CONSTRAINT IF EXISTS chk_subscriptions_billing_interval,
+    DROP COLUMN IF EXISTS interval_days,
+    DROP COLUMN IF EXISTS billing_interval;
@@ -0,0 +1,10 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS billing_interval VARCHAR(16) NOT NULL DEFAULT 'monthly',
+    ADD COLUMN IF NOT EXISTS interval_days INT;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_subscriptions_billing_interval
+        CHECK (billing_interval IN ('weekly', 'monthly', 'quarterly', 'yearly', 'custom')),
+    ADD CONSTRAINT chk_subscriptions_interval_days
+        CHECK ((billing_interval = 'custom' AND interval_days > 0)
+            OR (billing_interval <> 'custom' AND interval_days IS NULL));
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS subscription_prices (
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    price BIGINT NOT NULL CHECK (price > 0),
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (subscription_id, effective_from)
+);
+
+-- Начальная цена существующих подписок действует с даты начала
+INSERT INTO subscription_prices (subscription_id, price, effective_from)
+SELECT id, price, start_date::date
+FROM subscriptions
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,21 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'active'
+        CHECK (state IN ('trial', 'active', 'paused', 'cancelled', 'expired'));
+
+UPDATE subscriptions SET state = 'expired' WHERE end_date < now();
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_state_end_date
+    ON subscriptions (state, end_date)
+    WHERE deleted_at IS NULL;
+
+-- Интервалы приостановки; открытый интервал (resumed_at IS NULL) — текущая пауза
+CREATE TABLE IF NOT EXISTS subscription_pauses (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    paused_at TIMESTAMPTZ NOT NULL,
+    resumed_at TIMESTAMPTZ,
+    CHECK (resumed_at IS NULL OR resumed_at >= paused_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_pauses_subscription_id
+    ON subscription_pauses (subscription_id, paused_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_subscriptions_trial_end;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS trial_end;
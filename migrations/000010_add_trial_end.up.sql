@@ -0,0 +1,8 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS trial_end TIMESTAMPTZ
+        CHECK (trial_end IS NULL OR trial_end >= start_date);
+
+-- Пробные подписки, которые скоро закончатся или пора перевести в active
+CREATE INDEX IF NOT EXISTS idx_subscriptions_trial_end
+    ON subscriptions (trial_end)
+    WHERE state = 'trial' AND deleted_at IS NULL;
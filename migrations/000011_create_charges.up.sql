@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS charges (
+    id BIGSERIAL PRIMARY KEY,
+    -- без внешнего ключа: списания сохраняются после окончательного удаления подписки
+    subscription_id UUID NOT NULL,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (period_end > period_start),
+    -- одно списание за период: повторный проход планировщика ничего не создаёт
+    UNIQUE (subscription_id, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_charges_subscription_period_end
+    ON charges (subscription_id, period_end DESC);
@@ -0,0 +1,23 @@
+-- Напоминания о предстоящих событиях подписок; одна запись на событие,
+-- sent_at отмечает отправку, поэтому напоминание уходит один раз
+CREATE TABLE IF NOT EXISTS reminders (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('renewal', 'trial_end', 'expiry')),
+    due_at TIMESTAMPTZ NOT NULL,
+    user_id UUID NOT NULL,
+    service_name VARCHAR(100) NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    -- пока locked_until в будущем, напоминание отправляет один из экземпляров сервиса
+    locked_until TIMESTAMPTZ,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, kind, due_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_pending
+    ON reminders (due_at)
+    WHERE sent_at IS NULL;
@@ -0,0 +1,11 @@
+-- Изменения подписок для публикации в брокер сообщений (transactional outbox).
+-- Записываются в транзакции изменения; опубликованные строки удаляются.
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    -- идентификатор CloudEvent: одинаков при повторных публикациях одного события
+    event_id UUID NOT NULL UNIQUE,
+    subscription_id UUID NOT NULL,
+    operation TEXT NOT NULL,
+    data JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
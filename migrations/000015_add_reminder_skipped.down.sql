@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_reminders_pending;
+ALTER TABLE reminders DROP COLUMN IF EXISTS skipped_at;
+CREATE INDEX IF NOT EXISTS idx_reminders_pending
+    ON reminders (due_at)
+    WHERE sent_at IS NULL;
@@ -0,0 +1,8 @@
+-- skipped_at отмечает напоминание, событие которого перестало наступать
+-- (подписку отменили, приостановили, удалили или перенесли её дату)
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS skipped_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS idx_reminders_pending;
+CREATE INDEX IF NOT EXISTS idx_reminders_pending
+    ON reminders (due_at)
+    WHERE sent_at IS NULL AND skipped_at IS NULL;
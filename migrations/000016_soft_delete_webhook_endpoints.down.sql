@@ -0,0 +1,8 @@
+DELETE FROM webhook_endpoints WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS webhook_deliveries_endpoint_id_fkey;
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_endpoint_id_fkey
+    FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id) ON DELETE CASCADE;
+
+ALTER TABLE webhook_endpoints DROP COLUMN IF EXISTS deleted_at;
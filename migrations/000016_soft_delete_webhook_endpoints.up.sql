@@ -0,0 +1,8 @@
+-- Получатели вебхуков удаляются мягко, чтобы их доставки, в том числе
+-- недоставленные, оставались доступны для разбора
+ALTER TABLE webhook_endpoints ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS webhook_deliveries_endpoint_id_fkey;
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_endpoint_id_fkey
+    FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id);
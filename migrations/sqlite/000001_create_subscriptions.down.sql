@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS subscription_events;
+DROP TABLE IF EXISTS charges;
+DROP TABLE IF EXISTS subscription_pauses;
+DROP TABLE IF EXISTS subscription_prices;
+DROP TABLE IF EXISTS subscriptions;
@@ -0,0 +1,96 @@
+-- Схема SQLite повторяет итоговую схему PostgreSQL (см. ../*.up.sql) для хранилища подписок.
+-- Моменты хранятся в TEXT как 'YYYY-MM-DD HH:MM:SS.ffffff' в UTC, даты — как 'YYYY-MM-DD':
+-- такой формат сравнивается как строка и понимается функциями даты SQLite.
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id TEXT PRIMARY KEY,
+    service_name TEXT NOT NULL CHECK (length(service_name) <= 100),
+    price INTEGER NOT NULL CHECK (price > 0),
+    currency TEXT NOT NULL DEFAULT 'RUB' CHECK (currency GLOB '[A-Z][A-Z][A-Z]'),
+    user_id TEXT NOT NULL,
+    billing_interval TEXT NOT NULL DEFAULT 'monthly'
+        CHECK (billing_interval IN ('weekly', 'monthly', 'quarterly', 'yearly', 'custom')),
+    interval_days INTEGER,
+    start_date TEXT NOT NULL,
+    end_date TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TEXT,
+    state TEXT NOT NULL DEFAULT 'active'
+        CHECK (state IN ('trial', 'active', 'paused', 'cancelled', 'expired')),
+    trial_end TEXT CHECK (trial_end IS NULL OR trial_end >= start_date),
+    CHECK ((billing_interval = 'custom' AND interval_days > 0)
+        OR (billing_interval <> 'custom' AND interval_days IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_id ON subscriptions (user_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_service_name ON subscriptions (service_name);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_created_at_id ON subscriptions (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_deleted_at ON subscriptions (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_subscriptions_state_end_date ON subscriptions (state, end_date) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_subscriptions_trial_end ON subscriptions (trial_end) WHERE state = 'trial' AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS subscription_prices (
+    subscription_id TEXT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    price INTEGER NOT NULL CHECK (price > 0),
+    effective_from TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (subscription_id, effective_from)
+);
+
+-- Интервалы приостановки; открытый интервал (resumed_at IS NULL) — текущая пауза
+CREATE TABLE IF NOT EXISTS subscription_pauses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id TEXT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    paused_at TEXT NOT NULL,
+    resumed_at TEXT,
+    CHECK (resumed_at IS NULL OR resumed_at >= paused_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_pauses_subscription_id
+    ON subscription_pauses (subscription_id, paused_at);
+
+CREATE TABLE IF NOT EXISTS charges (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    -- без внешнего ключа: списания сохраняются после окончательного удаления подписки
+    subscription_id TEXT NOT NULL,
+    period_start TEXT NOT NULL,
+    period_end TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    CHECK (period_end > period_start),
+    -- одно списание за период: повторный проход планировщика ничего не создаёт
+    UNIQUE (subscription_id, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_charges_subscription_period_end
+    ON charges (subscription_id, period_end DESC);
+
+CREATE TABLE IF NOT EXISTS subscription_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    -- без внешнего ключа: история переживает окончательное удаление подписки
+    subscription_id TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    before TEXT,
+    after TEXT,
+    occurred_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_events_subscription_id
+    ON subscription_events (subscription_id, id DESC);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+CREATE TRIGGER IF NOT EXISTS trg_subscription_events_no_update
+    BEFORE UPDATE ON subscription_events
+BEGIN
+    SELECT RAISE(ABORT, 'subscription_events is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_subscription_events_no_delete
+    BEFORE DELETE ON subscription_events
+BEGIN
+    SELECT RAISE(ABORT, 'subscription_events is append-only');
+END;
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/rs/zerolog"
+	_ "modernc.org/sqlite"
+)
+
+// Схема строки подключения SQLite: sqlite://path/to/file.db
+const sqliteScheme = "sqlite://"
+
+// Параметры соединения SQLite: внешние ключи (каскадное удаление цен и пауз),
+// ожидание блокировки вместо немедленной ошибки SQLITE_BUSY, журнал WAL для чтения
+// во время записи и BEGIN IMMEDIATE, чтобы пишущие транзакции шли по очереди.
+var sqlitePragmas = []string{
+	"_pragma=foreign_keys(1)",
+	"_pragma=busy_timeout(5000)",
+	"_pragma=journal_mode(WAL)",
+	"_txlock=immediate",
+}
+
+// IsSQLite сообщает, указывает ли строка подключения на базу SQLite.
+func IsSQLite(connString string) bool {
+	return strings.HasPrefix(connString, sqliteScheme)
+}
+
+func NewSQLite(ctx context.Context, connString string, logger *zerolog.Logger) (*sql.DB, error) {
+	path := strings.TrimPrefix(connString, sqliteScheme)
+
+	dsn := path
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	dsn += separator + strings.Join(sqlitePragmas, "&")
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		logger.Error().Err(err).Msg("Ошибка открытия базы SQLite")
+		return nil, err
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		logger.Error().Err(err).Msg("Проверка подключения не пройдена")
+		_ = db.Close()
+		return nil, err
+	}
+
+	logger.Info().
+		Str("db_path", path).
+		Msg("База SQLite успешно открыта")
+
+	return db, nil
+}
@@ -1,23 +1,31 @@
 package migrate
 
 import (
+	"SubscriptionService/pkg/db"
 	"fmt"
 	"path/filepath"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/rs/zerolog"
 )
 
 // Up выполняет миграции вверх (создаёт/обновляет таблицы).
-// dbURL — строка подключения postgres (из DB_URL).
+// dbURL — строка подключения postgres или sqlite (из DB_URL); для SQLite
+// используется отдельный набор миграций migrations/sqlite.
 func Up(dbURL string, logger *zerolog.Logger) error {
 	if dbURL == "" {
 		return fmt.Errorf("DB_URL is empty")
 	}
 
-	absPath, err := filepath.Abs("migrations")
+	dir := "migrations"
+	if db.IsSQLite(dbURL) {
+		dir = filepath.Join(dir, "sqlite")
+	}
+
+	absPath, err := filepath.Abs(dir)
 	if err != nil {
 		return fmt.Errorf("resolve migrations path: %w", err)
 	}